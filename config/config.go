@@ -7,9 +7,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 
 	"github.com/toba/go-html-validate/linter"
+	"github.com/toba/go-html-validate/parser"
 	"github.com/toba/go-html-validate/rules"
 )
 
@@ -28,6 +30,89 @@ type FrameworkConfig struct {
 	HTMXCustomEvents []string `json:"htmx-custom-events"`
 }
 
+// TemplatesConfig configures Go template preprocessing.
+type TemplatesConfig struct {
+	// ExploreBranches lints every {{if}}/{{else}}, {{range}}/{{else}} and
+	// {{with}}/{{else}} branch combination instead of only the first branch.
+	ExploreBranches bool `json:"explore-branches"`
+	// MaxVariants caps the branch combinations linted per file (default 16).
+	MaxVariants int `json:"max-variants"`
+	// Delimiters are the left and right template action delimiters, for
+	// templates parsed with template.Delims (default ["{{", "}}"]).
+	Delimiters []string `json:"delimiters"`
+	// Overrides apply different delimiters to files matching a glob.
+	Overrides []TemplatesOverride `json:"overrides"`
+	// Set lists globs of the files making up the template set, resolved
+	// relative to the config file.
+	Set StringOrStrings `json:"set"`
+	// EntryPoints names templates executed directly from Go code.
+	EntryPoints []string `json:"entry-points"`
+}
+
+// TemplatesOverride sets template options for files matching any of Files.
+type TemplatesOverride struct {
+	Files      StringOrStrings `json:"files"`
+	Delimiters []string        `json:"delimiters"`
+	// Data is the Go type the files are executed with ("import/path.Type").
+	Data string `json:"data"`
+}
+
+// validate checks that every delimiter setting is a pair of non-empty strings.
+func (t *TemplatesConfig) validate() error {
+	if err := validateDelimiters(t.Delimiters); err != nil {
+		return fmt.Errorf("templates.delimiters: %w", err)
+	}
+	for i, o := range t.Overrides {
+		if len(o.Files) == 0 {
+			return fmt.Errorf("templates.overrides[%d]: files is required", i)
+		}
+		if err := validateDelimiters(o.Delimiters); err != nil {
+			return fmt.Errorf("templates.overrides[%d].delimiters: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func validateDelimiters(d []string) error {
+	if d == nil {
+		return nil
+	}
+	if len(d) != 2 || d[0] == "" || d[1] == "" {
+		return errors.New("must be a pair of non-empty strings")
+	}
+	return nil
+}
+
+// toDelims converts a validated delimiter pair, where nil means the default.
+func toDelims(d []string) parser.Delims {
+	if len(d) != 2 {
+		return parser.Delims{}
+	}
+	return parser.Delims{Left: d[0], Right: d[1]}
+}
+
+// validateRuleOptions checks the options of each configured rule against the
+// rule's schema.
+func (c *FileConfig) validateRuleOptions() error {
+	registry := rules.NewRegistry()
+	names := make([]string, 0, len(c.Rules))
+	for name := range c.Rules {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		options := c.Rules[name].Options
+		rule := registry.ByName(name)
+		if options == nil || rule == nil {
+			continue
+		}
+		if err := rules.ValidateOptions(rule, options); err != nil {
+			return fmt.Errorf("rules.%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
 // FileConfig represents the JSON structure of .htmlvalidate.json.
 type FileConfig struct {
 	// Schema is the JSON schema URL (ignored, but allowed for IDE support).
@@ -40,6 +125,8 @@ type FileConfig struct {
 	Rules map[string]RuleConfig `json:"rules"`
 	// Frameworks configures framework-specific attribute handling.
 	Frameworks FrameworkConfig `json:"frameworks"`
+	// Templates configures Go template preprocessing.
+	Templates TemplatesConfig `json:"templates"`
 }
 
 // StringOrStrings handles JSON that can be either a string or array of strings.
@@ -155,6 +242,12 @@ func LoadFile(path string) (*FileConfig, error) {
 	if err := json.Unmarshal(data, &cfg); err != nil {
 		return nil, fmt.Errorf("parsing %s: %w", path, err)
 	}
+	if err := cfg.Templates.validate(); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if err := cfg.validateRuleOptions(); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
 
 	return &cfg, nil
 }
@@ -276,6 +369,26 @@ func merge(base, overlay *FileConfig) *FileConfig {
 		result.Frameworks.HTMXCustomEvents = overlay.Frameworks.HTMXCustomEvents
 	}
 
+	// Merge templates config (overlay takes precedence)
+	result.Templates = base.Templates
+	if overlay.Templates.ExploreBranches {
+		result.Templates.ExploreBranches = true
+	}
+	if overlay.Templates.MaxVariants > 0 {
+		result.Templates.MaxVariants = overlay.Templates.MaxVariants
+	}
+	if overlay.Templates.Delimiters != nil {
+		result.Templates.Delimiters = overlay.Templates.Delimiters
+	}
+	if overlay.Templates.Set != nil {
+		result.Templates.Set = overlay.Templates.Set
+	}
+	if overlay.Templates.EntryPoints != nil {
+		result.Templates.EntryPoints = overlay.Templates.EntryPoints
+	}
+	// Overrides accumulate; later ones win when several match a file
+	result.Templates.Overrides = append(slices.Clone(base.Templates.Overrides), overlay.Templates.Overrides...)
+
 	return result
 }
 
@@ -289,6 +402,9 @@ func ToLinterConfig(fc *FileConfig, configPath string) *linter.Config {
 	}
 
 	for name, ruleCfg := range fc.Rules {
+		if len(ruleCfg.Options) > 0 {
+			cfg.RuleOptions[name] = ruleCfg.Options
+		}
 		switch ruleCfg.Severity {
 		case "off", "0":
 			cfg.DisabledRules = append(cfg.DisabledRules, name)
@@ -306,6 +422,27 @@ func ToLinterConfig(fc *FileConfig, configPath string) *linter.Config {
 		HTMXCustomEvents: fc.Frameworks.HTMXCustomEvents,
 	}
 
+	// Copy templates config
+	cfg.Templates = linter.TemplateConfig{
+		ExploreBranches: fc.Templates.ExploreBranches,
+		MaxVariants:     fc.Templates.MaxVariants,
+		Delims:          toDelims(fc.Templates.Delimiters),
+		EntryPoints:     fc.Templates.EntryPoints,
+	}
+	for _, pattern := range fc.Templates.Set {
+		if configPath != "" && !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(filepath.Dir(configPath), pattern)
+		}
+		cfg.Templates.Set = append(cfg.Templates.Set, pattern)
+	}
+	for _, o := range fc.Templates.Overrides {
+		cfg.Templates.Overrides = append(cfg.Templates.Overrides, linter.TemplateOverride{
+			Files:  o.Files,
+			Delims: toDelims(o.Delimiters),
+			Data:   o.Data,
+		})
+	}
+
 	return cfg
 }
 
@@ -1,8 +1,10 @@
 package config_test
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"reflect"
 	"slices"
 	"testing"
 
@@ -297,3 +299,194 @@ func TestResolveWithPreset(t *testing.T) {
 		t.Errorf("expected prefer-tbody severity to be off from preset, got %+v", cfg.Rules["prefer-tbody"])
 	}
 }
+
+func TestLoadFile_Templates(t *testing.T) {
+	dir := t.TempDir()
+	content := `{
+		"templates": {
+			"explore-branches": true,
+			"max-variants": 8,
+			"set": "templates/**/*.gohtml"
+		}
+	}`
+	path := filepath.Join(dir, config.ConfigFileName)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := config.LoadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	linterCfg := config.ToLinterConfig(cfg, path)
+	if !linterCfg.Templates.ExploreBranches {
+		t.Error("expected ExploreBranches to be enabled")
+	}
+	if linterCfg.Templates.MaxVariants != 8 {
+		t.Errorf("MaxVariants = %d, want 8", linterCfg.Templates.MaxVariants)
+	}
+	wantSet := filepath.Join(dir, "templates", "**", "*.gohtml")
+	if len(linterCfg.Templates.Set) != 1 || linterCfg.Templates.Set[0] != wantSet {
+		t.Errorf("Set = %v, want [%s]", linterCfg.Templates.Set, wantSet)
+	}
+}
+
+func TestLoadFile_TemplateDelimiters(t *testing.T) {
+	dir := t.TempDir()
+	content := `{
+		"templates": {
+			"delimiters": ["[[", "]]"],
+			"overrides": [
+				{"files": "legacy/**", "delimiters": ["{{", "}}"]},
+				{"files": ["legacy/vue/*.html"], "delimiters": ["<%", "%>"]}
+			]
+		}
+	}`
+	path := filepath.Join(dir, config.ConfigFileName)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := config.LoadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	templates := config.ToLinterConfig(cfg, path).Templates
+	tests := []struct {
+		path        string
+		left, right string
+	}{
+		{"views/index.html", "[[", "]]"},
+		{"legacy/page.html", "{{", "}}"},
+		{"legacy/vue/app.html", "<%", "%>"},
+	}
+	for _, tt := range tests {
+		d := templates.DelimsFor(tt.path)
+		if d.Left != tt.left || d.Right != tt.right {
+			t.Errorf("DelimsFor(%q) = %q %q, want %q %q", tt.path, d.Left, d.Right, tt.left, tt.right)
+		}
+	}
+}
+
+func TestLoadFile_InvalidTemplateDelimiters(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"single delimiter", `{"templates": {"delimiters": ["[["]}}`},
+		{"empty delimiter", `{"templates": {"delimiters": ["[[", ""]}}`},
+		{"override without files", `{"templates": {"overrides": [{"delimiters": ["[[", "]]"]}]}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), config.ConfigFileName)
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := config.LoadFile(path); err == nil {
+				t.Error("expected error for invalid delimiters")
+			}
+		})
+	}
+}
+
+func TestLoadFile_RuleOptions(t *testing.T) {
+	content := `{
+		"rules": {
+			"class-pattern": ["warn", {"pattern": "bem"}],
+			"long-title": ["error", {"maxlength": 60}],
+			"img-alt": ["error", {"accept": "anything"}]
+		}
+	}`
+	path := filepath.Join(t.TempDir(), config.ConfigFileName)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	fc, err := config.LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	cfg := config.ToLinterConfig(fc, path)
+	if got := cfg.RuleOptions[rules.RuleClassPattern]["pattern"]; got != "bem" {
+		t.Errorf("class-pattern pattern = %v, want bem", got)
+	}
+	if got := cfg.RuleOptions[rules.RuleLongTitle]["maxlength"]; got != 60.0 {
+		t.Errorf("long-title maxlength = %v, want 60", got)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestLoadFile_InvalidRuleOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"invalid regex", `{"rules": {"id-pattern": ["error", {"pattern": "["}]}}`},
+		{"unknown option", `{"rules": {"long-title": ["error", {"max": 60}]}}`},
+		{"wrong type", `{"rules": {"long-title": ["error", {"maxlength": "60"}]}}`},
+		{"below minimum", `{"rules": {"long-title": ["error", {"maxlength": 0}]}}`},
+		{"wrong item type", `{"rules": {"allowed-links": ["error", {"protocols": ["https", 1]}]}}`},
+		{"invalid ignore pattern", `{"rules": {"no-missing-references": ["error", {"ignore": ["["]}]}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), config.ConfigFileName)
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := config.LoadFile(path); err == nil {
+				t.Error("expected error for invalid options")
+			}
+		})
+	}
+}
+
+// The published schema must describe the same options the rules accept.
+func TestSchema_RuleOptions(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "schemas", "htmlint.schema.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema struct {
+		Properties struct {
+			Rules struct {
+				Properties map[string]struct {
+					AllOf []struct {
+						Items []json.RawMessage `json:"items"`
+					} `json:"allOf"`
+				} `json:"properties"`
+			} `json:"rules"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, rule := range rules.NewRegistry().All() {
+		c, ok := rule.(rules.Configurable)
+		if !ok {
+			continue
+		}
+		entry := schema.Properties.Rules.Properties[rule.Name()]
+		if len(entry.AllOf) != 2 || len(entry.AllOf[1].Items) != 2 {
+			t.Errorf("schema has no options for %s", rule.Name())
+			continue
+		}
+		var published, want any
+		if err := json.Unmarshal(entry.AllOf[1].Items[1], &published); err != nil {
+			t.Fatal(err)
+		}
+		b, _ := json.Marshal(c.OptionsSchema())
+		_ = json.Unmarshal(b, &want)
+		if !reflect.DeepEqual(published, want) {
+			t.Errorf("schema options for %s = %s, want %s", rule.Name(), entry.AllOf[1].Items[1], b)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package linter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/toba/go-html-validate/rules"
+)
+
+// DefaultCacheFile is the cache file name used when no location is given, or
+// when the location is a directory.
+const DefaultCacheFile = ".htmlintcache"
+
+// cacheFormat changes whenever the layout of the cache file or of
+// rules.Result does, so old cache files are discarded.
+const cacheFormat = 2
+
+// Cache records the results of linting each file, so a file is only linted
+// again when its content, the configuration or the tool version changes.
+// It is safe for concurrent use.
+type Cache struct {
+	path string
+	// key identifies the tool version and configuration the entries were
+	// recorded with
+	key string
+
+	mu      sync.Mutex
+	files   map[string]cacheEntry
+	changed bool
+}
+
+// cacheEntry is the cached outcome of linting one file.
+type cacheEntry struct {
+	// Hash is the SHA-256 of the file content
+	Hash    string         `json:"hash"`
+	Results []rules.Result `json:"results"`
+}
+
+// cacheFile is the on-disk form of a Cache.
+type cacheFile struct {
+	Key   string                `json:"key"`
+	Files map[string]cacheEntry `json:"files"`
+}
+
+// OpenCache loads the cache file at path and makes l use it for the files it
+// lints. If path is a directory the cache file is DefaultCacheFile inside it.
+// A missing or unreadable cache file, or one recorded with another version
+// or configuration, starts an empty cache. Call Save after linting.
+func (l *Linter) OpenCache(path, version string) (*Cache, error) {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, DefaultCacheFile)
+	}
+	key, err := l.cacheKey(version)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Cache{path: path, key: key, files: make(map[string]cacheEntry)}
+	if data, err := os.ReadFile(path); err == nil { //nolint:gosec // user-specified cache location is intentional
+		var f cacheFile
+		if json.Unmarshal(data, &f) == nil && f.Key == key && f.Files != nil {
+			c.files = f.Files
+		}
+	}
+	l.cache = c
+	return c, nil
+}
+
+// cacheKey hashes everything besides a file's own content that its results
+// depend on: the tool version, the configuration, and the content of the
+// template set, whose templates are inlined into pages.
+func (l *Linter) cacheKey(version string) (string, error) {
+	cfg := *l.config
+	// Neither changes what is found
+	cfg.Jobs = 0
+	cfg.ConfigPath = ""
+
+	h := sha256.New()
+	err := json.NewEncoder(h).Encode(struct {
+		Format  int
+		Version string
+		Config  Config
+	}{cacheFormat, version, cfg})
+	if err != nil {
+		return "", err
+	}
+
+	paths, err := l.templateSetFiles()
+	if err != nil {
+		return "", err
+	}
+	for _, path := range paths {
+		content, err := os.ReadFile(path) //nolint:gosec // paths come from the user's config
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s\x00%x\n", path, sha256.Sum256(content))
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// lookup returns the cached results for the file at path, if its content
+// hasn't changed.
+func (c *Cache) lookup(path, hash string) ([]rules.Result, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.files[path]
+	if !ok || e.Hash != hash {
+		return nil, false
+	}
+	return slices.Clone(e.Results), true
+}
+
+// store records the results of linting the file at path.
+func (c *Cache) store(path, hash string, results []rules.Result) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.files[path] = cacheEntry{Hash: hash, Results: slices.Clone(results)}
+	c.changed = true
+}
+
+// Save writes the cache file if anything changed, first dropping entries
+// for files that no longer exist.
+func (c *Cache) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for path := range c.files {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			delete(c.files, path)
+			c.changed = true
+		}
+	}
+	if !c.changed {
+		return nil
+	}
+
+	data, err := json.Marshal(cacheFile{Key: c.key, Files: c.files})
+	if err != nil {
+		return err
+	}
+	// Write a temporary file and rename it, so an interrupted run can't
+	// leave a truncated cache behind
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		return err
+	}
+	c.changed = false
+	return nil
+}
+
+// contentHash returns the hash a file's content is cached under.
+func contentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,171 @@
+package linter
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"math"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/toba/go-html-validate/rules"
+)
+
+// Changes records the files that changed against a revision and the lines
+// changed in each.
+type Changes struct {
+	// files holds the changed line ranges by absolute path
+	files map[string][]LineRange
+}
+
+// LineRange is a range of 1-based lines, End included.
+type LineRange struct {
+	Start, End int
+}
+
+// GitChanges runs git in dir to find the files that changed between rev and
+// the working tree, including files git doesn't track yet, whose every line
+// counts as changed.
+func GitChanges(dir, rev string) (*Changes, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	cdup, err := git(absDir, "rev-parse", "--show-cdup")
+	if err != nil {
+		return nil, err
+	}
+	root := filepath.Join(absDir, strings.TrimSpace(string(cdup)))
+
+	// Explicit prefixes guard against diff.noprefix and friends
+	patch, err := git(root, "-c", "core.quotePath=false", "diff", "--no-color", "--no-ext-diff",
+		"--src-prefix=a/", "--dst-prefix=b/", "-U0", rev, "--")
+	if err != nil {
+		return nil, err
+	}
+	c, err := parseDiff(root, patch)
+	if err != nil {
+		return nil, err
+	}
+
+	untracked, err := git(root, "ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return nil, err
+	}
+	for name := range strings.SplitSeq(string(untracked), "\x00") {
+		if name != "" {
+			c.files[filepath.Join(root, filepath.FromSlash(name))] = []LineRange{{1, math.MaxInt}}
+		}
+	}
+	return c, nil
+}
+
+// git runs git with args in dir, returning its output.
+func git(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return out, nil
+}
+
+// parseDiff reads the added line ranges from a unified diff with no context
+// lines, for paths relative to root.
+func parseDiff(root string, patch []byte) (*Changes, error) {
+	c := &Changes{files: make(map[string][]LineRange)}
+	var current string
+	scanner := bufio.NewScanner(bytes.NewReader(patch))
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "+++ "):
+			name := strings.TrimPrefix(line, "+++ ")
+			if name == "/dev/null" {
+				// Deleted files have nothing to lint
+				current = ""
+				continue
+			}
+			if strings.HasPrefix(name, `"`) {
+				unquoted, err := strconv.Unquote(name)
+				if err != nil {
+					return nil, fmt.Errorf("parsing git diff: bad file name %s", name)
+				}
+				name = unquoted
+			}
+			current = filepath.Join(root, filepath.FromSlash(strings.TrimPrefix(name, "b/")))
+			c.files[current] = nil
+		case strings.HasPrefix(line, "@@ ") && current != "":
+			// @@ -old[,count] +new[,count] @@
+			fields := strings.Fields(line)
+			if len(fields) < 3 || !strings.HasPrefix(fields[2], "+") {
+				return nil, fmt.Errorf("parsing git diff: bad hunk header %q", line)
+			}
+			startText, countText, hasCount := strings.Cut(fields[2][1:], ",")
+			start, err := strconv.Atoi(startText)
+			if err != nil {
+				return nil, fmt.Errorf("parsing git diff: bad hunk header %q", line)
+			}
+			count := 1
+			if hasCount {
+				if count, err = strconv.Atoi(countText); err != nil {
+					return nil, fmt.Errorf("parsing git diff: bad hunk header %q", line)
+				}
+			}
+			// A hunk that only removes lines adds none
+			if count > 0 {
+				c.files[current] = append(c.files[current], LineRange{start, start + count - 1})
+			}
+		}
+	}
+	return c, scanner.Err()
+}
+
+// Files returns the number of changed files.
+func (c *Changes) Files() int {
+	return len(c.files)
+}
+
+// Contains reports whether the file at path changed.
+func (c *Changes) Contains(path string) bool {
+	_, ok := c.files[absPath(path)]
+	return ok
+}
+
+// ContainsResult reports whether r is on a changed line. Results spanning
+// several lines count if any of them changed, and results for the whole
+// file, such as parse errors, count if the file changed.
+func (c *Changes) ContainsResult(r rules.Result) bool {
+	ranges, ok := c.files[absPath(r.Filename)]
+	if !ok {
+		return false
+	}
+	// Parse errors are reported at 1:1 but stand for the whole file
+	if r.Line <= 0 || r.Rule == RuleParseError {
+		return true
+	}
+	end := max(r.EndLine, r.Line)
+	for _, lr := range ranges {
+		if r.Line <= lr.End && lr.Start <= end {
+			return true
+		}
+	}
+	return false
+}
+
+// absPath returns path made absolute, or path itself if that fails.
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
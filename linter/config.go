@@ -1,8 +1,12 @@
 package linter
 
 import (
+	"fmt"
+	"path/filepath"
 	"slices"
+	"strings"
 
+	"github.com/toba/go-html-validate/parser"
 	"github.com/toba/go-html-validate/rules"
 )
 
@@ -18,6 +22,90 @@ type FrameworkConfig struct {
 	HTMXCustomEvents []string
 }
 
+// TemplateConfig configures how Go templates are preprocessed.
+type TemplateConfig struct {
+	// ExploreBranches lints each combination of {{if}}/{{else}},
+	// {{range}}/{{else}} and {{with}}/{{else}} branches, instead of only the
+	// first branch, so markup inside else branches is checked too.
+	ExploreBranches bool
+	// MaxVariants caps the branch combinations linted per file.
+	// Defaults to parser.DefaultMaxVariants when zero.
+	MaxVariants int
+	// Delims are the template action delimiters, for templates that call
+	// template.Delims. The zero value means "{{" and "}}".
+	Delims parser.Delims
+	// Overrides set different delimiters for files matching a pattern.
+	Overrides []TemplateOverride
+	// Dir is the directory override patterns are relative to. Absolute
+	// paths under it are matched relative to it; others are matched as
+	// they are.
+	Dir string
+	// Set lists glob patterns (with ** support) of files making up the
+	// template set, like template.ParseGlob. When set, {{template}} calls in
+	// entry-point pages are resolved against the templates these files
+	// define, and document-level rules check the resulting page.
+	Set []string
+	// EntryPoints names templates executed directly from Go code, which
+	// the template-unused rule doesn't expect to be called.
+	EntryPoints []string
+}
+
+// TemplateOverride applies template settings to files matching any of its
+// patterns, which use the same syntax as IgnorePatterns. Zero-valued settings
+// are left as they are.
+type TemplateOverride struct {
+	Files  []string
+	Delims parser.Delims
+	// Data is the Go type the files are executed with, as an
+	// "import/path.Type" spec, for the template-type-check rule.
+	Data string
+}
+
+// matches reports whether the override applies to the file at path.
+func (o TemplateOverride) matches(path string) bool {
+	return slices.ContainsFunc(o.Files, func(pattern string) bool {
+		return matchIgnorePattern(path, pattern)
+	})
+}
+
+// matchPath returns path as override patterns match it: relative to Dir
+// when it's an absolute path under Dir.
+func (c TemplateConfig) matchPath(path string) string {
+	if c.Dir == "" || !filepath.IsAbs(path) {
+		return path
+	}
+	if rel, err := filepath.Rel(c.Dir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+// DelimsFor returns the template delimiters for the file at path. The last
+// matching override wins.
+func (c TemplateConfig) DelimsFor(path string) parser.Delims {
+	path = c.matchPath(path)
+	delims := c.Delims
+	for _, o := range c.Overrides {
+		if o.Delims.Left != "" && o.matches(path) {
+			delims = o.Delims
+		}
+	}
+	return delims.OrDefault()
+}
+
+// DataFor returns the data type spec for the file at path, or "" if none is
+// configured. The last matching override wins.
+func (c TemplateConfig) DataFor(path string) string {
+	path = c.matchPath(path)
+	data := ""
+	for _, o := range c.Overrides {
+		if o.Data != "" && o.matches(path) {
+			data = o.Data
+		}
+	}
+	return data
+}
+
 // Config holds linter configuration options.
 type Config struct {
 	// EnabledRules lists rules to enable (empty means all)
@@ -26,14 +114,22 @@ type Config struct {
 	DisabledRules []string
 	// RuleSeverity overrides severity for specific rules
 	RuleSeverity map[string]rules.Severity
+	// RuleOptions holds options for rules implementing rules.Configurable,
+	// keyed by rule name
+	RuleOptions map[string]map[string]any
 	// MinSeverity filters results to this severity or higher
 	MinSeverity rules.Severity
 	// IgnorePatterns are glob patterns for files to skip
 	IgnorePatterns []string
+	// Jobs is the number of files linted concurrently; runtime.GOMAXPROCS
+	// when zero
+	Jobs int
 	// ConfigPath is the path to the loaded config file (for debugging)
 	ConfigPath string
 	// Frameworks configures framework-specific attribute handling.
 	Frameworks FrameworkConfig
+	// Templates configures Go template preprocessing.
+	Templates TemplateConfig
 }
 
 // DefaultConfig returns a configuration with all rules enabled.
@@ -42,6 +138,7 @@ func DefaultConfig() *Config {
 		EnabledRules:   nil, // nil means all enabled
 		DisabledRules:  nil,
 		RuleSeverity:   make(map[string]rules.Severity),
+		RuleOptions:    make(map[string]map[string]any),
 		MinSeverity:    rules.Info, // Show everything by default
 		IgnorePatterns: nil,
 	}
@@ -63,6 +160,27 @@ func (c *Config) IsRuleEnabled(name string) bool {
 	return true
 }
 
+// Validate checks RuleOptions against the schema of each rule. New ignores
+// options that don't validate, so call Validate first to report them.
+func (c *Config) Validate() error {
+	registry := rules.NewRegistry()
+	names := make([]string, 0, len(c.RuleOptions))
+	for name := range c.RuleOptions {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		rule := registry.ByName(name)
+		if rule == nil {
+			continue
+		}
+		if err := rules.ValidateOptions(rule, c.RuleOptions[name]); err != nil {
+			return fmt.Errorf("rule %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
 // ErrorsOnly configures the linter to only report errors.
 func (c *Config) ErrorsOnly() *Config {
 	c.MinSeverity = rules.Error
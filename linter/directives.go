@@ -0,0 +1,292 @@
+package linter
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+
+	"github.com/toba/go-html-validate/parser"
+	"github.com/toba/go-html-validate/rules"
+)
+
+// directivePrefixes introduce an inline directive in an HTML comment
+// (<!-- htmlvalidate-disable-next img-alt -->) or a template comment
+// ({{/* htmlint-disable-line img-alt */}}). Either prefix works in either
+// kind of comment.
+var directivePrefixes = []string{"htmlvalidate-", "htmlint-"}
+
+// directiveKind is what a directive does.
+type directiveKind int
+
+const (
+	// directiveDisable turns rules off until a matching enable
+	directiveDisable directiveKind = iota
+	// directiveEnable turns rules back on
+	directiveEnable
+	// directiveDisableNext turns rules off for the line after the directive
+	directiveDisableNext
+	// directiveDisableLine turns rules off for the directive's own line
+	directiveDisableLine
+)
+
+// directiveVerbs maps directive names to kinds. Longer names come first so
+// "disable-next" isn't read as "disable".
+var directiveVerbs = []struct {
+	name string
+	kind directiveKind
+}{
+	{"disable-next", directiveDisableNext},
+	{"disable-line", directiveDisableLine},
+	{"disable", directiveDisable},
+	{"enable", directiveEnable},
+}
+
+// directive is a single inline directive in a file.
+type directive struct {
+	kind directiveKind
+	// text is the directive name as written, e.g. "htmlvalidate-disable"
+	text string
+	// rules lists the rules the directive applies to; empty means all
+	rules []string
+	span  parser.Span
+	// used records which of rules (or "" for all) suppressed something
+	used map[string]bool
+	// unknown is set for comments that look like directives but aren't
+	unknown bool
+}
+
+// findDirectives returns the directives in content, in source order.
+// HTML comments are found by scanning content outside template actions;
+// template comments come from actions.
+func findDirectives(content []byte, actions []parser.TemplateAction, sm *parser.SourceMap) []*directive {
+	var directives []*directive
+	add := func(text string, start, end int) {
+		if d := parseDirective(text); d != nil {
+			d.span = parser.Span{Start: sm.Position(start), End: sm.Position(end)}
+			directives = append(directives, d)
+		}
+	}
+
+	next := 0
+	for pos := 0; pos < len(content); {
+		i := bytes.Index(content[pos:], []byte("<!--"))
+		if i < 0 {
+			break
+		}
+		start := pos + i
+		// Skip comment markers inside template actions
+		for next < len(actions) && actions[next].Span.End.Offset <= start {
+			next++
+		}
+		if next < len(actions) && actions[next].Span.Start.Offset <= start {
+			pos = actions[next].Span.End.Offset
+			continue
+		}
+		end := len(content)
+		textEnd := end
+		if j := bytes.Index(content[start+4:], []byte("-->")); j >= 0 {
+			textEnd = start + 4 + j
+			end = textEnd + 3
+		}
+		add(string(content[start+4:textEnd]), start, end)
+		pos = end
+	}
+
+	for _, a := range actions {
+		if a.Kind == parser.ActionComment {
+			add(templateCommentText(content, a), a.Span.Start.Offset, a.Span.End.Offset)
+		}
+	}
+
+	slices.SortStableFunc(directives, func(a, b *directive) int {
+		return a.span.Start.Offset - b.span.Start.Offset
+	})
+	return directives
+}
+
+// templateCommentText returns the text of a template comment action
+// between its "/*" and "*/".
+func templateCommentText(content []byte, a parser.TemplateAction) string {
+	text := string(content[a.Span.Start.Offset:a.Span.End.Offset])
+	if i := strings.Index(text, "/*"); i >= 0 {
+		text = text[i+2:]
+	}
+	if i := strings.LastIndex(text, "*/"); i >= 0 {
+		text = text[:i]
+	}
+	return text
+}
+
+// parseDirective parses comment text as a directive. Returns nil if the
+// comment isn't one. Rule names are separated by commas or spaces, and
+// anything after " -- " is a free-form explanation.
+func parseDirective(text string) *directive {
+	text, _, _ = strings.Cut(strings.TrimSpace(text), " -- ")
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return nil
+	}
+	name := fields[0]
+
+	var verb string
+	for _, prefix := range directivePrefixes {
+		if rest, ok := strings.CutPrefix(name, prefix); ok {
+			verb = rest
+			break
+		}
+	}
+	if verb == "" {
+		return nil
+	}
+
+	d := &directive{text: name, used: make(map[string]bool), unknown: true}
+	for _, v := range directiveVerbs {
+		if verb == v.name {
+			d.kind, d.unknown = v.kind, false
+			break
+		}
+	}
+	for _, f := range fields[1:] {
+		for r := range strings.SplitSeq(f, ",") {
+			if r != "" {
+				d.rules = append(d.rules, r)
+			}
+		}
+	}
+	return d
+}
+
+// appliesTo reports whether the directive names rule, or names no rules.
+func (d *directive) appliesTo(rule string) bool {
+	return len(d.rules) == 0 || slices.Contains(d.rules, rule)
+}
+
+// markUsed records that the directive suppressed a result from rule.
+func (d *directive) markUsed(rule string) {
+	if len(d.rules) == 0 {
+		d.used[""] = true
+	} else {
+		d.used[rule] = true
+	}
+}
+
+// suppressedBy returns the directive that turns off rule at pos, or nil.
+func suppressedBy(directives []*directive, rule string, pos parser.Position) *directive {
+	var all *directive
+	byRule := make(map[string]*directive)
+	var except []string
+
+	for _, d := range directives {
+		if d.unknown {
+			continue
+		}
+		switch d.kind {
+		case directiveDisableLine:
+			if d.span.Start.Line == pos.Line && d.appliesTo(rule) {
+				return d
+			}
+		case directiveDisableNext:
+			if d.span.End.Line+1 == pos.Line && d.appliesTo(rule) {
+				return d
+			}
+		case directiveDisable:
+			if !before(d.span.End, pos) {
+				continue
+			}
+			if len(d.rules) == 0 {
+				all, except = d, nil
+				clear(byRule)
+			}
+			for _, r := range d.rules {
+				byRule[r] = d
+			}
+		case directiveEnable:
+			if !before(d.span.End, pos) {
+				continue
+			}
+			if len(d.rules) == 0 {
+				all, except = nil, nil
+				clear(byRule)
+			}
+			for _, r := range d.rules {
+				delete(byRule, r)
+				except = append(except, r)
+			}
+		}
+	}
+
+	if d := byRule[rule]; d != nil {
+		return d
+	}
+	if all != nil && !slices.Contains(except, rule) {
+		return all
+	}
+	return nil
+}
+
+// before reports whether a is at or before b.
+func before(a, b parser.Position) bool {
+	return a.Line < b.Line || (a.Line == b.Line && a.Col <= b.Col)
+}
+
+// applyDirectives removes results turned off by directives in the file and
+// reports problems with the directives themselves. Directives naming rules
+// that didn't run aren't reported as unused, as another configuration may
+// need them.
+func (l *Linter) applyDirectives(filename string, directives []*directive, results []rules.Result) []rules.Result {
+	if len(directives) == 0 {
+		return results
+	}
+
+	kept := results[:0]
+	for _, r := range results {
+		if r.Rule == rules.RuleDisableDirective {
+			kept = append(kept, r)
+			continue
+		}
+		pos := parser.Position{Line: r.Line, Col: r.Col}
+		if d := suppressedBy(directives, r.Rule, pos); d != nil {
+			d.markUsed(r.Rule)
+			continue
+		}
+		kept = append(kept, r)
+	}
+
+	if !l.isEnabled(rules.RuleDisableDirective) {
+		return kept
+	}
+	report := func(d *directive, message string) {
+		kept = append(kept, rules.Result{
+			Rule:     rules.RuleDisableDirective,
+			Message:  message,
+			Filename: filename,
+			Severity: rules.Warning,
+		}.WithSpan(d.span))
+	}
+	for _, d := range directives {
+		if d.unknown {
+			report(d, "unknown directive "+d.text)
+			continue
+		}
+		if len(d.rules) == 0 {
+			if d.kind != directiveEnable && !d.used[""] {
+				report(d, "unused "+d.text+" directive")
+			}
+			continue
+		}
+		for _, name := range d.rules {
+			switch {
+			case l.registry.ByName(name) == nil:
+				report(d, "unknown rule "+name+" in "+d.text+" directive")
+			case d.kind != directiveEnable && l.isEnabled(name) && !d.used[name]:
+				report(d, "unused "+d.text+" directive for "+name)
+			}
+		}
+	}
+	return kept
+}
+
+// isEnabled reports whether the named rule ran.
+func (l *Linter) isEnabled(name string) bool {
+	return slices.ContainsFunc(l.rules, func(r rules.Rule) bool { return r.Name() == name })
+}
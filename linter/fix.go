@@ -0,0 +1,91 @@
+package linter
+
+import (
+	"io"
+	"slices"
+
+	"github.com/toba/go-html-validate/rules"
+)
+
+// FixMode selects what Run does with results that carry fixes.
+type FixMode int
+
+const (
+	// FixOff only reports results.
+	FixOff FixMode = iota
+	// FixWrite applies fixes and writes the fixed files back.
+	FixWrite
+	// FixDryRun applies fixes in memory and writes them as a unified diff
+	// instead of changing any file.
+	FixDryRun
+)
+
+// maxFixPasses caps how many times a file is linted and fixed. Fixes are
+// applied a pass at a time because an edit can overlap another, or uncover
+// a new problem.
+const maxFixPasses = 10
+
+// SetFix makes Run apply the fixes of the results it finds. With FixDryRun,
+// or for content with no file to write to, the changes are written to w as
+// a unified diff.
+func (l *Linter) SetFix(mode FixMode, w io.Writer) {
+	l.fix = mode
+	l.diffs = w
+}
+
+// FixContent applies the fixes of content's results, linting again after
+// each pass until nothing more can be fixed. It returns the fixed content
+// and the results that remain.
+func (l *Linter) FixContent(filename string, content []byte) ([]byte, []rules.Result, error) {
+	l.resetRules()
+	return l.fixContent(filename, content)
+}
+
+// fixContent is FixContent within a run.
+func (l *Linter) fixContent(filename string, content []byte) ([]byte, []rules.Result, error) {
+	for pass := 1; ; pass++ {
+		results, err := l.lintContent(filename, content)
+		if err != nil {
+			return nil, nil, err
+		}
+		if pass > maxFixPasses {
+			return content, results, nil
+		}
+		fixed, ok := applyFixes(content, results)
+		if !ok {
+			return content, results, nil
+		}
+		content = fixed
+	}
+}
+
+// applyFixes applies the edits of each result whose edits don't overlap
+// those of an earlier result, returning false if there were none.
+func applyFixes(content []byte, results []rules.Result) ([]byte, bool) {
+	var accepted []rules.Edit
+	overlaps := func(e rules.Edit) bool {
+		return slices.ContainsFunc(accepted, func(a rules.Edit) bool {
+			// Insertions at the same point conflict, as their order is unknown
+			return (e.Start < a.End && a.Start < e.End) || e.Start == a.Start
+		})
+	}
+	for _, r := range results {
+		if len(r.Edits) == 0 || slices.ContainsFunc(r.Edits, overlaps) {
+			continue
+		}
+		accepted = append(accepted, r.Edits...)
+	}
+	if len(accepted) == 0 {
+		return content, false
+	}
+
+	slices.SortFunc(accepted, func(a, b rules.Edit) int { return a.Start - b.Start })
+	fixed := make([]byte, 0, len(content))
+	last := 0
+	for _, e := range accepted {
+		fixed = append(fixed, content[last:e.Start]...)
+		fixed = append(fixed, e.Text...)
+		last = e.End
+	}
+	return append(fixed, content[last:]...), true
+}
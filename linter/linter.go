@@ -2,9 +2,15 @@
 package linter
 
 import (
+	"bytes"
+	"errors"
+	"io"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
+	"sync"
 
 	"github.com/toba/go-html-validate/parser"
 	"github.com/toba/go-html-validate/rules"
@@ -13,8 +19,27 @@ import (
 // Linter coordinates HTML template accessibility checking.
 type Linter struct {
 	rules    []rules.Rule
+	registry *rules.Registry
 	config   *Config
-	reporter Reporter
+	// reporters are set by SetReporter and AddReporter
+	reporters []Reporter
+	// cache, if set by OpenCache, holds results from earlier runs
+	cache *Cache
+	// fix and diffs are set by SetFix
+	fix   FixMode
+	diffs io.Writer
+	// changes and changedLinesOnly are set by SetChanges
+	changes          *Changes
+	changedLinesOnly bool
+	// sources holds content that was linted without being on disk, such as
+	// standard input and dry-run fixes, by path
+	sourcesMu sync.Mutex
+	sources   map[string][]byte
+
+	// templates is the template set loaded from config.Templates.Set
+	templatesOnce sync.Once
+	templates     *parser.TemplateSet
+	templatesErr  error
 }
 
 // Reporter defines the interface for outputting lint results.
@@ -22,6 +47,24 @@ type Reporter interface {
 	Report(results []rules.Result) error
 }
 
+// FilesReporter is implemented by reporters that also need the files that
+// were linted, such as to list clean files. Run calls ReportFiles instead of
+// Report for them.
+type FilesReporter interface {
+	Reporter
+	// ReportFiles reports results along with every file linted, in the
+	// order they were linted, whether or not it has results.
+	ReportFiles(files []string, results []rules.Result) error
+}
+
+// SourceReporter is implemented by reporters that show or hash the source
+// results point into. Before each report they're given a function reading
+// the content that was linted, which may not be what's on disk.
+type SourceReporter interface {
+	Reporter
+	SetSource(read func(path string) ([]byte, error))
+}
+
 // New creates a new Linter with the given configuration.
 func New(cfg *Config) *Linter {
 	if cfg == nil {
@@ -40,102 +83,387 @@ func New(cfg *Config) *Linter {
 			if customRule, ok := rule.(rules.HTMXCustomEventsConfigurable); ok {
 				customRule.ConfigureCustomEvents(cfg.Frameworks.HTMXCustomEvents)
 			}
+			if entryRule, ok := rule.(rules.TemplateEntryPointsConfigurable); ok {
+				entryRule.ConfigureEntryPoints(cfg.Templates.EntryPoints)
+			}
+			if dataRule, ok := rule.(rules.TemplateDataConfigurable); ok {
+				dataRule.ConfigureTemplateData(cfg.Templates.DataFor)
+			}
+			// Invalid options leave the defaults; Config.Validate reports them
+			if optionsRule, ok := rule.(rules.Configurable); ok {
+				if options, ok := cfg.RuleOptions[rule.Name()]; ok {
+					_ = rules.ConfigureOptions(optionsRule, options)
+				}
+			}
 			enabledRules = append(enabledRules, rule)
 		}
 	}
 
 	return &Linter{
-		rules:  enabledRules,
-		config: cfg,
+		rules:    enabledRules,
+		registry: registry,
+		config:   cfg,
+	}
+}
+
+// resetRules clears what rules kept from the last run, such as loaded Go
+// packages, which may have changed since.
+func (l *Linter) resetRules() {
+	for _, rule := range l.rules {
+		if r, ok := rule.(rules.Resettable); ok {
+			r.Reset()
+		}
 	}
 }
 
-// SetReporter sets the output reporter.
+// SetReporter sets the output reporter, replacing any others.
 func (l *Linter) SetReporter(r Reporter) {
-	l.reporter = r
+	l.reporters = []Reporter{r}
+}
+
+// AddReporter adds a reporter that's given the same results as the others,
+// so one run can produce several outputs.
+func (l *Linter) AddReporter(r Reporter) {
+	l.reporters = append(l.reporters, r)
 }
 
-// LintFile checks a single file and returns any violations.
+// RuleNames returns the names of the enabled rules, in registry order.
+func (l *Linter) RuleNames() []string {
+	names := make([]string, 0, len(l.rules))
+	for _, rule := range l.rules {
+		names = append(names, rule.Name())
+	}
+	return names
+}
+
+// SetChanges makes Run lint only the files in c. With linesOnly, only
+// results on the changed lines are reported, though whole files are linted
+// so problems spanning elements are still found.
+func (l *Linter) SetChanges(c *Changes, linesOnly bool) {
+	l.changes = c
+	l.changedLinesOnly = linesOnly
+}
+
+// LintFile checks a single file and returns any violations. With a cache
+// open, an unchanged file's results come from the cache.
 func (l *Linter) LintFile(path string) ([]rules.Result, error) {
+	l.resetRules()
+	return l.lintFile(path)
+}
+
+// lintFile is LintFile within a run.
+func (l *Linter) lintFile(path string) ([]rules.Result, error) {
 	content, err := os.ReadFile(path) //nolint:gosec // user-specified file path is intentional
 	if err != nil {
 		return nil, err
 	}
 
-	return l.LintContent(path, content)
+	// Results checked against Go data types also depend on Go source the
+	// cache doesn't track
+	if l.cache == nil || l.config.Templates.DataFor(path) != "" ||
+		bytes.Contains(content, []byte(rules.DataDirective)) {
+		return l.lintContent(path, content)
+	}
+
+	hash := contentHash(content)
+	if results, ok := l.cache.lookup(path, hash); ok {
+		return results, nil
+	}
+	results, err := l.lintContent(path, content)
+	if err != nil {
+		return nil, err
+	}
+	l.cache.store(path, hash, results)
+	return results, nil
 }
 
 // LintContent checks HTML content and returns any violations.
 func (l *Linter) LintContent(filename string, content []byte) ([]rules.Result, error) {
-	doc, err := parser.ParseFragment(filename, content)
+	l.resetRules()
+	return l.lintContent(filename, content)
+}
+
+// lintContent is LintContent within a run.
+func (l *Linter) lintContent(filename string, content []byte) ([]rules.Result, error) {
+	delims := l.config.Templates.DelimsFor(filename)
+	set, err := l.templateSet()
 	if err != nil {
 		return nil, err
 	}
 
+	docs, err := l.parse(filename, content, delims, set, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// Document-level rules check entry-point pages with the templates they
+	// call inlined
+	pageDocs := docs
+	if set != nil && !docs[0].IsTemplateFragment {
+		pageDocs, err = l.parse(filename, content, delims, set, true)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Visitor rules share one walk of each document
+	visited := l.visit(docs, pageDocs)
+
 	var allResults []rules.Result
 	for _, rule := range l.rules {
 		// Check if rule implements RawRule interface for pre-parse checks
 		if rawRule, ok := rule.(rules.RawRule); ok {
-			rawResults := rawRule.CheckRaw(filename, content)
-			for _, r := range rawResults {
-				if severity, ok := l.config.RuleSeverity[r.Rule]; ok {
-					r.Severity = severity
-				}
-				if r.Severity <= l.config.MinSeverity {
-					allResults = append(allResults, r)
-				}
+			if templateRule, ok := rule.(rules.TemplateRawRule); ok {
+				allResults = append(allResults, templateRule.CheckRawDelims(filename, content, delims)...)
+			} else {
+				allResults = append(allResults, rawRule.CheckRaw(filename, content)...)
 			}
 		}
 
-		results := rule.Check(doc)
-		for _, r := range results {
-			// Apply severity override from config
-			if severity, ok := l.config.RuleSeverity[r.Rule]; ok {
-				r.Severity = severity
-			}
-			// Filter by minimum severity
-			if r.Severity <= l.config.MinSeverity {
-				allResults = append(allResults, r)
-			}
+		if _, ok := rule.(rules.VisitorRule); ok {
+			allResults = append(allResults, visited[rule.Name()]...)
+			continue
+		}
+		ruleDocs := docs
+		if _, ok := rule.(rules.DocumentRule); ok {
+			ruleDocs = pageDocs
+		}
+		for _, doc := range ruleDocs {
+			allResults = append(allResults, rule.Check(doc)...)
 		}
 	}
 
-	return allResults, nil
-}
+	if len(docs) > 1 || len(pageDocs) > 1 {
+		allResults = dedupeResults(allResults)
+	}
 
-// LintFiles checks multiple files and returns all violations.
-func (l *Linter) LintFiles(paths []string) ([]rules.Result, error) {
-	var allResults []rules.Result
+	// Inline directives apply before severity filtering, so a directive
+	// for a filtered-out result isn't reported as unused
+	directives := findDirectives(content, docs[0].TemplateActions(), docs[0].SourceMap())
+	allResults = l.applyDirectives(filename, directives, allResults)
 
-	for _, path := range paths {
-		// Skip ignored patterns
-		if l.shouldIgnore(path) {
+	filtered := allResults[:0]
+	for _, r := range allResults {
+		// Apply severity override from config
+		if severity, ok := l.config.RuleSeverity[r.Rule]; ok {
+			r.Severity = severity
+		}
+		// Filter by minimum severity
+		if r.Severity <= l.config.MinSeverity {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered, nil
+}
+
+// visit runs the enabled visitor rules over docs, or over pageDocs for
+// document rules, and returns their results by rule name. When pageDocs is
+// docs, every visitor rule shares a single walk of each document.
+func (l *Linter) visit(docs, pageDocs []*parser.Document) map[string][]rules.Result {
+	var fileRules, pageRules []rules.VisitorRule
+	for _, rule := range l.rules {
+		v, ok := rule.(rules.VisitorRule)
+		if !ok {
 			continue
 		}
+		if _, ok := rule.(rules.DocumentRule); ok && !slices.Equal(pageDocs, docs) {
+			pageRules = append(pageRules, v)
+		} else {
+			fileRules = append(fileRules, v)
+		}
+	}
+
+	results := make(map[string][]rules.Result)
+	dispatch := func(docs []*parser.Document, visitors []rules.VisitorRule) {
+		if len(visitors) == 0 {
+			return
+		}
+		for _, doc := range docs {
+			for i, found := range rules.Dispatch(doc, visitors) {
+				name := visitors[i].Name()
+				results[name] = append(results[name], found...)
+			}
+		}
+	}
+	dispatch(docs, fileRules)
+	dispatch(pageDocs, pageRules)
+	return results
+}
 
-		results, err := l.LintFile(path)
+// parse builds the documents to lint: one per template branch combination
+// when branch exploration is enabled, otherwise just one. With inline set,
+// {{template}} calls are resolved against templates and inlined.
+func (l *Linter) parse(filename string, content []byte, delims parser.Delims, templates *parser.TemplateSet, inline bool) ([]*parser.Document, error) {
+	prep := &parser.Preprocessor{Delims: delims, Templates: templates, Inline: inline}
+	if !l.config.Templates.ExploreBranches {
+		doc, err := prep.ParseFragment(filename, content)
 		if err != nil {
-			// Report error but continue with other files
-			allResults = append(allResults, rules.Result{
-				Rule:     "parse-error",
-				Message:  err.Error(),
-				Filename: path,
-				Line:     1,
-				Col:      1,
-				Severity: rules.Error,
-			})
+			return nil, err
+		}
+		return []*parser.Document{doc}, nil
+	}
+
+	maxVariants := l.config.Templates.MaxVariants
+	if maxVariants <= 0 {
+		maxVariants = parser.DefaultMaxVariants
+	}
+	return prep.ParseFragmentVariants(filename, content, maxVariants)
+}
+
+// dedupeResults removes results reported at the same source position by more
+// than one template variant, keeping the first occurrence.
+func dedupeResults(results []rules.Result) []rules.Result {
+	type key struct {
+		rule, message string
+		line, col     int
+	}
+	seen := make(map[key]bool, len(results))
+	deduped := results[:0]
+	for _, r := range results {
+		k := key{r.Rule, r.Message, r.Line, r.Col}
+		if seen[k] {
 			continue
 		}
-		allResults = append(allResults, results...)
+		seen[k] = true
+		deduped = append(deduped, r)
+	}
+	return deduped
+}
+
+// LintFiles checks multiple files and returns all violations. Files are
+// linted concurrently, Config.Jobs at a time, and results come back grouped
+// by file in the order of paths.
+func (l *Linter) LintFiles(paths []string) ([]rules.Result, error) {
+	l.resetRules()
+	paths = slices.DeleteFunc(slices.Clone(paths), l.shouldIgnore)
+	perFile := make([][]rules.Result, len(paths))
+	diffs := make([]string, len(paths))
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range min(l.jobs(), len(paths)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				perFile[i], diffs[i] = l.lintFileOrError(paths[i])
+			}
+		}()
+	}
+	for i := range paths {
+		next <- i
 	}
+	close(next)
+	wg.Wait()
 
+	// Diffs are written in the order of paths, however files were scheduled
+	if err := l.writeDiffs(diffs...); err != nil {
+		return nil, err
+	}
+
+	var allResults []rules.Result
+	for _, results := range perFile {
+		allResults = append(allResults, results...)
+	}
 	return allResults, nil
 }
 
+// lintFileOrError lints a file, reporting an error reading or parsing it as
+// a result so the other files are still linted. When fixing, it also
+// applies the fixes and returns the diff, if it isn't writing them.
+func (l *Linter) lintFileOrError(path string) ([]rules.Result, string) {
+	if l.fix == FixOff {
+		results, err := l.lintFile(path)
+		if err != nil {
+			return ErrorResults(path, err), ""
+		}
+		return results, ""
+	}
+
+	results, diff, err := l.fixFile(path)
+	if err != nil {
+		return ErrorResults(path, err), ""
+	}
+	return results, diff
+}
+
+// fixFile applies the fixes for the file at path, writing the file back or
+// returning the diff as the fix mode says. The cache isn't used, since the
+// fixes are what's wanted.
+func (l *Linter) fixFile(path string) ([]rules.Result, string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, "", err
+	}
+	content, err := os.ReadFile(path) //nolint:gosec // user-specified file path is intentional
+	if err != nil {
+		return nil, "", err
+	}
+	fixed, results, err := l.fixContent(path, content)
+	if err != nil || bytes.Equal(fixed, content) {
+		return results, "", err
+	}
+
+	if l.fix == FixDryRun {
+		// Results point into the fixed content, which isn't written
+		l.keepSource(path, fixed)
+		return results, unifiedDiff(path, content, fixed), nil
+	}
+	if err := os.WriteFile(path, fixed, info.Mode().Perm()); err != nil {
+		return nil, "", err
+	}
+	return results, "", nil
+}
+
+// writeDiffs writes the diffs of fixed files to the writer given to SetFix.
+func (l *Linter) writeDiffs(diffs ...string) error {
+	if l.diffs == nil {
+		return nil
+	}
+	for _, diff := range diffs {
+		if _, err := io.WriteString(l.diffs, diff); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RuleParseError names the result reporting a file that couldn't be read or
+// parsed.
+const RuleParseError = "parse-error"
+
+// ErrorResults reports an error reading or parsing a file as its only result.
+func ErrorResults(path string, err error) []rules.Result {
+	return []rules.Result{{
+		Rule:     RuleParseError,
+		Message:  err.Error(),
+		Filename: path,
+		Line:     1,
+		Col:      1,
+		Severity: rules.Error,
+	}}
+}
+
+// jobs returns how many files to lint at once.
+func (l *Linter) jobs() int {
+	if l.config.Jobs > 0 {
+		return l.config.Jobs
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
 // LintDir recursively checks all HTML files in a directory.
 func (l *Linter) LintDir(dir string) ([]rules.Result, error) {
-	var files []string
+	files, err := findHTMLFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	return l.LintFiles(files)
+}
 
+// findHTMLFiles returns the HTML files under dir, in lexical order.
+func findHTMLFiles(dir string) ([]string, error) {
+	var files []string
 	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
@@ -143,45 +471,100 @@ func (l *Linter) LintDir(dir string) ([]rules.Result, error) {
 		if info.IsDir() {
 			return nil
 		}
-		if isHTMLFile(path) {
+		if IsHTMLFile(path) {
 			files = append(files, path)
 		}
 		return nil
 	})
-	if err != nil {
-		return nil, err
-	}
-
-	return l.LintFiles(files)
+	return files, err
 }
 
 // Run executes linting and reports results.
 func (l *Linter) Run(paths []string) (int, error) {
-	var allResults []rules.Result
+	// Collect every file first so they're all linted in one pool
+	files, err := collectFiles(paths)
+	if err != nil {
+		return 0, err
+	}
+	files = slices.DeleteFunc(files, l.shouldIgnore)
+	if l.changes != nil {
+		files = slices.DeleteFunc(files, func(path string) bool { return !l.changes.Contains(path) })
+	}
 
+	allResults, err := l.LintFiles(files)
+	if err != nil {
+		return 0, err
+	}
+	if l.changes != nil && l.changedLinesOnly {
+		allResults = slices.DeleteFunc(allResults, func(r rules.Result) bool { return !l.changes.ContainsResult(r) })
+	}
+	return l.report(files, allResults)
+}
+
+// collectFiles returns the files named by paths, with directories replaced
+// by the HTML files under them.
+func collectFiles(paths []string) ([]string, error) {
+	var files []string
 	for _, path := range paths {
 		info, err := os.Stat(path)
 		if err != nil {
-			return 0, err
+			return nil, err
 		}
 
-		var results []rules.Result
 		if info.IsDir() {
-			results, err = l.LintDir(path)
+			found, err := findHTMLFiles(path)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, found...)
 		} else {
-			results, err = l.LintFiles([]string{path})
-		}
-		if err != nil {
-			return 0, err
+			files = append(files, path)
 		}
-		allResults = append(allResults, results...)
+	}
+	return files, nil
+}
+
+// RunContent lints content as if it were the file at filename, which need
+// not exist, and reports the results. An ignored filename reports no results.
+// When fixing, the fixes are written as a diff, as there's no file to write
+// them to.
+func (l *Linter) RunContent(filename string, content []byte) (int, error) {
+	if l.shouldIgnore(filename) {
+		return l.report(nil, nil)
 	}
 
-	if l.reporter != nil {
-		if err := l.reporter.Report(allResults); err != nil {
-			return 0, err
+	var results []rules.Result
+	var err error
+	if l.fix == FixOff {
+		l.keepSource(filename, content)
+		results, err = l.LintContent(filename, content)
+	} else {
+		var fixed []byte
+		fixed, results, err = l.FixContent(filename, content)
+		if err == nil {
+			l.keepSource(filename, fixed)
+			err = l.writeDiffs(unifiedDiff(filename, content, fixed))
+			if err != nil {
+				return 0, err
+			}
 		}
 	}
+	if err != nil {
+		results = ErrorResults(filename, err)
+	}
+	return l.report([]string{filename}, results)
+}
+
+// report passes results for files to the reporters and returns the number
+// of errors. A failing reporter doesn't keep the others from reporting.
+func (l *Linter) report(files []string, allResults []rules.Result) (int, error) {
+	var errs []error
+	for _, r := range l.reporters {
+		errs = append(errs, l.reportTo(r, files, allResults))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return 0, err
+	}
 
 	// Count errors (not warnings)
 	errorCount := 0
@@ -194,6 +577,44 @@ func (l *Linter) Run(paths []string) (int, error) {
 	return errorCount, nil
 }
 
+// reportTo passes results to r, along with the files linted and their
+// source if it takes them.
+func (l *Linter) reportTo(r Reporter, files []string, results []rules.Result) error {
+	if sr, ok := r.(SourceReporter); ok {
+		sr.SetSource(l.readSource)
+	}
+	if fr, ok := r.(FilesReporter); ok {
+		return fr.ReportFiles(files, results)
+	}
+	return r.Report(results)
+}
+
+// keepSource records content linted for path that isn't on disk.
+func (l *Linter) keepSource(path string, content []byte) {
+	l.sourcesMu.Lock()
+	defer l.sourcesMu.Unlock()
+	if l.sources == nil {
+		l.sources = make(map[string][]byte)
+	}
+	l.sources[path] = content
+}
+
+// readSource returns the content linted for path.
+func (l *Linter) readSource(path string) ([]byte, error) {
+	l.sourcesMu.Lock()
+	content, ok := l.sources[path]
+	l.sourcesMu.Unlock()
+	if ok {
+		return content, nil
+	}
+	return os.ReadFile(path) //nolint:gosec // user-specified file path is intentional
+}
+
+// IsIgnored reports whether the file at path matches an ignore pattern.
+func (l *Linter) IsIgnored(path string) bool {
+	return l.shouldIgnore(path)
+}
+
 func (l *Linter) shouldIgnore(path string) bool {
 	for _, pattern := range l.config.IgnorePatterns {
 		if matchIgnorePattern(path, pattern) {
@@ -267,7 +688,9 @@ func matchDoublestar(path, pattern string) bool {
 	return strings.HasPrefix(path, prefix+"/") || path == prefix
 }
 
-func isHTMLFile(path string) bool {
+// IsHTMLFile reports whether path has an extension the linter checks when
+// walking a directory.
+func IsHTMLFile(path string) bool {
 	ext := strings.ToLower(filepath.Ext(path))
 	return ext == ".html" || ext == ".htm" || ext == ".gohtml" || ext == ".tmpl"
 }
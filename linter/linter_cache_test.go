@@ -0,0 +1,125 @@
+package linter_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/toba/go-html-validate/linter"
+	"github.com/toba/go-html-validate/rules"
+)
+
+func TestCache(t *testing.T) {
+	dir := t.TempDir()
+	page := filepath.Join(dir, "page.html")
+	if err := os.WriteFile(page, []byte(`<img src="a.png">`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cachePath := filepath.Join(dir, linter.DefaultCacheFile)
+
+	lint := func(cfg *linter.Config, version string) []rules.Result {
+		t.Helper()
+		l := linter.New(cfg)
+		cache, err := l.OpenCache(dir, version)
+		if err != nil {
+			t.Fatalf("OpenCache() error = %v", err)
+		}
+		results, err := l.LintFile(page)
+		if err != nil {
+			t.Fatalf("LintFile() error = %v", err)
+		}
+		if err := cache.Save(); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+		return results
+	}
+
+	// Rewrite every cached message, so results read from the cache can be
+	// told apart from fresh ones
+	tamper := func() {
+		t.Helper()
+		data, err := os.ReadFile(cachePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var f map[string]json.RawMessage
+		if err := json.Unmarshal(data, &f); err != nil {
+			t.Fatal(err)
+		}
+		var files map[string]struct {
+			Hash    string         `json:"hash"`
+			Results []rules.Result `json:"results"`
+		}
+		if err := json.Unmarshal(f["files"], &files); err != nil {
+			t.Fatal(err)
+		}
+		for path, e := range files {
+			for i := range e.Results {
+				e.Results[i].Message = "cached"
+			}
+			files[path] = e
+		}
+		f["files"], _ = json.Marshal(files)
+		data, _ = json.Marshal(f)
+		if err := os.WriteFile(cachePath, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cached := func(results []rules.Result) bool {
+		return len(results) > 0 && results[0].Message == "cached"
+	}
+
+	first := lint(linter.DefaultConfig(), "1.0.0")
+	if !hasRule(first, "img-alt") {
+		t.Fatalf("expected img-alt, got %v", first)
+	}
+	tamper()
+	if got := lint(linter.DefaultConfig(), "1.0.0"); !cached(got) {
+		t.Errorf("unchanged file was linted again: %v", got)
+	}
+
+	tests := []struct {
+		name    string
+		cfg     func(*linter.Config)
+		version string
+	}{
+		{"version changed", nil, "1.1.0"},
+		{"severity changed", func(c *linter.Config) { c.RuleSeverity = map[string]rules.Severity{"img-alt": rules.Warning} }, "1.0.0"},
+		{"options changed", func(c *linter.Config) {
+			c.RuleOptions = map[string]map[string]any{rules.RuleClassPattern: {"pattern": "bem"}}
+		}, "1.0.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tamper()
+			cfg := linter.DefaultConfig()
+			if tt.cfg != nil {
+				tt.cfg(cfg)
+			}
+			if got := lint(cfg, tt.version); cached(got) {
+				t.Errorf("expected cache to be invalidated, got %v", got)
+			}
+		})
+	}
+
+	t.Run("content changed", func(t *testing.T) {
+		lint(linter.DefaultConfig(), "1.0.0")
+		tamper()
+		if err := os.WriteFile(page, []byte(`<img src="b.png">`), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if got := lint(linter.DefaultConfig(), "1.0.0"); cached(got) || !hasRule(got, "img-alt") {
+			t.Errorf("changed file was not linted again: %v", got)
+		}
+	})
+
+	t.Run("corrupt cache file", func(t *testing.T) {
+		if err := os.WriteFile(cachePath, []byte("{not json"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if got := lint(linter.DefaultConfig(), "1.0.0"); !hasRule(got, "img-alt") {
+			t.Errorf("expected img-alt, got %v", got)
+		}
+	})
+}
@@ -0,0 +1,121 @@
+package linter_test
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/toba/go-html-validate/linter"
+	"github.com/toba/go-html-validate/rules"
+)
+
+func TestRun_ChangedSince(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	runGit := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+			"GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_NOSYSTEM=1")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	legacy := "<img src=\"a.png\">\n<p>ok</p>\n<img src=\"b.png\">\n"
+	write("web/page.html", legacy)
+	write("web/same.html", legacy)
+	write("web/gone.html", legacy)
+	runGit("init", "-q")
+	runGit("add", ".")
+	runGit("commit", "-q", "-m", "initial")
+
+	// Change the middle line of page.html, delete a file and add an
+	// untracked one
+	write("web/page.html", "<img src=\"a.png\">\n<img src=\"new.png\">\n<img src=\"b.png\">\n")
+	write("web/new file.html", "<img src=\"c.png\">\n")
+	if err := os.Remove(filepath.Join(dir, "web", "gone.html")); err != nil {
+		t.Fatal(err)
+	}
+
+	web := filepath.Join(dir, "web")
+	changes, err := linter.GitChanges(web, "HEAD")
+	if err != nil {
+		t.Fatalf("GitChanges() error = %v", err)
+	}
+	if n := changes.Files(); n != 2 {
+		t.Errorf("Files() = %d, want 2", n)
+	}
+
+	// A file's parse error counts wherever it's reported, but other results
+	// only on changed lines
+	page := filepath.Join(web, "page.html")
+	if !changes.ContainsResult(rules.Result{Rule: "parse-error", Filename: page, Line: 1, Col: 1}) {
+		t.Error("ContainsResult() = false for a parse error in a changed file")
+	}
+	if changes.ContainsResult(rules.Result{Rule: rules.RuleImgAlt, Filename: page, Line: 1, Col: 1}) {
+		t.Error("ContainsResult() = true for an unchanged line")
+	}
+	if changes.ContainsResult(rules.Result{Rule: "parse-error", Filename: filepath.Join(web, "same.html"), Line: 1, Col: 1}) {
+		t.Error("ContainsResult() = true for a parse error in an unchanged file")
+	}
+
+	tests := []struct {
+		name      string
+		linesOnly bool
+		want      []string
+	}{
+		{
+			name: "changed files",
+			want: []string{"new file.html:1", "page.html:1", "page.html:2", "page.html:3"},
+		},
+		{
+			name:      "changed lines",
+			linesOnly: true,
+			want:      []string{"new file.html:1", "page.html:2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := linter.DefaultConfig()
+			cfg.DisabledRules = []string{rules.RuleElementRequiredAttributes}
+			l := linter.New(cfg)
+			l.SetChanges(changes, tt.linesOnly)
+			rec := &resultsRecorder{}
+			l.SetReporter(rec)
+			if _, err := l.Run([]string{web}); err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			var got []string
+			for _, r := range rec.results {
+				got = append(got, fmt.Sprintf("%s:%d", filepath.Base(r.Filename), r.Line))
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("results at %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := linter.GitChanges(web, "no-such-rev"); err == nil {
+		t.Error("expected an error for an unknown revision")
+	}
+}
@@ -0,0 +1,141 @@
+package linter_test
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/toba/go-html-validate/linter"
+	"github.com/toba/go-html-validate/rules"
+)
+
+func TestLintContent_Directives(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		// want lists the expected img-alt and directive findings as
+		// "rule@line:col"
+		want []string
+	}{
+		{
+			name: "no directive",
+			html: `<img src="a.png">`,
+			want: []string{"img-alt@1:1"},
+		},
+		{
+			name: "disable-next",
+			html: "<!-- htmlvalidate-disable-next img-alt -->\n<img src=\"a.png\">\n<img src=\"b.png\">",
+			want: []string{"img-alt@3:1"},
+		},
+		{
+			name: "disable and enable block",
+			html: "<!-- htmlvalidate-disable img-alt -->\n<img src=\"a.png\">\n<img src=\"b.png\">\n" +
+				"<!-- htmlvalidate-enable img-alt -->\n<img src=\"c.png\">",
+			want: []string{"img-alt@5:1"},
+		},
+		{
+			name: "disable all, enable one",
+			html: "<!-- htmlvalidate-disable -->\n<img src=\"a.png\">\n" +
+				"<!-- htmlvalidate-enable img-alt -->\n<img src=\"b.png\">",
+			want: []string{"img-alt@4:1"},
+		},
+		{
+			name: "template comment disable-line",
+			html: "<img src=\"a.png\">{{/* htmlint-disable-line img-alt */}}\n<img src=\"b.png\">",
+			want: []string{"img-alt@2:1"},
+		},
+		{
+			name: "comma separated rules with reason",
+			html: "<!-- htmlvalidate-disable-next img-alt,no-inline-style -- decorative -->\n<img src=\"a.png\" style=\"x\">",
+		},
+		{
+			name: "unused directive",
+			html: "<!-- htmlvalidate-disable-next img-alt -->\n<img src=\"a.png\" alt=\"A\">",
+			want: []string{"disable-directive@1:1"},
+		},
+		{
+			name: "unknown rule",
+			html: "<!-- htmlvalidate-disable-next img-alt, no-such-rule -->\n<img src=\"a.png\">",
+			want: []string{"disable-directive@1:1"},
+		},
+		{
+			name: "unknown directive",
+			html: "{{/* htmlint-disabel img-alt */}}<p>Text</p>",
+			want: []string{"disable-directive@1:1"},
+		},
+		{
+			name: "comment markers inside actions are ignored",
+			html: `<p title="{{ "<!-- htmlvalidate-disable -->" }}">Text</p><img src="a.png">`,
+			want: []string{"img-alt@1:58"},
+		},
+	}
+
+	l := linter.New(nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := l.LintContent("test.html", []byte(tt.html))
+			if err != nil {
+				t.Fatalf("LintContent() error = %v", err)
+			}
+			var got []string
+			for _, r := range results {
+				if r.Rule == rules.RuleImgAlt || r.Rule == rules.RuleDisableDirective {
+					got = append(got, fmt.Sprintf("%s@%d:%d", r.Rule, r.Line, r.Col))
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v (all results: %v)", got, tt.want, results)
+			}
+		})
+	}
+}
+
+func TestLintContent_DirectivesBeforeSeverityFilter(t *testing.T) {
+	// The directive suppresses an Info result; filtering it out afterwards
+	// mustn't make the directive look unused
+	cfg := linter.DefaultConfig().ErrorsOnly()
+	cfg.RuleSeverity[rules.RuleDisableDirective] = rules.Error
+	l := linter.New(cfg)
+
+	html := "<!-- htmlvalidate-disable-next allowed-links -->\n<a href=\"\">Link</a>"
+	results, err := l.LintContent("test.html", []byte(html))
+	if err != nil {
+		t.Fatalf("LintContent() error = %v", err)
+	}
+	if hasRule(results, rules.RuleDisableDirective) {
+		t.Errorf("expected no %s, got %v", rules.RuleDisableDirective, results)
+	}
+}
+
+func TestLintContent_DirectiveRuleConfigurable(t *testing.T) {
+	if rules.NewRegistry().ByName(rules.RuleDisableDirective) == nil {
+		t.Fatalf("%s is not in the registry", rules.RuleDisableDirective)
+	}
+
+	html := "<!-- htmlvalidate-disable-next img-alt -->\n<p>nothing to suppress</p>"
+	for _, tt := range []struct {
+		name string
+		off  bool
+	}{
+		{name: "enabled"},
+		{name: "disabled", off: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := linter.DefaultConfig()
+			if tt.off {
+				cfg.DisabledRules = []string{rules.RuleDisableDirective}
+			}
+			l := linter.New(cfg)
+			if got := slices.Contains(l.RuleNames(), rules.RuleDisableDirective); got == tt.off {
+				t.Errorf("RuleNames() lists %s = %v", rules.RuleDisableDirective, got)
+			}
+			results, err := l.LintContent("test.html", []byte(html))
+			if err != nil {
+				t.Fatalf("LintContent() error = %v", err)
+			}
+			if got := hasRule(results, rules.RuleDisableDirective); got == tt.off {
+				t.Errorf("%s reported = %v, want %v", rules.RuleDisableDirective, got, !tt.off)
+			}
+		})
+	}
+}
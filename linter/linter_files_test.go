@@ -0,0 +1,286 @@
+package linter_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/toba/go-html-validate/linter"
+	"github.com/toba/go-html-validate/rules"
+)
+
+func TestLintFiles_Parallel(t *testing.T) {
+	dir := t.TempDir()
+	pages := []string{
+		`<img src="a.png">`,
+		`<div hx-get="/items" hx-swap="sideways">Items</div>`,
+		`{{ if .A }}<p id="x">A</p>{{ else }}<p id="x">B</p>{{ end }}<p id="x">C</p>`,
+		`<a href="javascript:void(0)">Link</a>`,
+	}
+	var paths []string
+	for i := range 40 {
+		path := filepath.Join(dir, fmt.Sprintf("page%02d.html", i))
+		if err := os.WriteFile(path, []byte(pages[i%len(pages)]), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	// Unreadable files are reported in place; ignored files are skipped
+	paths = append(paths[:10], append([]string{filepath.Join(dir, "missing.html")}, paths[10:]...)...)
+
+	lint := func(jobs int) []string {
+		cfg := linter.DefaultConfig()
+		cfg.Jobs = jobs
+		cfg.Frameworks.HTMX = true
+		cfg.Templates.ExploreBranches = true
+		cfg.IgnorePatterns = []string{"page3*.html"}
+		results, err := linter.New(cfg).LintFiles(paths)
+		if err != nil {
+			t.Fatalf("LintFiles() error = %v", err)
+		}
+		var got []string
+		for _, r := range results {
+			got = append(got, fmt.Sprintf("%s:%d:%d %s", filepath.Base(r.Filename), r.Line, r.Col, r.Rule))
+		}
+		return got
+	}
+
+	sequential := lint(1)
+	if len(sequential) == 0 {
+		t.Fatal("expected results")
+	}
+	if !slices.Contains(sequential, "missing.html:1:1 parse-error") {
+		t.Errorf("expected parse-error for missing file, got %v", sequential)
+	}
+	if got := sequential[len(sequential)-1]; got[:6] != "page29" {
+		t.Errorf("last result from %s, want page29 (page3* ignored)", got)
+	}
+	for range 5 {
+		if parallel := lint(8); !reflect.DeepEqual(parallel, sequential) {
+			t.Fatalf("parallel results differ from sequential:\n%v\n%v", parallel, sequential)
+		}
+	}
+}
+
+// resultsRecorder is a reporter that keeps the results it's given.
+type resultsRecorder struct {
+	results []rules.Result
+	calls   int
+}
+
+func (r *resultsRecorder) Report(results []rules.Result) error {
+	r.results = results
+	r.calls++
+	return nil
+}
+
+func TestRunContent(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		content  string
+		want     []string
+		errors   int
+	}{
+		{
+			name:     "results carry filename",
+			filename: "web/page.gohtml",
+			content:  `<img src="a.png">`,
+			want:     []string{"web/page.gohtml:1:1 img-alt"},
+			errors:   1,
+		},
+		{
+			name:     "file need not exist",
+			filename: "web/missing/new.html",
+			content:  `<p>Hello</p>`,
+		},
+		{
+			name:     "ignored filename",
+			filename: "vendor/page.html",
+			content:  `<img src="a.png">`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := linter.DefaultConfig()
+			cfg.DisabledRules = []string{"element-required-attributes"}
+			cfg.IgnorePatterns = []string{"vendor/"}
+			l := linter.New(cfg)
+			rec := &resultsRecorder{}
+			l.SetReporter(rec)
+
+			errors, err := l.RunContent(tt.filename, []byte(tt.content))
+			if err != nil {
+				t.Fatalf("RunContent() error = %v", err)
+			}
+			var got []string
+			for _, r := range rec.results {
+				got = append(got, fmt.Sprintf("%s:%d:%d %s", r.Filename, r.Line, r.Col, r.Rule))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if errors != tt.errors {
+				t.Errorf("got %d errors, want %d", errors, tt.errors)
+			}
+			if rec.calls != 1 {
+				t.Errorf("reporter called %d times, want 1", rec.calls)
+			}
+		})
+	}
+}
+
+// filesRecorder is a reporter that keeps the files it's given.
+type filesRecorder struct {
+	resultsRecorder
+	files []string
+}
+
+func (r *filesRecorder) ReportFiles(files []string, results []rules.Result) error {
+	r.files = files
+	return r.Report(results)
+}
+
+func TestRun_FilesReporter(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"clean.html":   `<p>ok</p>`,
+		"page.html":    `<img src="a.png">`,
+		"ignored.html": `<img src="a.png">`,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := linter.DefaultConfig()
+	cfg.IgnorePatterns = []string{"ignored.html"}
+	l := linter.New(cfg)
+	rec := &filesRecorder{}
+	l.SetReporter(rec)
+	if _, err := l.Run([]string{dir}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	var files []string
+	for _, f := range rec.files {
+		files = append(files, filepath.Base(f))
+	}
+	// Clean files are listed; ignored files aren't
+	if want := []string{"clean.html", "page.html"}; !slices.Equal(files, want) {
+		t.Errorf("reported files %v, want %v", files, want)
+	}
+	if rec.calls != 1 || len(rec.results) == 0 {
+		t.Errorf("reported %d times with %d results", rec.calls, len(rec.results))
+	}
+
+	if _, err := l.RunContent("stdin.html", []byte(`<p>ok</p>`)); err != nil {
+		t.Fatalf("RunContent() error = %v", err)
+	}
+	if !slices.Equal(rec.files, []string{"stdin.html"}) {
+		t.Errorf("RunContent reported files %v", rec.files)
+	}
+}
+
+// sourceRecorder is a reporter that reads the source of each result's file.
+type sourceRecorder struct {
+	read    func(path string) ([]byte, error)
+	sources map[string]string
+}
+
+func (r *sourceRecorder) SetSource(read func(path string) ([]byte, error)) {
+	r.read = read
+}
+
+func (r *sourceRecorder) Report(results []rules.Result) error {
+	r.sources = make(map[string]string)
+	for _, res := range results {
+		content, err := r.read(res.Filename)
+		if err != nil {
+			return err
+		}
+		r.sources[filepath.Base(res.Filename)] = string(content)
+	}
+	return nil
+}
+
+func TestRun_SourceReporter(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "page.html")
+	if err := os.WriteFile(path, []byte(`<img src="a.png"><button>Go</button>`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := &sourceRecorder{}
+	l := linter.New(nil)
+	l.SetReporter(rec)
+	if _, err := l.Run([]string{path}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if got := rec.sources["page.html"]; got != `<img src="a.png"><button>Go</button>` {
+		t.Errorf("source read from disk = %q", got)
+	}
+
+	// Standard input isn't on disk
+	if _, err := l.RunContent(filepath.Join(dir, "stdin.html"), []byte(`<img src="b.png">`)); err != nil {
+		t.Fatalf("RunContent() error = %v", err)
+	}
+	if got := rec.sources["stdin.html"]; got != `<img src="b.png">` {
+		t.Errorf("source of standard input = %q", got)
+	}
+
+	// Dry-run results point into the fixed content, which isn't written
+	l.SetFix(linter.FixDryRun, io.Discard)
+	if _, err := l.Run([]string{path}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if got := rec.sources["page.html"]; got != `<img src="a.png"><button type="button">Go</button>` {
+		t.Errorf("source after dry-run fix = %q", got)
+	}
+}
+
+// failingReporter is a reporter that always fails.
+type failingReporter struct{}
+
+func (failingReporter) Report([]rules.Result) error { return errors.New("disk full") }
+
+func TestRun_Reporters(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "page.html")
+	if err := os.WriteFile(path, []byte(`<img src="a.png">`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	l := linter.New(nil)
+	first, second := &resultsRecorder{}, &filesRecorder{}
+	l.SetReporter(first)
+	l.AddReporter(second)
+	errorCount, err := l.Run([]string{path})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if first.calls != 1 || second.calls != 1 {
+		t.Errorf("reporters called %d and %d times, want once each", first.calls, second.calls)
+	}
+	if !reflect.DeepEqual(first.results, second.results) || len(first.results) != errorCount {
+		t.Errorf("reporters got %v and %v, want the same %d errors", first.results, second.results, errorCount)
+	}
+	if len(second.files) != 1 {
+		t.Errorf("files reporter got files %v", second.files)
+	}
+
+	// A failing reporter doesn't stop the others
+	l.SetReporter(failingReporter{})
+	l.AddReporter(first)
+	if _, err := l.Run([]string{path}); err == nil || err.Error() != "disk full" {
+		t.Errorf("Run() error = %v, want disk full", err)
+	}
+	if first.calls != 2 {
+		t.Errorf("reporter after the failing one called %d times, want 2", first.calls)
+	}
+}
@@ -0,0 +1,196 @@
+package linter_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/toba/go-html-validate/linter"
+)
+
+func TestFixContent(t *testing.T) {
+	tests := []struct {
+		name string
+		rule string
+		html string
+		want string
+	}{
+		{
+			name: "button type",
+			rule: "button-type",
+			html: `<button class="go">Go</button>`,
+			want: `<button type="button" class="go">Go</button>`,
+		},
+		{
+			name: "input type",
+			rule: "no-implicit-input-type",
+			html: "<input\n  name=\"q\">",
+			want: "<input type=\"text\"\n  name=\"q\">",
+		},
+		{
+			name: "duplicate classes",
+			rule: "no-dup-class",
+			html: `<p class="a b a  b a">X</p>`,
+			want: `<p class="a b">X</p>`,
+		},
+		{
+			name: "svg focusable missing",
+			rule: "svg-focusable",
+			html: `<button type="button"><svg></svg></button>`,
+			want: `<button type="button"><svg focusable="false"></svg></button>`,
+		},
+		{
+			name: "svg focusable wrong",
+			rule: "svg-focusable",
+			html: `<a href="/"><svg focusable='true'></svg><svg focusable></svg></a>`,
+			want: `<a href="/"><svg focusable='false'></svg><svg focusable="false"></svg></a>`,
+		},
+		{
+			name: "trim marker",
+			rule: "template-whitespace-trim",
+			html: "{{ if .A }}\n<p>A</p>\n{{end}}\n",
+			want: "{{ if .A -}}\n<p>A</p>\n{{end -}}\n",
+		},
+		{
+			name: "center",
+			rule: "deprecated",
+			html: `<CENTER class="x"><center>A</center></Center>`,
+			want: `<div class="x"><div>A</div></div>`,
+		},
+		{
+			name: "center keeps its attributes",
+			rule: "deprecated",
+			html: `<center id="c" style="color: red">A</center>`,
+			want: `<div id="c" style="color: red">A</div>`,
+		},
+		{
+			name: "template content in attribute values is kept",
+			rule: "button-type",
+			html: `<button class="{{.Class}}" data-x="{{if .X}}a{{end}}">Go</button>`,
+			want: `<button type="button" class="{{.Class}}" data-x="{{if .X}}a{{end}}">Go</button>`,
+		},
+		{
+			name: "conditional attributes are left alone",
+			rule: "button-type",
+			html: `<button {{if .Submit}}type="submit"{{else}}disabled{{end}}>Go</button>`,
+			want: `<button {{if .Submit}}type="submit"{{else}}disabled{{end}}>Go</button>`,
+		},
+		{
+			name: "duplicate classes from templates are left alone",
+			rule: "no-dup-class",
+			html: `<p class="a {{if .X}}a{{end}} a">X</p>`,
+			want: `<p class="a {{if .X}}a{{end}} a">X</p>`,
+		},
+		{
+			name: "element in a define is fixed in place",
+			rule: "button-type",
+			html: `{{define "b"}}<button>Go</button>{{end}}{{template "b"}}`,
+			want: `{{define "b"}}<button type="button">Go</button>{{end}}{{template "b"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := linter.DefaultConfig()
+			cfg.EnabledRules = []string{tt.rule}
+			cfg.Templates.ExploreBranches = true
+			fixed, results, err := linter.New(cfg).FixContent("test.html", []byte(tt.html))
+			if err != nil {
+				t.Fatalf("FixContent() error = %v", err)
+			}
+			if string(fixed) != tt.want {
+				t.Errorf("got  %q\nwant %q", fixed, tt.want)
+			}
+			if tt.html != tt.want && hasRule(results, tt.rule) {
+				t.Errorf("expected %s fixed, got %v", tt.rule, results)
+			}
+		})
+	}
+}
+
+func TestFixContent_NoNewProblems(t *testing.T) {
+	// A fix mustn't trade its problem for another rule's
+	fixed, results, err := linter.New(nil).FixContent("test.html", []byte(`<center><p>A</p></center>`))
+	if err != nil {
+		t.Fatalf("FixContent() error = %v", err)
+	}
+	if string(fixed) != `<div><p>A</p></div>` {
+		t.Errorf("got %q", fixed)
+	}
+	if hasRule(results, "no-inline-style") || hasRule(results, "deprecated") {
+		t.Errorf("unexpected results after fixing: %v", results)
+	}
+}
+
+func TestRun_Fix(t *testing.T) {
+	const (
+		page  = "<div>\n<button>A</button>\n<p>1</p>\n<p>2</p>\n<p>3</p>\n<p>4</p>\n<p>5</p>\n<p>6</p>\n<p>7</p>\n<input>\n</div>"
+		fixed = "<div>\n<button type=\"button\">A</button>\n<p>1</p>\n<p>2</p>\n<p>3</p>\n<p>4</p>\n<p>5</p>\n<p>6</p>\n<p>7</p>\n<input type=\"text\">\n</div>"
+	)
+
+	run := func(t *testing.T, mode linter.FixMode) (string, string) {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), "page.html")
+		if err := os.WriteFile(path, []byte(page), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		cfg := linter.DefaultConfig()
+		cfg.EnabledRules = []string{"button-type", "no-implicit-input-type"}
+		l := linter.New(cfg)
+		var diff bytes.Buffer
+		l.SetFix(mode, &diff)
+		rec := &resultsRecorder{}
+		l.SetReporter(rec)
+		if _, err := l.Run([]string{path}); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if len(rec.results) != 0 {
+			t.Errorf("expected every result fixed, got %v", rec.results)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(content), strings.ReplaceAll(diff.String(), filepath.Dir(path), "dir")
+	}
+
+	t.Run("write", func(t *testing.T) {
+		content, diff := run(t, linter.FixWrite)
+		if content != fixed {
+			t.Errorf("file not fixed: %q", content)
+		}
+		if diff != "" {
+			t.Errorf("unexpected diff: %s", diff)
+		}
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		content, diff := run(t, linter.FixDryRun)
+		if content != page {
+			t.Errorf("file changed: %q", content)
+		}
+		want := `--- dir/page.html
++++ dir/page.html
+@@ -1,5 +1,5 @@
+ <div>
+-<button>A</button>
++<button type="button">A</button>
+ <p>1</p>
+ <p>2</p>
+ <p>3</p>
+@@ -7,5 +7,5 @@
+ <p>5</p>
+ <p>6</p>
+ <p>7</p>
+-<input>
++<input type="text">
+ </div>
+\ No newline at end of file
+`
+		if diff != want {
+			t.Errorf("got diff:\n%s\nwant:\n%s", diff, want)
+		}
+	})
+}
@@ -0,0 +1,206 @@
+package linter_test
+
+import (
+	"testing"
+
+	"github.com/toba/go-html-validate/linter"
+	"github.com/toba/go-html-validate/rules"
+)
+
+func TestLintContent_SourcePositions(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		rule     string
+		wantLine int
+		wantCol  int
+	}{
+		{
+			name:     "element on first line",
+			html:     `<p>text</p><img src="a.jpg">`,
+			rule:     rules.RuleImgAlt,
+			wantLine: 1,
+			wantCol:  12,
+		},
+		{
+			name: "nested element on later line",
+			html: `<div>
+  <section>
+    <img src="a.jpg">
+  </section>
+</div>`,
+			rule:     rules.RuleImgAlt,
+			wantLine: 3,
+			wantCol:  5,
+		},
+		{
+			name: "element after full document structure",
+			html: `<!DOCTYPE html>
+<html lang="en">
+<head><title>Page</title></head>
+<body>
+  <button>Go</button>
+</body>
+</html>`,
+			rule:     rules.RuleButtonType,
+			wantLine: 5,
+			wantCol:  3,
+		},
+		{
+			name: "element inside implied table body",
+			html: `<table>
+  <tr><td><img src="a.jpg"></td></tr>
+</table>`,
+			rule:     rules.RuleImgAlt,
+			wantLine: 2,
+			wantCol:  11,
+		},
+		{
+			name: "element after script content",
+			html: `<script>
+  if (a < b) { document.write("<img>"); }
+</script>
+<img src="a.jpg">`,
+			rule:     rules.RuleImgAlt,
+			wantLine: 4,
+			wantCol:  1,
+		},
+		{
+			name:     "element after template expression on same line",
+			html:     `<p>{{ .Greeting }}</p><img src="{{ .Src }}">`,
+			rule:     rules.RuleImgAlt,
+			wantLine: 1,
+			wantCol:  23,
+		},
+		{
+			name: "element after removed else branch",
+			html: `{{if .A}}
+<p>a</p>
+{{else}}
+<p>b</p>
+<p>c</p>
+{{end}}
+<img src="a.jpg">`,
+			rule:     rules.RuleImgAlt,
+			wantLine: 7,
+			wantCol:  1,
+		},
+		{
+			name:     "element after closing delimiter inside string literal",
+			html:     `<p title="{{ printf "}}" }}">x</p><img src="a.jpg">`,
+			rule:     rules.RuleImgAlt,
+			wantLine: 1,
+			wantCol:  35,
+		},
+		{
+			name:     "element after trimmed else with",
+			html:     "{{- with .A }}<p>a</p>{{- else with .B -}}\n<p>b</p>{{ end }}<img src=\"a.jpg\">",
+			rule:     rules.RuleImgAlt,
+			wantLine: 2,
+			wantCol:  18,
+		},
+		{
+			name:     "element after template comment",
+			html:     `{{/* a comment */}}<button>Go</button>`,
+			rule:     rules.RuleButtonType,
+			wantLine: 1,
+			wantCol:  20,
+		},
+	}
+
+	l := linter.New(nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := l.LintContent("test.html", []byte(tt.html))
+			if err != nil {
+				t.Fatalf("LintContent() error = %v", err)
+			}
+			for _, r := range results {
+				if r.Rule != tt.rule {
+					continue
+				}
+				if r.Line != tt.wantLine || r.Col != tt.wantCol {
+					t.Errorf("%s at %d:%d, want %d:%d", tt.rule, r.Line, r.Col, tt.wantLine, tt.wantCol)
+				}
+				return
+			}
+			t.Errorf("expected %s rule, got %v", tt.rule, results)
+		})
+	}
+}
+
+func TestLintContent_AttributeRanges(t *testing.T) {
+	tests := []struct {
+		name        string
+		html        string
+		rule        string
+		htmx        bool
+		wantLine    int
+		wantCol     int
+		wantEndLine int
+		wantEndCol  int
+	}{
+		{
+			name:        "invalid attribute value",
+			html:        `<input type="txt">`,
+			rule:        rules.RuleAttributeAllowedValues,
+			wantLine:    1,
+			wantCol:     14,
+			wantEndLine: 1,
+			wantEndCol:  17,
+		},
+		{
+			name:        "second duplicate attribute",
+			html:        `<input type="text" name="a"` + "\n" + `  name="b">`,
+			rule:        rules.RuleNoDupAttr,
+			wantLine:    2,
+			wantCol:     3,
+			wantEndLine: 2,
+			wantEndCol:  11,
+		},
+		{
+			name:        "deprecated attribute",
+			html:        `<table border="1"><tbody><tr><td>x</td></tr></tbody></table>`,
+			rule:        rules.RuleNoDeprecatedAttr,
+			wantLine:    1,
+			wantCol:     8,
+			wantEndLine: 1,
+			wantEndCol:  18,
+		},
+		{
+			name:        "invalid htmx swap value after template expression",
+			html:        `<div>{{ .X }}</div><div hx-get="/a" hx-swap="sideways">x</div>`,
+			rule:        rules.RuleHTMXAttributes,
+			htmx:        true,
+			wantLine:    1,
+			wantCol:     46,
+			wantEndLine: 1,
+			wantEndCol:  54,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := linter.DefaultConfig()
+			cfg.Frameworks.HTMX = tt.htmx
+			l := linter.New(cfg)
+			results, err := l.LintContent("test.html", []byte(tt.html))
+			if err != nil {
+				t.Fatalf("LintContent() error = %v", err)
+			}
+			for _, r := range results {
+				if r.Rule != tt.rule {
+					continue
+				}
+				if r.Line != tt.wantLine || r.Col != tt.wantCol ||
+					r.EndLine != tt.wantEndLine || r.EndCol != tt.wantEndCol {
+					t.Errorf("%s at %d:%d-%d:%d, want %d:%d-%d:%d", tt.rule,
+						r.Line, r.Col, r.EndLine, r.EndCol,
+						tt.wantLine, tt.wantCol, tt.wantEndLine, tt.wantEndCol)
+				}
+				return
+			}
+			t.Errorf("expected %s rule, got %v", tt.rule, results)
+		})
+	}
+}
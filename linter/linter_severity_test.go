@@ -0,0 +1,91 @@
+package linter_test
+
+import (
+	"testing"
+
+	"github.com/toba/go-html-validate/linter"
+	"github.com/toba/go-html-validate/rules"
+)
+
+// severityCorpus holds documents that break as many rules as they can, at
+// every level those rules report.
+var severityCorpus = map[string]string{
+	"page.html": `<!DOCTYPE html4>
+<html><head><title></title><meta http-equiv="refresh" content="5">
+<script src="https://cdn.example.com/a.js" type="text/javascript"></script>
+<style>p { color: red }</style>
+<link rel="stylesheet" href="https://cdn.example.com/a.css">
+</head>
+<body aria-hidden="true">
+<center><font color="red">old</font></center>
+<!--[if IE]><p>ie</p><![endif]-->
+<img src="a.png"><area href="#"><input><input type="bogus" id="x"><input id="x">
+<button></button><button type="submit"><div>block</div></button>
+<a href="javascript:void(0)"></a><a href="">empty</a><a>no href</a>
+<div role="button" tabindex="5" onclick="go()">click</div>
+<div role="widget"></div><nav role="navigation"></nav>
+<span aria-label="x">x</span><label for="missing">L</label>
+<h1></h1><h3>skip</h3><h1>again</h1>
+<main></main><main></main>
+<table><tr><td>1</td></tr></table>
+<p style="color: red" class="a a Bad_Class" id="Bad ID">&bogus; text</p>
+<video autoplay></video>
+<form><input name="q"><input name="q"></form>
+<map name="m" id="n"></map><map name="m"></map>
+<svg></svg><ul><div></div></ul><p><div></div></p>
+<br>text</br><img src="b.png" alt="">
+<select><option></option></select>
+<a href="tel:+1 555 1234">+1 555 1234</a>
+<input type="text" autocomplete="bogus" aria-labelledby="nope">
+<abbr title="x"></abbr><blink>x</blink><marquee>x</marquee>
+<div hidden><button>hidden</button></div>
+<iframe src="x"></iframe><object></object><embed>
+<input type="image"><input type="submit" value=""><textarea></textarea>
+</body></html>`,
+	"template.gohtml": `{{define "page"}}{{ if .A }}
+<a href="{{.URL}}" onclick="{{.JS}}" title={{.T}}>x</a>
+<script>var s = "{{.S}}";</script>
+{{template "missing" .}}
+{{end}}{{end}}{{define "unused"}}<p></p>{{end}}`,
+	"broken.gohtml": `<p>{{if}}</p>`,
+	"htmx.html":     `<div hx-get="/a" hx-trigger="bogus delay:x" hx-swap="sideways" hx-target="nope" hx-bogus="1" hx-on="x"></div>`,
+}
+
+func TestDefaultSeverity(t *testing.T) {
+	registry := rules.NewRegistry()
+	for _, rule := range registry.All() {
+		d, ok := rule.(rules.SeverityDefaulter)
+		if !ok {
+			t.Errorf("%s doesn't declare a default severity", rule.Name())
+			continue
+		}
+		if s := d.DefaultSeverity(); s < rules.Error || s > rules.Info {
+			t.Errorf("%s has default severity %d", rule.Name(), s)
+		}
+	}
+	// Rules from elsewhere needn't declare one
+	if s := rules.DefaultSeverity(&eventRecorder{}); s != rules.Warning {
+		t.Errorf("undeclared default severity = %s, want %s", s, rules.Warning)
+	}
+
+	cfg := linter.DefaultConfig()
+	cfg.Frameworks.HTMX = true
+	l := linter.New(cfg)
+	for name, content := range severityCorpus {
+		results, err := l.LintContent(name, []byte(content))
+		if err != nil {
+			t.Fatalf("LintContent(%s) error = %v", name, err)
+		}
+		for _, r := range results {
+			rule := registry.ByName(r.Rule)
+			if rule == nil {
+				continue
+			}
+			// Severity counts down from Error, the most severe
+			if def := rules.DefaultSeverity(rule); r.Severity < def {
+				t.Errorf("%s reported at %s, more severe than its default %s: %s",
+					r.Rule, r.Severity, def, r.Message)
+			}
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/toba/go-html-validate/linter"
+	"github.com/toba/go-html-validate/parser"
 	"github.com/toba/go-html-validate/rules"
 )
 
@@ -154,6 +155,19 @@ func TestLintContent_TemplateSyntaxValid(t *testing.T) {
 			name: "balanced braces - no error",
 			html: `<div>{{ .Title }}</div>`,
 		},
+		{
+			name: "closing delimiter in a string - no error",
+			html: `{{ if eq .A "}}" }}a{{ end }}`,
+		},
+		{
+			name: "delimiters in a comment - no error",
+			html: `{{/* a } } }} */}}<p>{{/* {{ if */}}</p>`,
+		},
+		{
+			name:     "unmatched closing brace after an action",
+			html:     `<p>{{ .A }} }}</p>`,
+			wantRule: rules.RuleTemplateSyntaxValid,
+		},
 
 		// Control structure tests
 		{
@@ -368,3 +382,348 @@ content
 		})
 	}
 }
+
+func TestLintContent_TemplateBranchExploration(t *testing.T) {
+	tests := []struct {
+		name        string
+		html        string
+		maxVariants int
+		wantCount   int // expected img-alt results with branch exploration
+	}{
+		{
+			name:      "missing alt in else branch",
+			html:      `{{if .Logo}}<img src="logo.png" alt="Logo">{{else}}<img src="default.png">{{end}}`,
+			wantCount: 1,
+		},
+		{
+			name: "missing alt in nested else branch",
+			html: `{{if .A}}
+  {{if .B}}<img src="b.png" alt="B">{{else}}<img src="c.png">{{end}}
+{{else}}
+  <p>none</p>
+{{end}}`,
+			wantCount: 1,
+		},
+		{
+			name:      "missing alt in range else branch",
+			html:      `<ul>{{range .Items}}<li>{{.}}</li>{{else}}<li><img src="empty.png"></li>{{end}}</ul>`,
+			wantCount: 1,
+		},
+		{
+			name: "shared markup reported once across variants",
+			html: `<img src="a.png">
+{{if .A}}<p>a</p>{{else}}<p>b</p>{{end}}
+{{if .B}}<p>c</p>{{else}}<p>d</p>{{end}}`,
+			wantCount: 1,
+		},
+		{
+			name: "every else branch covered beyond the cap",
+			html: `{{if .A}}<p>a</p>{{else}}<img src="a.png">{{end}}
+{{if .B}}<p>b</p>{{else}}<img src="b.png">{{end}}
+{{if .C}}<p>c</p>{{else}}<img src="c.png">{{end}}`,
+			maxVariants: 4,
+			wantCount:   3,
+		},
+		{
+			name:      "else branch inside define",
+			html:      `{{define "logo"}}{{with .Logo}}<img src="{{.}}" alt="">{{else}}<img src="x.png">{{end}}{{end}}`,
+			wantCount: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := linter.DefaultConfig()
+			cfg.Templates.ExploreBranches = true
+			cfg.Templates.MaxVariants = tt.maxVariants
+			results, err := linter.New(cfg).LintContent("test.html", []byte(tt.html))
+			if err != nil {
+				t.Fatalf("LintContent() error = %v", err)
+			}
+			count := 0
+			for _, r := range results {
+				if r.Rule == rules.RuleImgAlt {
+					count++
+				}
+			}
+			if count != tt.wantCount {
+				t.Errorf("got %d %s results, want %d: %v", count, rules.RuleImgAlt, tt.wantCount, results)
+			}
+		})
+	}
+}
+
+func TestLintContent_TemplateBranchExplorationDisabled(t *testing.T) {
+	html := `{{if .Logo}}<img src="logo.png" alt="Logo">{{else}}<img src="default.png">{{end}}`
+	results, err := linter.New(nil).LintContent("test.html", []byte(html))
+	if err != nil {
+		t.Fatalf("LintContent() error = %v", err)
+	}
+	checkRule(t, results, rules.RuleImgAlt, "")
+}
+
+func TestParse_TemplateActions(t *testing.T) {
+	content := `{{- if .A }}<p title="{{ printf "}}" }}">{{/* {{ }} */}}</p>{{ else with .B -}}` +
+		`{{ $x := .C }}{{ template "row" . }}{{ end }}`
+
+	doc, err := parser.ParseFragment("test.html", []byte(content))
+	if err != nil {
+		t.Fatalf("ParseFragment() error = %v", err)
+	}
+
+	want := []struct {
+		kind      parser.ActionKind
+		pipeline  string
+		name      string
+		trimLeft  bool
+		trimRight bool
+	}{
+		{kind: parser.ActionIf, pipeline: ".A", trimLeft: true},
+		{kind: parser.ActionOutput, pipeline: `printf "}}"`},
+		{kind: parser.ActionComment},
+		{kind: parser.ActionElseWith, pipeline: ".B", trimRight: true},
+		{kind: parser.ActionVariable, pipeline: "$x := .C"},
+		{kind: parser.ActionTemplate, pipeline: `"row" .`, name: "row"},
+		{kind: parser.ActionEnd},
+	}
+
+	actions := doc.TemplateActions()
+	if len(actions) != len(want) {
+		t.Fatalf("got %d actions, want %d: %+v", len(actions), len(want), actions)
+	}
+	for i, w := range want {
+		a := actions[i]
+		if a.Kind != w.kind || a.Pipeline != w.pipeline || a.Name != w.name ||
+			a.TrimLeft != w.trimLeft || a.TrimRight != w.trimRight {
+			t.Errorf("action %d = %s %q %q trim(%v,%v), want %s %q %q trim(%v,%v)", i,
+				a.Kind, a.Pipeline, a.Name, a.TrimLeft, a.TrimRight,
+				w.kind, w.pipeline, w.name, w.trimLeft, w.trimRight)
+		}
+	}
+
+	// The output action spans from "{{" through the closing "}}" after the string
+	if got := actions[1].Span; got.Start.Col != 23 || got.End.Col != 40 {
+		t.Errorf("output action span = %d-%d, want 23-40", got.Start.Col, got.End.Col)
+	}
+}
+
+func TestLintContent_CustomDelimiters(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		wantRule string
+	}{
+		{
+			name: "actions are preprocessed",
+			html: `<ul>[[ range .Items ]]<li id="[[ .ID ]]">[[ .Name ]]</li>[[ end ]]</ul>`,
+		},
+		{
+			name: "only the first branch is linted",
+			html: `<input type="text" [[ if .A ]]name="a"[[ else ]]name="b"[[ end ]]>`,
+		},
+		{
+			name: "default delimiters are plain text",
+			html: `<p>{{ .Name }} &bogus;</p>`,
+			// Outside a template action with these delimiters
+			wantRule: rules.RuleUnrecognizedCharRef,
+		},
+		{
+			name:     "unclosed control structure",
+			html:     `[[ if .A ]]<p>a</p>`,
+			wantRule: rules.RuleTemplateSyntaxValid,
+		},
+		{
+			name:     "missing trim marker",
+			html:     "<div>\n  [[ if .A ]]\n  <p>a</p>\n  [[- end -]]\n</div>",
+			wantRule: rules.RuleTemplateWhitespaceTrim,
+		},
+		{
+			name: "reference to templated id",
+			html: `<label for="[[ .ID ]]">Name</label><input type="text">`,
+		},
+		{
+			name: "templated htmx attributes",
+			html: `<div hx-get="/a" hx-swap="[[ .Swap ]]" hx-trigger="[[ .Trigger ]]" hx-target="[[ .Target ]]"` +
+				` hx-vals='[[ .Vals ]]' hx-include="[[ .Include ]]"></div>`,
+		},
+	}
+
+	cfg := linter.DefaultConfig()
+	cfg.Templates.Delims = parser.Delims{Left: "[[", Right: "]]"}
+	cfg.Frameworks.HTMX = true
+	l := linter.New(cfg)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := l.LintContent("test.html", []byte(tt.html))
+			if err != nil {
+				t.Fatalf("LintContent() error = %v", err)
+			}
+
+			found := false
+			for _, r := range results {
+				switch r.Rule {
+				case tt.wantRule:
+					found = true
+				case rules.RuleTemplateSyntaxValid, rules.RuleNoDupAttr, rules.RuleNoMissingReferences,
+					rules.RuleUnrecognizedCharRef, rules.RuleHTMXAttributes:
+					t.Errorf("unexpected %s: %s", r.Rule, r.Message)
+				}
+			}
+			if tt.wantRule != "" && !found {
+				t.Errorf("expected %s rule, got %v", tt.wantRule, results)
+			}
+		})
+	}
+}
+
+func TestLintContent_CustomDelimitersOverride(t *testing.T) {
+	cfg := linter.DefaultConfig()
+	cfg.Templates.Delims = parser.Delims{Left: "[[", Right: "]]"}
+	cfg.Templates.Overrides = []linter.TemplateOverride{
+		{Files: []string{"legacy/**"}, Delims: parser.DefaultDelims},
+	}
+	l := linter.New(cfg)
+
+	content := []byte(`{{ if .A }}<p>a</p>`)
+	for path, wantSyntaxError := range map[string]bool{
+		"views/page.html":  false,
+		"legacy/page.html": true,
+	} {
+		results, err := l.LintContent(path, content)
+		if err != nil {
+			t.Fatalf("LintContent(%s) error = %v", path, err)
+		}
+		got := false
+		for _, r := range results {
+			if r.Rule == rules.RuleTemplateSyntaxValid {
+				got = true
+			}
+		}
+		if got != wantSyntaxError {
+			t.Errorf("%s: template-syntax-valid reported = %v, want %v", path, got, wantSyntaxError)
+		}
+	}
+}
+
+func TestParse_EscapeContexts(t *testing.T) {
+	content := `<a href="{{.URL}}" title={{.Title}} onclick="go('{{.ID}}')">{{.Text}}</a>` +
+		`<!-- {{.Note}} --><script>var s = "x" + {{.S}};</script><style>p { color: {{.C}} }</style>` +
+		`<a href="/p?q={{.Q}}" {{.Attrs}}>`
+
+	doc, err := parser.ParseFragment("test.html", []byte(content))
+	if err != nil {
+		t.Fatalf("ParseFragment() error = %v", err)
+	}
+
+	want := []parser.EscapeContext{
+		{State: parser.StateURL, Element: "a", Attr: "href", Quote: '"', URLPart: parser.URLPartNone},
+		{State: parser.StateAttr, Element: "a", Attr: "title"},
+		{State: parser.StateJS, Element: "a", Attr: "onclick", Quote: '"', JSQuote: '\''},
+		{State: parser.StateText},
+		{State: parser.StateComment},
+		{State: parser.StateJS, Element: "script"},
+		{State: parser.StateCSS, Element: "style"},
+		{State: parser.StateURL, Element: "a", Attr: "href", Quote: '"', URLPart: parser.URLPartQueryOrFrag},
+		{State: parser.StateTag, Element: "a"},
+	}
+
+	actions := doc.TemplateActions()
+	if len(actions) != len(want) {
+		t.Fatalf("got %d actions, want %d", len(actions), len(want))
+	}
+	for i, w := range want {
+		if got := actions[i].Context; got != w {
+			t.Errorf("action %d (%s) context = %+v, want %+v", i, actions[i].Pipeline, got, w)
+		}
+	}
+}
+
+func TestLintContent_TemplateEscapeHazard(t *testing.T) {
+	tests := []struct {
+		name    string
+		html    string
+		wantCol int // column of the reported action; 0 for none
+	}{
+		{
+			name:    "action as whole href",
+			html:    `<a href="{{ .URL }}">Link</a>`,
+			wantCol: 10,
+		},
+		{
+			name:    "action as whole form action",
+			html:    `<form action="{{ .URL }}"></form>`,
+			wantCol: 15,
+		},
+		{
+			name:    "action as whole formaction",
+			html:    `<button formaction="{{ .URL }}">Go</button>`,
+			wantCol: 21,
+		},
+		{
+			name: "action as whole src",
+			html: `<img src="{{.URL}}" alt="">`,
+		},
+		{
+			name: "action in href path",
+			html: `<a href="/users/{{ .ID }}">Link</a>`,
+		},
+		{
+			name:    "action in event handler",
+			html:    `<button type="button" onclick="select({{ .ID }})">Go</button>`,
+			wantCol: 39,
+		},
+		{
+			name:    "action concatenated in script",
+			html:    `<script>el.innerHTML = "<b>" + {{ .Name }};</script>`,
+			wantCol: 32,
+		},
+		{
+			name: "action as script value",
+			html: `<script>const user = {{ .User }};</script>`,
+		},
+		{
+			name:    "unquoted attribute value",
+			html:    `<p class=x-{{ .Class }}>Text</p>`,
+			wantCol: 12,
+		},
+		{
+			name:    "action in attribute name position",
+			html:    `<p {{ .Attrs }}>Text</p>`,
+			wantCol: 4,
+		},
+		{
+			name: "action in text",
+			html: `<p title="{{ .Title }}">{{ .Body }}</p>`,
+		},
+		{
+			name: "conditional attribute",
+			html: `<p {{ if .Hidden }}hidden{{ end }}>Text</p>`,
+		},
+	}
+
+	l := linter.New(nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := l.LintContent("test.html", []byte(tt.html))
+			if err != nil {
+				t.Fatalf("LintContent() error = %v", err)
+			}
+			var found []rules.Result
+			for _, r := range results {
+				if r.Rule == rules.RuleTemplateEscapeHazard {
+					found = append(found, r)
+				}
+			}
+			if tt.wantCol == 0 {
+				if len(found) > 0 {
+					t.Errorf("expected no %s, got %v", rules.RuleTemplateEscapeHazard, found)
+				}
+				return
+			}
+			if len(found) != 1 || found[0].Col != tt.wantCol {
+				t.Errorf("got %v, want one %s at col %d", found, rules.RuleTemplateEscapeHazard, tt.wantCol)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package linter_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/toba/go-html-validate/linter"
+	"github.com/toba/go-html-validate/rules"
+)
+
+func TestLintFile_TemplateSet(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"partials/header.gohtml": `{{define "header"}}<header><h1 id="site-title">Site</h1><label for="q">Search</label><img src="logo.png"></header><main id="content">{{end}}`,
+		"partials/footer.gohtml": `{{define "footer"}}</main><footer>{{template "links" .}}</footer>{{end}}` +
+			`{{define "links"}}<nav id="content"></nav>{{end}}`,
+		"page.html":   `{{template "header" .}}<input id="q" type="search"><main></main>{{template "footer" .}}`,
+		"layout.html": `{{block "body" .}}<main></main>{{end}}{{block "body2" .}}<main></main>{{end}}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lint := func(set []string, name string) []rules.Result {
+		t.Helper()
+		cfg := linter.DefaultConfig()
+		cfg.Templates.Set = set
+		results, err := linter.New(cfg).LintFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("LintFile() error = %v", err)
+		}
+		return results
+	}
+	count := func(results []rules.Result, rule string) int {
+		n := 0
+		for _, r := range results {
+			if r.Rule == rule {
+				n++
+			}
+		}
+		return n
+	}
+
+	set := []string{filepath.Join(dir, "partials", "**", "*.gohtml")}
+
+	t.Run("without set", func(t *testing.T) {
+		results := lint(nil, "page.html")
+		if count(results, rules.RuleNoMultipleMain) != 0 {
+			t.Error("unexpected no-multiple-main without template set")
+		}
+		if count(results, rules.RuleNoMissingReferences) != 0 {
+			t.Error("unexpected no-missing-references without template set")
+		}
+	})
+
+	t.Run("included templates are inlined", func(t *testing.T) {
+		results := lint(set, "page.html")
+		if count(results, rules.RuleNoMultipleMain) == 0 {
+			t.Errorf("expected no-multiple-main from included header, got %v", results)
+		}
+		if count(results, rules.RuleDuplicateID) == 0 {
+			t.Errorf("expected duplicate-id across nested includes, got %v", results)
+		}
+		// The header's own markup is reported in the header, not the page
+		if n := count(results, rules.RuleImgAlt); n != 0 {
+			t.Errorf("img-alt reported %d times in page, want 0", n)
+		}
+		// The page's own <main> follows the one inlined from the header
+		for _, r := range results {
+			if r.Rule == rules.RuleNoMultipleMain && (r.Line != 1 || r.Col != 52) {
+				t.Errorf("no-multiple-main at %d:%d, want 1:52", r.Line, r.Col)
+			}
+		}
+	})
+
+	t.Run("references into included templates resolve", func(t *testing.T) {
+		cfg := linter.DefaultConfig()
+		cfg.Templates.Set = set
+		results, err := linter.New(cfg).LintContent("form.html",
+			[]byte(`{{template "header" .}}<section aria-labelledby="site-title"><input id="q"></section></main>`))
+		if err != nil {
+			t.Fatalf("LintContent() error = %v", err)
+		}
+		if n := count(results, rules.RuleNoMissingReferences); n != 0 {
+			t.Errorf("no-missing-references reported %d times, want 0: %v", n, results)
+		}
+	})
+
+	t.Run("blocks render once", func(t *testing.T) {
+		results := lint(set, "layout.html")
+		if count(results, rules.RuleNoMultipleMain) == 0 {
+			t.Errorf("expected no-multiple-main across blocks, got %v", results)
+		}
+	})
+
+	t.Run("partials are still linted on their own", func(t *testing.T) {
+		results := lint(set, "partials/header.gohtml")
+		if count(results, rules.RuleImgAlt) != 1 {
+			t.Errorf("expected img-alt in header, got %v", results)
+		}
+	})
+}
+
+func TestLintFile_TemplateSetBrokenFile(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"header.gohtml": `{{define "header"}}<main></main>{{end}}`,
+		"broken.gohtml": `{{define "broken"}}<p>{{if}}</p>{{end}}`,
+		"page.html":     `{{template "header" .}}<main></main>`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := linter.DefaultConfig()
+	cfg.Templates.Set = []string{filepath.Join(dir, "*.gohtml")}
+	l := linter.New(cfg)
+
+	// The broken partial is left out of the set instead of failing the page
+	results, err := l.LintFile(filepath.Join(dir, "page.html"))
+	if err != nil {
+		t.Fatalf("LintFile() error = %v", err)
+	}
+	if !hasRule(results, rules.RuleNoMultipleMain) {
+		t.Errorf("expected no-multiple-main from the valid header, got %v", results)
+	}
+	for _, r := range results {
+		if r.Rule == "parse-error" {
+			t.Errorf("unexpected parse error in page: %s", r.Message)
+		}
+	}
+
+	// and its own syntax error is still reported when it's linted
+	results, err = l.LintFile(filepath.Join(dir, "broken.gohtml"))
+	if err != nil {
+		t.Fatalf("LintFile() error = %v", err)
+	}
+	if !hasRule(results, rules.RuleTemplateSyntaxValid) {
+		t.Errorf("expected template-syntax-valid in the broken partial, got %v", results)
+	}
+}
+
+func TestLintFile_TemplateNames(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"layout.gohtml": `{{define "layout"}}<main>{{template "content" .}}{{template "sidebar" .}}</main>{{end}}`,
+		"page.gohtml": `{{define "content"}}<p>{{template "card" .}}</p>{{end}}` +
+			"\n" + `{{define "card"}}<div></div>{{end}}` +
+			"\n" + `{{define "orphan"}}<div></div>{{end}}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := linter.DefaultConfig()
+	cfg.Templates.Set = []string{filepath.Join(dir, "*.gohtml")}
+	cfg.Templates.EntryPoints = []string{"layout"}
+	l := linter.New(cfg)
+
+	results, err := l.LintFiles([]string{
+		filepath.Join(dir, "layout.gohtml"),
+		filepath.Join(dir, "page.gohtml"),
+	})
+	if err != nil {
+		t.Fatalf("LintFiles() error = %v", err)
+	}
+
+	var undefined, unused []rules.Result
+	for _, r := range results {
+		switch r.Rule {
+		case rules.RuleTemplateUndefined:
+			undefined = append(undefined, r)
+		case rules.RuleTemplateUnused:
+			unused = append(unused, r)
+		}
+	}
+
+	if len(undefined) != 1 || undefined[0].Message != `template "sidebar" is not defined` {
+		t.Errorf("template-undefined = %v, want one for sidebar", undefined)
+	} else if r := undefined[0]; r.Line != 1 || r.Col != 50 || r.EndCol != 74 {
+		t.Errorf("template-undefined at %d:%d-%d, want 1:50-74", r.Line, r.Col, r.EndCol)
+	}
+	if len(unused) != 1 || unused[0].Message != `template "orphan" is defined but never used` {
+		t.Errorf("template-unused = %v, want one for orphan", unused)
+	} else if r := unused[0]; r.Line != 3 || r.Col != 1 {
+		t.Errorf("template-unused at %d:%d, want 3:1", r.Line, r.Col)
+	}
+
+	// Without a template set, neither rule can tell and both stay quiet
+	results, err = linter.New(nil).LintFile(filepath.Join(dir, "page.gohtml"))
+	if err != nil {
+		t.Fatalf("LintFile() error = %v", err)
+	}
+	for _, r := range results {
+		if r.Rule == rules.RuleTemplateUndefined || r.Rule == rules.RuleTemplateUnused {
+			t.Errorf("unexpected %s without template set: %s", r.Rule, r.Message)
+		}
+	}
+}
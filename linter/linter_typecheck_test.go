@@ -0,0 +1,164 @@
+package linter_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/toba/go-html-validate/linter"
+	"github.com/toba/go-html-validate/rules"
+)
+
+func TestLintContent_TemplateTypeCheck(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		// configured lints the file under a name configured with go/ast.File
+		configured bool
+		// want lists the expected messages in order
+		want []string
+		// wantCol is the column of the first finding, when set
+		wantCol int
+	}{
+		{
+			name:    "unknown field",
+			html:    `{{/* htmlint:data net/url.URL */}}<p>{{ .Hots }}</p>`,
+			want:    []string{"can't evaluate field Hots in type url.URL"},
+			wantCol: 41,
+		},
+		{
+			name: "fields and methods through pointers",
+			html: `{{/* htmlint:data net/url.URL */}}<p>{{ .Host }} {{ .User.Username }} {{ .Query.Get "q" }}</p>`,
+		},
+		{
+			name:    "unknown field on nested type",
+			html:    `{{/* htmlint:data net/url.URL */}}<p>{{ .User.Name }}</p>`,
+			want:    []string{"can't evaluate field Name in type *url.Userinfo"},
+			wantCol: 46,
+		},
+		{
+			name:       "range and with scopes",
+			configured: true,
+			html: `{{ range .Imports }}<p>{{ .Path.Value }} {{ .Nmae }}</p>{{ end }}` +
+				`{{ with .Name }}<p>{{ .Name }} {{ .Value }}</p>{{ else }}<p>{{ .Package }}</p>{{ end }}`,
+			want: []string{
+				"can't evaluate field Nmae in type *ast.ImportSpec",
+				"can't evaluate field Value in type *ast.Ident",
+			},
+		},
+		{
+			name:       "range variables",
+			configured: true,
+			html:       `{{ range $i, $imp := .Imports }}<p>{{ $i }} {{ $imp.Path.Value }} {{ $imp.Pth }} {{ $.Name }}</p>{{ end }}`,
+			want:       []string{"can't evaluate field Pth in type *ast.ImportSpec"},
+		},
+		{
+			name:       "unexported field",
+			configured: true,
+			html:       `<p>{{ .comments }}</p>`,
+			want:       []string{"comments is an unexported field of struct type ast.File"},
+		},
+		{
+			name:       "interfaces and function results are not checked",
+			configured: true,
+			html:       `{{ range .Decls }}<p>{{ .Anything }}</p>{{ end }}<p>{{ (index .Imports 0).Whatever }}</p>`,
+		},
+		{
+			name:       "defines called with a field",
+			configured: true,
+			html:       `{{ template "ident" .Name }}{{ define "ident" }}<p>{{ .Name }} {{ .Bogus }}</p>{{ end }}`,
+			want:       []string{"can't evaluate field Bogus in type *ast.Ident"},
+		},
+		{
+			name: "no data type",
+			html: `<p>{{ .Anything.At.All }}</p>`,
+		},
+		{
+			name: "unknown type",
+			html: `{{/* htmlint:data net/url.Nope */}}<p>{{ .Host }}</p>`,
+			want: []string{"can't load template data type net/url.Nope: no type Nope in package net/url"},
+		},
+	}
+
+	cfg := linter.DefaultConfig()
+	cfg.Templates.Overrides = []linter.TemplateOverride{
+		{Files: []string{"ast/*.html"}, Data: "go/ast.File"},
+	}
+	l := linter.New(cfg)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := "test.html"
+			if tt.configured {
+				filename = "ast/test.html"
+			}
+			results, err := l.LintContent(filename, []byte(tt.html))
+			if err != nil {
+				t.Fatalf("LintContent() error = %v", err)
+			}
+
+			var got []rules.Result
+			for _, r := range results {
+				if r.Rule == rules.RuleTemplateTypeCheck {
+					got = append(got, r)
+				}
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d findings, want %d: %v", len(got), len(tt.want), got)
+			}
+			for i, r := range got {
+				if r.Message != tt.want[i] {
+					t.Errorf("finding %d = %q, want %q", i, r.Message, tt.want[i])
+				}
+			}
+			if tt.wantCol > 0 && got[0].Col != tt.wantCol {
+				t.Errorf("first finding at column %d, want %d", got[0].Col, tt.wantCol)
+			}
+		})
+	}
+}
+
+func TestLintFile_TemplateTypeCheckReloads(t *testing.T) {
+	// Packages resolve through the go command, which runs in the working
+	// directory
+	dir := t.TempDir()
+	t.Chdir(dir)
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("go.mod", "module example.com/site\n\ngo 1.24\n")
+	write("view/view.go", "package view\n\ntype Page struct{ Title string }\n")
+	write("page.html", `{{/* htmlint:data example.com/site/view.Page */}}<p>{{ .Heading }}</p>`)
+
+	l := linter.New(nil)
+	lint := func() []string {
+		t.Helper()
+		results, err := l.LintFiles([]string{filepath.Join(dir, "page.html")})
+		if err != nil {
+			t.Fatalf("LintFiles() error = %v", err)
+		}
+		var messages []string
+		for _, r := range results {
+			if r.Rule == rules.RuleTemplateTypeCheck {
+				messages = append(messages, r.Message)
+			}
+		}
+		return messages
+	}
+
+	if got := lint(); len(got) != 1 || got[0] != "can't evaluate field Heading in type view.Page" {
+		t.Fatalf("before the edit got %q, want the missing Heading field", got)
+	}
+	// The next run sees the field added since
+	write("view/view.go", "package view\n\ntype Page struct{ Title, Heading string }\n")
+	if got := lint(); len(got) != 0 {
+		t.Errorf("after the edit got %q, want none", got)
+	}
+}
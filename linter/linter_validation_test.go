@@ -376,6 +376,19 @@ func TestLintContent_AllowedLinks(t *testing.T) {
 			name: "template expression (skip)",
 			html: `<a href="TMPL">Link</a>`,
 		},
+		{
+			name: "template action (skip)",
+			html: `<a href="{{ .URL }}">Link</a>`,
+		},
+		{
+			name: "template action after path",
+			html: `<a href="/users/{{ .ID }}">Link</a>`,
+		},
+		{
+			name:     "javascript protocol before template action",
+			html:     `<a href="javascript:{{ .Code }}">Link</a>`,
+			wantRule: rules.RuleAllowedLinks,
+		},
 	}
 
 	l := linter.New(nil)
@@ -417,6 +430,15 @@ func TestLintContent_LongTitle(t *testing.T) {
 			checkRule(t, results, rules.RuleLongTitle, tt.wantRule)
 		})
 	}
+
+	// The description follows a configured maximum
+	rule := &rules.LongTitle{}
+	if err := rules.ConfigureOptions(rule, map[string]any{"maxlength": 50.0}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "title element should not exceed 50 characters for SEO"; rule.Description() != want {
+		t.Errorf("Description() = %q, want %q", rule.Description(), want)
+	}
 }
 
 func TestLintContent_NoInlineStyle(t *testing.T) {
@@ -702,3 +724,94 @@ func TestLintContent_UnrecognizedCharRef(t *testing.T) {
 		})
 	}
 }
+
+func TestLintContent_RuleOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     string
+		options  map[string]any
+		html     string
+		wantRule string
+	}{
+		{
+			name:    "class-pattern bem",
+			rule:    rules.RuleClassPattern,
+			options: map[string]any{"pattern": "bem"},
+			html:    `<div class="card__title--large">Text</div>`,
+		},
+		{
+			name:     "class-pattern named convention",
+			rule:     rules.RuleClassPattern,
+			options:  map[string]any{"pattern": "camelcase"},
+			html:     `<div class="card-title">Text</div>`,
+			wantRule: rules.RuleClassPattern,
+		},
+		{
+			name:     "id-pattern regex",
+			rule:     rules.RuleIDPattern,
+			options:  map[string]any{"pattern": "^js-"},
+			html:     `<div id="main">Text</div>`,
+			wantRule: rules.RuleIDPattern,
+		},
+		{
+			name:    "name-pattern regex",
+			rule:    rules.RuleNamePattern,
+			options: map[string]any{"pattern": "^[a-z.]+$"},
+			html:    `<input type="text" name="user.email" aria-label="Email">`,
+		},
+		{
+			name:     "long-title maxlength",
+			rule:     rules.RuleLongTitle,
+			options:  map[string]any{"maxlength": 10.0},
+			html:     `<title>A page title</title>`,
+			wantRule: rules.RuleLongTitle,
+		},
+		{
+			name:     "allowed-links protocols",
+			rule:     rules.RuleAllowedLinks,
+			options:  map[string]any{"protocols": []any{"https", "mailto"}},
+			html:     `<a href="ftp://example.com/file">File</a>`,
+			wantRule: rules.RuleAllowedLinks,
+		},
+		{
+			name:    "allowed-links protocols allow relative links",
+			rule:    rules.RuleAllowedLinks,
+			options: map[string]any{"protocols": []any{"https"}},
+			html:    `<a href="/page">Page</a><a href="https://example.com">Site</a>`,
+		},
+		{
+			name:    "allowed-links protocols can allow data",
+			rule:    rules.RuleAllowedLinks,
+			options: map[string]any{"protocols": []any{"data:"}},
+			html:    `<a href="data:text/plain,hi">Data</a>`,
+		},
+		{
+			name:    "no-missing-references ignore",
+			rule:    rules.RuleNoMissingReferences,
+			options: map[string]any{"ignore": []any{"toast-*"}},
+			html:    `<button type="button" aria-controls="toast-1">Show</button>`,
+		},
+		{
+			name:     "no-missing-references ignore only matches",
+			rule:     rules.RuleNoMissingReferences,
+			options:  map[string]any{"ignore": []any{"toast-*"}},
+			html:     `<button type="button" aria-controls="dialog">Show</button>`,
+			wantRule: rules.RuleNoMissingReferences,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := linter.DefaultConfig()
+			cfg.RuleOptions[tt.rule] = tt.options
+			if err := cfg.Validate(); err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			results, err := linter.New(cfg).LintContent("test.html", []byte(tt.html))
+			if err != nil {
+				t.Fatalf("LintContent() error = %v", err)
+			}
+			checkRule(t, results, tt.rule, tt.wantRule)
+		})
+	}
+}
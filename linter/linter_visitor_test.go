@@ -0,0 +1,142 @@
+package linter_test
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/toba/go-html-validate/parser"
+	"github.com/toba/go-html-validate/rules"
+)
+
+// eventRecorder is a visitor rule that records the events it receives.
+type eventRecorder struct {
+	tags   []string
+	events *[]string
+}
+
+func (r *eventRecorder) Name() string        { return "event-recorder" }
+func (r *eventRecorder) Description() string { return "records visitor events" }
+
+func (r *eventRecorder) Check(doc *parser.Document) []rules.Result {
+	return rules.Dispatch(doc, []rules.VisitorRule{r})[0]
+}
+
+func (r *eventRecorder) Visit(v *rules.Visitor) {
+	v.OnEnter(func(n *parser.Node) { *r.events = append(*r.events, "+"+n.Data) }, r.tags...)
+	v.OnLeave(func(n *parser.Node) { *r.events = append(*r.events, "-"+n.Data) }, r.tags...)
+	v.OnDone(func() {
+		*r.events = append(*r.events, "done")
+		v.Report(rules.Result{Rule: r.Name(), Message: strings.Join(*r.events, " ")})
+	})
+}
+
+func TestDispatch_Events(t *testing.T) {
+	doc, err := parser.ParseFragment("test.html", []byte(`<ul><li>A <b>B</b></li><li>C</li></ul><p>D</p>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var all, filtered []string
+	results := rules.Dispatch(doc, []rules.VisitorRule{
+		&eventRecorder{events: &all},
+		&eventRecorder{tags: []string{"li", "p"}, events: &filtered},
+	})
+
+	wantAll := []string{"+ul", "+li", "+b", "-b", "-li", "+li", "-li", "-ul", "+p", "-p", "done"}
+	if !slices.Equal(all, wantAll) {
+		t.Errorf("all elements: got %v, want %v", all, wantAll)
+	}
+	wantFiltered := []string{"+li", "-li", "+li", "-li", "+p", "-p", "done"}
+	if !slices.Equal(filtered, wantFiltered) {
+		t.Errorf("filtered: got %v, want %v", filtered, wantFiltered)
+	}
+	if len(results) != 2 || len(results[0]) != 1 || len(results[1]) != 1 {
+		t.Fatalf("expected one result per rule, got %v", results)
+	}
+	if results[1][0].Message != strings.Join(wantFiltered, " ") {
+		t.Errorf("results out of rule order: %v", results)
+	}
+}
+
+func TestIndex(t *testing.T) {
+	html := `<header>Site</header>
+<nav aria-label="Main">Menu</nav>
+<form id="search" aria-label="Search"><label for="q">Query</label><input id="q"></form>
+<form><label for="q">Again</label></form>
+<section>Unnamed</section>
+<div role="button" id="q">Explicit role</div>
+<nav role="presentation">No role</nav>`
+	doc, err := parser.ParseFragment("test.html", []byte(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx := rules.NewIndex(doc)
+
+	if got := len(idx.IDs["q"]); got != 2 {
+		t.Errorf("IDs[q] has %d elements, want 2", got)
+	}
+	if n := idx.ByID("q"); n == nil || n.Data != "input" {
+		t.Errorf("ByID(q) = %v, want the input", n)
+	}
+	if idx.HasID("missing") {
+		t.Error("HasID(missing) = true")
+	}
+	if len(idx.Labels) != 2 || len(idx.LabelsFor["q"]) != 2 {
+		t.Errorf("got %d labels, %d for q; want 2 and 2", len(idx.Labels), len(idx.LabelsFor["q"]))
+	}
+	if len(idx.Forms) != 2 {
+		t.Errorf("got %d forms, want 2", len(idx.Forms))
+	}
+
+	var landmarks []string
+	for _, l := range idx.Landmarks {
+		landmarks = append(landmarks, l.Role+":"+l.Name)
+	}
+	// Like unique-landmark always has, any explicit role counts
+	want := []string{"banner:", "navigation:Main", "form:Search", "button:"}
+	if !slices.Equal(landmarks, want) {
+		t.Errorf("landmarks: got %v, want %v", landmarks, want)
+	}
+}
+
+func TestDispatch_MatchesCheck(t *testing.T) {
+	// Walking once for every visitor rule finds exactly what each rule's
+	// own Check does
+	html := `<!DOCTYPE html><html><head><title></title></head><body>
+<main><h1>Title</h1><h3>Skipped</h3>
+<nav>One</nav><nav>Two</nav>
+<div aria-hidden="true"><a href="/x">Hidden link</a><button>Go</button></div>
+<form><label for="a">A</label><input id="a"><input id="a" name="n"><input name="n"></form>
+<a href="javascript:void(0)"><svg></svg></a>
+<img src="a.png" class="BadClass" style="color: red" tabindex="2">
+<label for="missing">Missing</label><span aria-labelledby="nowhere">X</span>
+</main><main></main></body></html>`
+	doc, err := parser.Parse("test.html", []byte(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var visitors []rules.VisitorRule
+	for _, r := range rules.NewRegistry().All() {
+		if v, ok := r.(rules.VisitorRule); ok {
+			visitors = append(visitors, v)
+		}
+	}
+	if len(visitors) < 50 {
+		t.Fatalf("only %d visitor rules registered", len(visitors))
+	}
+
+	found := 0
+	for i, results := range rules.Dispatch(doc, visitors) {
+		want := visitors[i].Check(doc)
+		if !reflect.DeepEqual(results, want) {
+			t.Errorf("%s: Dispatch found %v, Check found %v", visitors[i].Name(), results, want)
+		}
+		found += len(results)
+	}
+	if found == 0 {
+		t.Error("expected results")
+	}
+}
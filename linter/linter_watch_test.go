@@ -0,0 +1,149 @@
+package linter_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/toba/go-html-validate/config"
+	"github.com/toba/go-html-validate/linter"
+	"github.com/toba/go-html-validate/rules"
+)
+
+// deltaRecorder is a reporter that passes each report on a channel.
+type deltaRecorder struct {
+	reports chan delta
+}
+
+type delta struct {
+	added, removed, all []rules.Result
+}
+
+func (r *deltaRecorder) Report(results []rules.Result) error {
+	r.reports <- delta{all: results}
+	return nil
+}
+
+func (r *deltaRecorder) ReportDelta(added, removed, all []rules.Result) error {
+	r.reports <- delta{added: added, removed: removed, all: all}
+	return nil
+}
+
+func TestWatcher(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		// Replace the file whole, so a poll never sees it half written
+		if err := os.WriteFile(path+".tmp", []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(path+".tmp", path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	const quiet = `"unique-landmark": "off", "element-required-attributes": "off", "template-whitespace-trim": "off"`
+	write(config.ConfigFileName, `{"templates": {"set": "partials/*.gohtml"}, "rules": {`+quiet+`}}`)
+	write("partials/header.gohtml", `{{define "header"}}<main id="top"></main>{{end}}`)
+	write("page.html", `{{template "header" .}}<main></main>`)
+	write("other.html", `<p>ok</p>`)
+
+	rec := &deltaRecorder{reports: make(chan delta, 10)}
+	w := &linter.Watcher{
+		Paths:    []string{dir},
+		Interval: 10 * time.Millisecond,
+		Load: func() (*linter.Linter, []string, error) {
+			fileCfg, path, err := config.Resolve(dir)
+			if err != nil {
+				return nil, nil, err
+			}
+			l := linter.New(config.ToLinterConfig(fileCfg, path))
+			l.SetReporter(rec)
+			return l, []string{filepath.Join(dir, config.ConfigFileName)}, nil
+		},
+		Log: &testWriter{t},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- w.Run(ctx) }()
+	defer func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Run() error = %v", err)
+		}
+	}()
+
+	next := func() delta {
+		t.Helper()
+		select {
+		case d := <-rec.reports:
+			return d
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for a report")
+			return delta{}
+		}
+	}
+	rulesOf := func(results []rules.Result) []string {
+		var names []string
+		for _, r := range results {
+			names = append(names, filepath.Base(r.Filename)+":"+r.Rule)
+		}
+		slices.Sort(names)
+		return names
+	}
+
+	if got := rulesOf(next().all); !slices.Contains(got, "page.html:"+rules.RuleNoMultipleMain) {
+		t.Fatalf("initial results %v, want no-multiple-main in page.html", got)
+	}
+
+	// Changing the partial re-lints the page that includes it
+	write("partials/header.gohtml", `{{define "header"}}<header id="top"></header>{{end}}`)
+	d := next()
+	if got := rulesOf(d.removed); !slices.Equal(got, []string{"page.html:" + rules.RuleNoMultipleMain}) {
+		t.Errorf("removed %v after editing the partial", got)
+	}
+	if len(d.added) != 0 {
+		t.Errorf("added %v after editing the partial", rulesOf(d.added))
+	}
+
+	// Only new findings are reported; those that just moved are not
+	write("page.html", `{{template "header" .}}`+"\n\n"+`<main></main><img src="a.png">`)
+	d = next()
+	if got := rulesOf(d.added); !slices.Equal(got, []string{"page.html:" + rules.RuleImgAlt}) {
+		t.Errorf("added %v after editing the page", got)
+	}
+	if len(d.removed) != 0 {
+		t.Errorf("removed %v after editing the page", rulesOf(d.removed))
+	}
+
+	// A config change applies to every file
+	write(config.ConfigFileName, `{"templates": {"set": "partials/*.gohtml"}, "rules": {`+quiet+`, "img-alt": "off"}}`)
+	d = next()
+	if got := rulesOf(d.removed); !slices.Equal(got, []string{"page.html:" + rules.RuleImgAlt}) {
+		t.Errorf("removed %v after editing the config", got)
+	}
+
+	// New files are linted
+	write("new.html", `<img src="b.png"><button>Go</button>`)
+	d = next()
+	if got := rulesOf(d.added); !slices.Equal(got, []string{"new.html:" + rules.RuleButtonType}) {
+		t.Errorf("added %v after creating a file", got)
+	}
+}
+
+// testWriter logs what's written to it.
+type testWriter struct {
+	t *testing.T
+}
+
+func (w *testWriter) Write(p []byte) (int, error) {
+	w.t.Log(strings.TrimSuffix(string(p), "\n"))
+	return len(p), nil
+}
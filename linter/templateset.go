@@ -0,0 +1,78 @@
+package linter
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/toba/go-html-validate/parser"
+)
+
+// templateSet returns the template set described by config.Templates.Set,
+// loading it on first use. Returns nil if no set is configured.
+func (l *Linter) templateSet() (*parser.TemplateSet, error) {
+	if len(l.config.Templates.Set) == 0 {
+		return nil, nil
+	}
+	l.templatesOnce.Do(func() {
+		l.templates, l.templatesErr = l.loadTemplateSet()
+	})
+	return l.templates, l.templatesErr
+}
+
+func (l *Linter) loadTemplateSet() (*parser.TemplateSet, error) {
+	paths, err := l.templateSetFiles()
+	if err != nil {
+		return nil, err
+	}
+	set := parser.NewTemplateSet()
+	for _, path := range paths {
+		// A file that can't be read or parsed is left out rather than
+		// failing every file that uses the set; linting it reports why
+		content, err := os.ReadFile(path) //nolint:gosec // paths come from the user's config
+		if err != nil {
+			continue
+		}
+		_ = set.Add(path, content, l.config.Templates.DelimsFor(path))
+	}
+	return set, nil
+}
+
+// templateSetFiles returns the files matching config.Templates.Set, in the
+// order of the patterns.
+func (l *Linter) templateSetFiles() ([]string, error) {
+	var files []string
+	for _, pattern := range l.config.Templates.Set {
+		paths, err := expandGlob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, paths...)
+	}
+	return files, nil
+}
+
+// expandGlob returns the files matching pattern, in lexical order. Unlike
+// filepath.Glob it supports ** for any number of directories.
+func expandGlob(pattern string) ([]string, error) {
+	if !strings.Contains(pattern, "**") {
+		return filepath.Glob(pattern)
+	}
+
+	root := strings.TrimSuffix(strings.SplitN(pattern, "**", 2)[0], "/")
+	if root == "" {
+		root = "."
+	}
+	var paths []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && matchDoublestar(path, pattern) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	return paths, err
+}
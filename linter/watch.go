@@ -0,0 +1,406 @@
+package linter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/toba/go-html-validate/parser"
+	"github.com/toba/go-html-validate/rules"
+)
+
+// DefaultWatchInterval is how often a Watcher polls files when no interval
+// is given.
+const DefaultWatchInterval = 500 * time.Millisecond
+
+// DeltaReporter is implemented by reporters that can show what changed
+// between two runs. A Watcher gives reporters without it every result after
+// each change.
+type DeltaReporter interface {
+	Reporter
+	// ReportDelta reports the results that appeared and the results that
+	// went away, given all current results.
+	ReportDelta(added, removed, all []rules.Result) error
+}
+
+// Watcher lints files, then polls them for changes, linting again the files
+// a change affects and reporting the difference. Polling needs nothing
+// platform-specific, and editors that replace files on save are handled
+// like any other change.
+type Watcher struct {
+	// Paths are the files and directories to lint, as for Run
+	Paths []string
+	// Interval is how often files are polled; DefaultWatchInterval when zero
+	Interval time.Duration
+	// Load builds the linter, with its reporters set, and lists the config
+	// and ignore files it was built from. It's called again whenever one of
+	// those files changes; files that don't exist yet are watched for
+	// creation.
+	Load func() (*Linter, []string, error)
+	// Log receives status messages and errors that don't stop watching;
+	// os.Stderr when nil
+	Log io.Writer
+
+	linter *Linter
+	// configs and files hold the last seen state of the config files and of
+	// the linted and template set files
+	configs map[string]fileState
+	files   map[string]fileState
+	// targets are the files being linted, in the order of Paths
+	targets []string
+	results map[string][]rules.Result
+	refs    map[string]templateRefs
+}
+
+// fileState is what polling compares to notice a change.
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// templateRefs are the templates a file defines and calls.
+type templateRefs struct {
+	defines, calls []string
+}
+
+// Run lints and reports every file, then watches for changes until ctx is
+// done.
+func (w *Watcher) Run(ctx context.Context) error {
+	if w.Log == nil {
+		w.Log = os.Stderr
+	}
+	w.results = make(map[string][]rules.Result)
+	w.refs = make(map[string]templateRefs)
+	if err := w.load(); err != nil {
+		return err
+	}
+
+	targets, files, err := w.scan()
+	if err != nil {
+		return err
+	}
+	w.targets, w.files = targets, files
+	// Read every file's template references now, so what a changed file
+	// defined before the change is known
+	for path := range files {
+		w.templateRefs(path)
+	}
+	if err := w.lint(targets); err != nil {
+		return err
+	}
+	if _, err := w.linter.report(w.targets, w.all()); err != nil {
+		return err
+	}
+	fmt.Fprintf(w.Log, "Watching %d file(s) for changes\n", len(targets))
+
+	interval := w.Interval
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := w.poll(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// load builds the linter and records the state of its config files.
+func (w *Watcher) load() error {
+	l, configs, err := w.Load()
+	if err != nil {
+		return err
+	}
+	w.linter = l
+	w.configs = statFiles(configs)
+	return nil
+}
+
+// poll lints the files affected by changes since the last poll and reports
+// the difference.
+func (w *Watcher) poll() error {
+	reloaded := false
+	if !maps.Equal(w.configs, statFiles(slices.Collect(maps.Keys(w.configs)))) {
+		// Keep the last good linter while a config file is being edited
+		if err := w.load(); err != nil {
+			fmt.Fprintf(w.Log, "error: %v\n", err)
+			return nil
+		}
+		reloaded = true
+	}
+
+	targets, files, err := w.scan()
+	if err != nil {
+		fmt.Fprintf(w.Log, "error: %v\n", err)
+		return nil
+	}
+	var changed []string
+	for path, state := range files {
+		if old, ok := w.files[path]; !ok || old != state {
+			changed = append(changed, path)
+		}
+	}
+	for path := range w.files {
+		if _, ok := files[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	if !reloaded && len(changed) == 0 {
+		return nil
+	}
+	w.files = files
+	if reloaded {
+		// Delimiters may have changed
+		clear(w.refs)
+		for path := range files {
+			w.templateRefs(path)
+		}
+	}
+
+	setFiles, err := w.linter.templateSetFiles()
+	if err != nil {
+		fmt.Fprintf(w.Log, "error: %v\n", err)
+		return nil
+	}
+	affected := targets
+	if !reloaded {
+		affected = w.dependents(changed, targets)
+		if slices.ContainsFunc(changed, func(path string) bool { return slices.Contains(setFiles, path) }) {
+			// The linter holds the template set as it was loaded
+			if err := w.load(); err != nil {
+				fmt.Fprintf(w.Log, "error: %v\n", err)
+				return nil
+			}
+		}
+	}
+
+	before := w.all()
+	for path := range w.results {
+		if !slices.Contains(targets, path) {
+			delete(w.results, path)
+		}
+	}
+	w.targets = targets
+	if err := w.lint(affected); err != nil {
+		return err
+	}
+	return w.report(before, w.all())
+}
+
+// scan lists the files to lint and their state, along with the state of the
+// template set files.
+func (w *Watcher) scan() ([]string, map[string]fileState, error) {
+	targets, err := collectFiles(w.Paths)
+	if err != nil {
+		return nil, nil, err
+	}
+	targets = slices.DeleteFunc(targets, w.linter.shouldIgnore)
+	setFiles, err := w.linter.templateSetFiles()
+	if err != nil {
+		return nil, nil, err
+	}
+	return targets, statFiles(append(slices.Clone(targets), setFiles...)), nil
+}
+
+// dependents returns the targets affected by changes to the changed files:
+// the changed files themselves, files calling a template they define,
+// directly or through other templates, and files defining a template they
+// call. Templates defined or called before and after the change both count.
+func (w *Watcher) dependents(changed, targets []string) []string {
+	affected := make(map[string]bool)
+	defined := make(map[string]bool)
+	called := make(map[string]bool)
+	for _, path := range changed {
+		affected[path] = true
+		refs := w.refs[path]
+		delete(w.refs, path)
+		for _, r := range []templateRefs{refs, w.templateRefs(path)} {
+			for _, name := range r.defines {
+				defined[name] = true
+			}
+			for _, name := range r.calls {
+				called[name] = true
+			}
+		}
+	}
+
+	files := slices.Concat(targets, changed)
+	for grew := true; grew; {
+		grew = false
+		for _, path := range files {
+			if affected[path] {
+				continue
+			}
+			refs := w.templateRefs(path)
+			if slices.ContainsFunc(refs.calls, func(name string) bool { return defined[name] }) {
+				affected[path] = true
+				grew = true
+				for _, name := range refs.defines {
+					defined[name] = true
+				}
+			}
+		}
+	}
+	for _, path := range files {
+		if slices.ContainsFunc(w.templateRefs(path).defines, func(name string) bool { return called[name] }) {
+			affected[path] = true
+		}
+	}
+
+	return slices.DeleteFunc(slices.Clone(targets), func(path string) bool { return !affected[path] })
+}
+
+// templateRefs returns the templates the file at path defines and calls,
+// reading the file the first time.
+func (w *Watcher) templateRefs(path string) templateRefs {
+	if refs, ok := w.refs[path]; ok {
+		return refs
+	}
+	var refs templateRefs
+	content, err := os.ReadFile(path) //nolint:gosec // user-specified file path is intentional
+	if err == nil {
+		for _, a := range parser.ScanActions(content, w.linter.config.Templates.DelimsFor(path)) {
+			switch a.Kind {
+			case parser.ActionDefine:
+				refs.defines = append(refs.defines, a.Name)
+			case parser.ActionBlock:
+				refs.defines = append(refs.defines, a.Name)
+				refs.calls = append(refs.calls, a.Name)
+			case parser.ActionTemplate:
+				refs.calls = append(refs.calls, a.Name)
+			}
+		}
+	}
+	w.refs[path] = refs
+	return refs
+}
+
+// lint lints paths and records their results.
+func (w *Watcher) lint(paths []string) error {
+	results, err := w.linter.LintFiles(paths)
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		w.results[path] = nil
+	}
+	for _, r := range results {
+		w.results[r.Filename] = append(w.results[r.Filename], r)
+	}
+	return nil
+}
+
+// all returns the current results, grouped by file in the order of targets.
+func (w *Watcher) all() []rules.Result {
+	var all []rules.Result
+	for _, path := range w.targets {
+		all = append(all, w.results[path]...)
+	}
+	return all
+}
+
+// report reports the difference between two sets of results.
+func (w *Watcher) report(before, after []rules.Result) error {
+	added, removed := diffResults(before, after)
+	if len(added) == 0 && len(removed) == 0 {
+		return nil
+	}
+	var errs []error
+	for _, r := range w.linter.reporters {
+		if dr, ok := r.(DeltaReporter); ok {
+			errs = append(errs, dr.ReportDelta(added, removed, after))
+		} else {
+			errs = append(errs, w.linter.reportTo(r, w.targets, after))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// diffResults returns the results in after that aren't in before, and the
+// results in before that aren't in after. A result that only moved, keeping
+// its file, rule and message, is in neither: editing one part of a file
+// shifts the lines of everything after it.
+func diffResults(before, after []rules.Result) (added, removed []rules.Result) {
+	type exact struct {
+		filename, rule, message string
+		line, col               int
+	}
+	type moved struct {
+		filename, rule, message string
+	}
+
+	// Pair up results at the same position first
+	remaining := make(map[exact]int)
+	for _, r := range before {
+		remaining[exact{r.Filename, r.Rule, r.Message, r.Line, r.Col}]++
+	}
+	var newAfter []rules.Result
+	for _, r := range after {
+		k := exact{r.Filename, r.Rule, r.Message, r.Line, r.Col}
+		if remaining[k] > 0 {
+			remaining[k]--
+			continue
+		}
+		newAfter = append(newAfter, r)
+	}
+	var goneBefore []rules.Result
+	for _, r := range before {
+		k := exact{r.Filename, r.Rule, r.Message, r.Line, r.Col}
+		if remaining[k] > 0 {
+			remaining[k]--
+			goneBefore = append(goneBefore, r)
+		}
+	}
+
+	// Then treat the rest as moved where the counts allow
+	beforeCounts := make(map[moved]int)
+	for _, r := range goneBefore {
+		beforeCounts[moved{r.Filename, r.Rule, r.Message}]++
+	}
+	afterCounts := make(map[moved]int)
+	for _, r := range newAfter {
+		afterCounts[moved{r.Filename, r.Rule, r.Message}]++
+	}
+	for _, r := range newAfter {
+		k := moved{r.Filename, r.Rule, r.Message}
+		if beforeCounts[k] > 0 {
+			beforeCounts[k]--
+			continue
+		}
+		added = append(added, r)
+	}
+	for _, r := range goneBefore {
+		k := moved{r.Filename, r.Rule, r.Message}
+		if afterCounts[k] > 0 {
+			afterCounts[k]--
+			continue
+		}
+		removed = append(removed, r)
+	}
+	return added, removed
+}
+
+// statFiles returns the state of each file that exists among paths, with
+// missing files at the zero state so their creation is noticed.
+func statFiles(paths []string) map[string]fileState {
+	states := make(map[string]fileState, len(paths))
+	for _, path := range paths {
+		var state fileState
+		if info, err := os.Stat(path); err == nil {
+			state = fileState{modTime: info.ModTime(), size: info.Size()}
+		}
+		states[path] = state
+	}
+	return states
+}
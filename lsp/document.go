@@ -0,0 +1,126 @@
+package lsp
+
+import (
+	"sort"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/toba/go-html-validate/linter"
+	"github.com/toba/go-html-validate/rules"
+)
+
+// document is an open text document and its latest results.
+type document struct {
+	// path is the file path the linter sees, relative to the workspace
+	// folder when the document is in one
+	path    string
+	content []byte
+	// lines holds the byte offset at which each line starts
+	lines   []int
+	linter  *linter.Linter
+	results []rules.Result
+}
+
+// setContent replaces the document's text.
+func (d *document) setContent(text string) {
+	d.content = []byte(text)
+	d.lines = []int{0}
+	for i, c := range d.content {
+		if c == '\n' {
+			d.lines = append(d.lines, i+1)
+		}
+	}
+}
+
+// lineColOffset converts a 1-based line and byte column, as results use,
+// to a byte offset, clamped to the document.
+func (d *document) lineColOffset(line, col int) int {
+	line = min(max(line, 1), len(d.lines))
+	end := len(d.content)
+	if line < len(d.lines) {
+		end = d.lines[line] - 1
+	}
+	return min(d.lines[line-1]+max(col, 1)-1, end)
+}
+
+// position converts a byte offset to an LSP position, which counts
+// characters in UTF-16 code units.
+func (d *document) position(offset int) Position {
+	offset = min(max(offset, 0), len(d.content))
+	line := sort.Search(len(d.lines), func(i int) bool { return d.lines[i] > offset }) - 1
+	character := 0
+	for _, r := range string(d.content[d.lines[line]:offset]) {
+		character += utf16.RuneLen(r)
+	}
+	return Position{Line: line, Character: character}
+}
+
+// offset converts an LSP position to a byte offset, clamped to the line.
+func (d *document) offset(p Position) int {
+	if p.Line >= len(d.lines) {
+		return len(d.content)
+	}
+	offset := d.lines[max(p.Line, 0)]
+	for units := 0; units < p.Character && offset < len(d.content); {
+		r, size := utf8.DecodeRune(d.content[offset:])
+		if r == '\n' {
+			break
+		}
+		units += utf16.RuneLen(r)
+		offset += size
+	}
+	return offset
+}
+
+// span returns the byte offsets a result covers. Results without an end
+// cover the tag, attribute or action starting at their position.
+func (d *document) span(r rules.Result) (start, end int) {
+	start = d.lineColOffset(r.Line, r.Col)
+	if r.EndLine > 0 {
+		return start, max(d.lineColOffset(r.EndLine, r.EndCol), start)
+	}
+	end = start
+	for end < len(d.content) {
+		c := d.content[end]
+		if c == ' ' || c == '\t' || c == '\r' || c == '\n' {
+			break
+		}
+		end++
+		if c == '>' {
+			break
+		}
+	}
+	return start, end
+}
+
+// rangeOf returns the range between two byte offsets.
+func (d *document) rangeOf(start, end int) Range {
+	return Range{Start: d.position(start), End: d.position(end)}
+}
+
+// diagnostic converts a result to a diagnostic.
+func (d *document) diagnostic(r rules.Result) Diagnostic {
+	severity := severityInformation
+	switch r.Severity {
+	case rules.Error:
+		severity = severityError
+	case rules.Warning:
+		severity = severityWarning
+	}
+	return Diagnostic{
+		Range:    d.rangeOf(d.span(r)),
+		Severity: severity,
+		Code:     r.Rule,
+		Source:   "htmlint",
+		Message:  r.Message,
+	}
+}
+
+// textEdits converts a result's edits to LSP text edits.
+func (d *document) textEdits(r rules.Result) []TextEdit {
+	edits := make([]TextEdit, 0, len(r.Edits))
+	for _, e := range r.Edits {
+		edits = append(edits, TextEdit{Range: d.rangeOf(e.Start, e.End), NewText: e.Text})
+	}
+	return edits
+}
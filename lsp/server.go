@@ -0,0 +1,449 @@
+// Package lsp implements a Language Server Protocol server that lints
+// documents as they are edited.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/toba/go-html-validate/config"
+	"github.com/toba/go-html-validate/linter"
+	"github.com/toba/go-html-validate/rules"
+)
+
+// showMessage types.
+const (
+	messageError   = 1
+	messageWarning = 2
+)
+
+// errExitWithoutShutdown is returned by Run when the client exits without
+// asking the server to shut down first.
+var errExitWithoutShutdown = errors.New("exit without shutdown")
+
+// Server answers LSP requests read from one stream on another. Requests are
+// handled one at a time, in the order they arrive.
+type Server struct {
+	in      *bufio.Reader
+	out     io.Writer
+	version string
+
+	registry *rules.Registry
+	// folders are the workspace folder paths, longest first, so the first
+	// one containing a file is the innermost
+	folders []string
+	// linters are built on first use for each workspace folder, or for the
+	// directory of a file outside every folder
+	linters map[string]*linter.Linter
+	docs    map[string]*document
+
+	shutdown bool
+}
+
+// NewServer creates a server reading requests from in and writing replies
+// to out. version is reported to the client.
+func NewServer(in io.Reader, out io.Writer, version string) *Server {
+	return &Server{
+		in:       bufio.NewReader(in),
+		out:      out,
+		version:  version,
+		registry: rules.NewRegistry(),
+		linters:  make(map[string]*linter.Linter),
+		docs:     make(map[string]*document),
+	}
+}
+
+// Run serves requests until the client sends exit, or the input ends.
+func (s *Server) Run() error {
+	for {
+		body, err := readMessage(s.in)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var req request
+		if err := json.Unmarshal(body, &req); err != nil {
+			if err := s.replyError(nil, &rpcError{Code: codeParseError, Message: err.Error()}); err != nil {
+				return err
+			}
+			continue
+		}
+		if req.Method == "exit" {
+			if !s.shutdown {
+				return errExitWithoutShutdown
+			}
+			return nil
+		}
+
+		// Errors other than rpcErrors come from writing to the client
+		result, err := s.handle(&req)
+		var rerr *rpcError
+		switch {
+		case errors.As(err, &rerr):
+			if !req.isNotification() {
+				err = s.replyError(req.ID, rerr)
+			} else {
+				err = nil
+			}
+		case err == nil && !req.isNotification():
+			err = writeMessage(s.out, response{JSONRPC: "2.0", ID: req.ID, Result: result})
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// handle dispatches a request or notification to its handler.
+func (s *Server) handle(req *request) (any, error) {
+	if s.shutdown && !req.isNotification() {
+		return nil, &rpcError{Code: codeInvalidRequest, Message: "server is shut down"}
+	}
+
+	switch req.Method {
+	case "initialize":
+		var params initializeParams
+		if err := decode(req.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.initialize(params), nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if err := decode(req.Params, &params); err != nil {
+			return nil, err
+		}
+		return nil, s.open(params.TextDocument.URI, params.TextDocument.Text)
+	case "textDocument/didChange":
+		var params didChangeParams
+		if err := decode(req.Params, &params); err != nil {
+			return nil, err
+		}
+		// Full sync: the last change holds the whole document
+		if n := len(params.ContentChanges); n > 0 {
+			return nil, s.open(params.TextDocument.URI, params.ContentChanges[n-1].Text)
+		}
+		return nil, nil
+	case "textDocument/didSave":
+		// A saved config, ignore file or template may change any
+		// document's results
+		clear(s.linters)
+		return nil, s.relintAll()
+	case "textDocument/didClose":
+		var params didCloseParams
+		if err := decode(req.Params, &params); err != nil {
+			return nil, err
+		}
+		delete(s.docs, params.TextDocument.URI)
+		return nil, s.publish(params.TextDocument.URI, nil)
+	case "workspace/didChangeWorkspaceFolders":
+		var params didChangeWorkspaceFoldersParams
+		if err := decode(req.Params, &params); err != nil {
+			return nil, err
+		}
+		for _, f := range params.Event.Removed {
+			s.folders = slices.DeleteFunc(s.folders, func(path string) bool { return path == uriPath(f.URI) })
+		}
+		s.addFolders(params.Event.Added)
+		clear(s.linters)
+		return nil, s.relintAll()
+	case "textDocument/codeAction":
+		var params codeActionParams
+		if err := decode(req.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.codeActions(params), nil
+	case "textDocument/hover":
+		var params hoverParams
+		if err := decode(req.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.hover(params), nil
+	}
+
+	if req.isNotification() {
+		return nil, nil
+	}
+	return nil, &rpcError{Code: codeMethodNotFound, Message: "method not found: " + req.Method}
+}
+
+// initialize records the workspace folders and describes the server.
+func (s *Server) initialize(params initializeParams) any {
+	if len(params.WorkspaceFolders) > 0 {
+		s.addFolders(params.WorkspaceFolders)
+	} else if params.RootURI != "" {
+		s.addFolders([]WorkspaceFolder{{URI: params.RootURI}})
+	}
+
+	return map[string]any{
+		"capabilities": map[string]any{
+			"textDocumentSync": map[string]any{
+				"openClose": true,
+				"change":    1, // full document
+				"save":      true,
+			},
+			"codeActionProvider": map[string]any{
+				"codeActionKinds": []string{kindQuickFix, kindFixAll},
+			},
+			"hoverProvider": true,
+			"workspace": map[string]any{
+				"workspaceFolders": map[string]any{
+					"supported":           true,
+					"changeNotifications": true,
+				},
+			},
+		},
+		"serverInfo": map[string]any{
+			"name":    "htmlint",
+			"version": s.version,
+		},
+	}
+}
+
+// addFolders adds workspace folders, keeping the longest paths first.
+func (s *Server) addFolders(folders []WorkspaceFolder) {
+	for _, f := range folders {
+		if path := uriPath(f.URI); path != "" {
+			s.folders = append(s.folders, path)
+		}
+	}
+	slices.SortStableFunc(s.folders, func(a, b string) int { return len(b) - len(a) })
+}
+
+// open sets the text of a document and publishes its diagnostics.
+// Documents the linter doesn't check are ignored.
+func (s *Server) open(uri, text string) error {
+	path := uriPath(uri)
+	if path == "" || !linter.IsHTMLFile(path) {
+		return nil
+	}
+	doc, ok := s.docs[uri]
+	if !ok {
+		doc = &document{}
+		s.docs[uri] = doc
+	}
+	doc.setContent(text)
+	return s.lint(uri, doc)
+}
+
+// relintAll lints every open document again.
+func (s *Server) relintAll() error {
+	uris := make([]string, 0, len(s.docs))
+	for uri := range s.docs {
+		uris = append(uris, uri)
+	}
+	slices.Sort(uris)
+	for _, uri := range uris {
+		if err := s.lint(uri, s.docs[uri]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lint checks a document with the linter for its workspace folder and
+// publishes the results.
+func (s *Server) lint(uri string, doc *document) error {
+	path := uriPath(uri)
+	l, rel, err := s.linterFor(path)
+	if err != nil {
+		return err
+	}
+	doc.path = path
+	doc.linter = l
+
+	doc.results = nil
+	if !l.IsIgnored(rel) {
+		results, err := l.LintContent(path, doc.content)
+		if err != nil {
+			// Problems with the template set apply to the whole document
+			results = linter.ErrorResults(path, err)
+		}
+		doc.results = results
+	}
+
+	diagnostics := make([]Diagnostic, 0, len(doc.results))
+	for _, r := range doc.results {
+		diagnostics = append(diagnostics, doc.diagnostic(r))
+	}
+	return s.publish(uri, diagnostics)
+}
+
+// linterFor returns the linter for the file at path, and the path relative
+// to its workspace folder, for ignore patterns to match as they do when
+// htmlint runs in that folder. Files are linted by their absolute path, so
+// packages and template sets resolve from where they are, with template
+// overrides matched relative to the folder.
+func (s *Server) linterFor(path string) (*linter.Linter, string, error) {
+	dir := filepath.Dir(path)
+	for _, folder := range s.folders {
+		if rel, err := filepath.Rel(folder, path); err == nil && !strings.HasPrefix(rel, "..") {
+			dir, path = folder, rel
+			break
+		}
+	}
+	if l, ok := s.linters[dir]; ok {
+		return l, path, nil
+	}
+
+	fileCfg, configPath, err := config.Resolve(dir)
+	if err != nil {
+		if err := s.showMessage(messageError, fmt.Sprintf("htmlint: error loading config: %v", err)); err != nil {
+			return nil, "", err
+		}
+		fileCfg, configPath = nil, ""
+	}
+	cfg := config.ToLinterConfig(fileCfg, configPath)
+	cfg.Templates.Dir = dir
+	ignorePatterns, err := config.LoadIgnorePatterns(dir)
+	if err != nil {
+		if err := s.showMessage(messageWarning, fmt.Sprintf("htmlint: error loading ignore file: %v", err)); err != nil {
+			return nil, "", err
+		}
+	}
+	cfg.IgnorePatterns = append(cfg.IgnorePatterns, ignorePatterns...)
+	if err := cfg.Validate(); err != nil {
+		if err := s.showMessage(messageWarning, "htmlint: "+err.Error()); err != nil {
+			return nil, "", err
+		}
+	}
+
+	l := linter.New(cfg)
+	s.linters[dir] = l
+	return l, path, nil
+}
+
+// codeActions offers a fix for each fixable result in the requested range,
+// and one action fixing everything in the document.
+func (s *Server) codeActions(params codeActionParams) []CodeAction {
+	uri := params.TextDocument.URI
+	doc, ok := s.docs[uri]
+	if !ok {
+		return nil
+	}
+
+	from, to := doc.offset(params.Range.Start), doc.offset(params.Range.End)
+	actions := []CodeAction{}
+	fixable := false
+	for _, r := range doc.results {
+		if len(r.Edits) == 0 {
+			continue
+		}
+		fixable = true
+		start, end := doc.span(r)
+		if start > to || end < from {
+			continue
+		}
+		actions = append(actions, CodeAction{
+			Title:       fmt.Sprintf("Fix %s: %s", r.Rule, r.Message),
+			Kind:        kindQuickFix,
+			Diagnostics: []Diagnostic{doc.diagnostic(r)},
+			IsPreferred: true,
+			Edit:        &WorkspaceEdit{Changes: map[string][]TextEdit{uri: doc.textEdits(r)}},
+		})
+	}
+
+	if fixable {
+		fixed, _, err := doc.linter.FixContent(doc.path, doc.content)
+		if err == nil && string(fixed) != string(doc.content) {
+			actions = append(actions, CodeAction{
+				Title: "Fix all htmlint problems",
+				Kind:  kindFixAll,
+				Edit: &WorkspaceEdit{Changes: map[string][]TextEdit{uri: {{
+					Range:   doc.rangeOf(0, len(doc.content)),
+					NewText: string(fixed),
+				}}}},
+			})
+		}
+	}
+	return actions
+}
+
+// hover describes the rules behind the results at a position.
+func (s *Server) hover(params hoverParams) *Hover {
+	doc, ok := s.docs[params.TextDocument.URI]
+	if !ok {
+		return nil
+	}
+
+	at := doc.offset(params.Position)
+	var parts []string
+	var hovered *Range
+	for _, r := range doc.results {
+		start, end := doc.span(r)
+		if at < start || at >= max(end, start+1) {
+			continue
+		}
+		text := "**" + r.Rule + "**"
+		if rule := s.registry.ByName(r.Rule); rule != nil {
+			text += ": " + rule.Description()
+		}
+		if !slices.Contains(parts, text) {
+			parts = append(parts, text)
+		}
+		if hovered == nil {
+			rng := doc.rangeOf(start, end)
+			hovered = &rng
+		}
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+	return &Hover{
+		Contents: MarkupContent{Kind: "markdown", Value: strings.Join(parts, "\n\n")},
+		Range:    hovered,
+	}
+}
+
+// publish sends a document's diagnostics to the client.
+func (s *Server) publish(uri string, diagnostics []Diagnostic) error {
+	if diagnostics == nil {
+		diagnostics = []Diagnostic{}
+	}
+	return s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{URI: uri, Diagnostics: diagnostics})
+}
+
+// showMessage asks the client to show a message to the user.
+func (s *Server) showMessage(kind int, message string) error {
+	return s.notify("window/showMessage", showMessageParams{Type: kind, Message: message})
+}
+
+func (s *Server) notify(method string, params any) error {
+	return writeMessage(s.out, notification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+func (s *Server) replyError(id json.RawMessage, err *rpcError) error {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	return writeMessage(s.out, errorResponse{JSONRPC: "2.0", ID: id, Error: err})
+}
+
+// decode unmarshals request params, reporting a failure as invalid params.
+func decode(params json.RawMessage, v any) error {
+	if err := json.Unmarshal(params, v); err != nil {
+		return &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+// uriPath returns the file path of a file URI, or "" for other URIs.
+func uriPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return ""
+	}
+	return filepath.FromSlash(u.Path)
+}
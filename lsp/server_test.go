@@ -0,0 +1,222 @@
+package lsp_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/toba/go-html-validate/lsp"
+)
+
+// session runs a server over the given messages and returns what it wrote.
+func session(t *testing.T, messages ...map[string]any) []map[string]any {
+	t.Helper()
+	var in bytes.Buffer
+	for _, m := range messages {
+		m["jsonrpc"] = "2.0"
+		body, err := json.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	}
+
+	var out bytes.Buffer
+	if err := lsp.NewServer(&in, &out, "test").Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	var replies []map[string]any
+	r := bufio.NewReader(&out)
+	for {
+		header, err := r.ReadString('\n')
+		if err == io.EOF {
+			return replies
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		length, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(header, "Content-Length:")))
+		if err != nil {
+			t.Fatalf("bad header %q", header)
+		}
+		if _, err := r.ReadString('\n'); err != nil {
+			t.Fatal(err)
+		}
+		body := make([]byte, length)
+		if _, err := io.ReadFull(r, body); err != nil {
+			t.Fatal(err)
+		}
+		var reply map[string]any
+		if err := json.Unmarshal(body, &reply); err != nil {
+			t.Fatal(err)
+		}
+		replies = append(replies, reply)
+	}
+}
+
+// reply returns the reply to the request with the given id.
+func reply(t *testing.T, replies []map[string]any, id int) any {
+	t.Helper()
+	for _, r := range replies {
+		if r["id"] == float64(id) {
+			if r["error"] != nil {
+				t.Fatalf("request %d failed: %v", id, r["error"])
+			}
+			return r["result"]
+		}
+	}
+	t.Fatalf("no reply to request %d", id)
+	return nil
+}
+
+// diagnostics returns the codes of each publishDiagnostics notification.
+func diagnostics(replies []map[string]any) [][]string {
+	var published [][]string
+	for _, r := range replies {
+		if r["method"] != "textDocument/publishDiagnostics" {
+			continue
+		}
+		codes := []string{}
+		for _, d := range r["params"].(map[string]any)["diagnostics"].([]any) {
+			codes = append(codes, d.(map[string]any)["code"].(string))
+		}
+		published = append(published, codes)
+	}
+	return published
+}
+
+func TestServer(t *testing.T) {
+	dir := t.TempDir()
+	config := `{"rules": {"no-implicit-input-type": "off", "element-required-attributes": "off"}}`
+	if err := os.WriteFile(filepath.Join(dir, ".htmlvalidate.json"), []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".htmlvalidateignore"), []byte("vendor/\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	root := "file://" + filepath.ToSlash(dir)
+	page := root + "/web/page.gohtml"
+
+	replies := session(t,
+		map[string]any{"id": 1, "method": "initialize", "params": map[string]any{
+			"workspaceFolders": []any{map[string]any{"uri": root, "name": "site"}},
+		}},
+		map[string]any{"method": "initialized", "params": map[string]any{}},
+		map[string]any{"method": "textDocument/didOpen", "params": map[string]any{
+			"textDocument": map[string]any{"uri": page, "languageId": "html", "version": 1, "text": "<p>ok</p>"},
+		}},
+		map[string]any{"method": "textDocument/didChange", "params": map[string]any{
+			"textDocument":   map[string]any{"uri": page, "version": 2},
+			"contentChanges": []any{map[string]any{"text": "<p>é</p><button>Go</button>\n<img src=\"a.png\"><input>"}},
+		}},
+		map[string]any{"method": "textDocument/didOpen", "params": map[string]any{
+			"textDocument": map[string]any{"uri": root + "/vendor/lib.html", "text": "<img src=\"a.png\">"},
+		}},
+		map[string]any{"method": "textDocument/didOpen", "params": map[string]any{
+			"textDocument": map[string]any{"uri": root + "/main.go", "text": "package main"},
+		}},
+		map[string]any{"id": 2, "method": "textDocument/codeAction", "params": map[string]any{
+			"textDocument": map[string]any{"uri": page},
+			"range": map[string]any{
+				"start": map[string]any{"line": 0, "character": 10},
+				"end":   map[string]any{"line": 0, "character": 10},
+			},
+			"context": map[string]any{"diagnostics": []any{}},
+		}},
+		map[string]any{"id": 3, "method": "textDocument/hover", "params": map[string]any{
+			"textDocument": map[string]any{"uri": page},
+			"position":     map[string]any{"line": 1, "character": 2},
+		}},
+		map[string]any{"id": 4, "method": "textDocument/unknown", "params": map[string]any{}},
+		map[string]any{"id": 5, "method": "shutdown"},
+		map[string]any{"method": "exit"},
+	)
+
+	init := reply(t, replies, 1).(map[string]any)
+	if init["capabilities"].(map[string]any)["hoverProvider"] != true {
+		t.Errorf("expected hover capability, got %v", init)
+	}
+
+	// Opening and changing the page publish its diagnostics; the ignored
+	// file publishes none, and main.go isn't linted
+	got := fmt.Sprint(diagnostics(replies))
+	if want := "[[] [img-alt input-label button-type] []]"; got != want {
+		t.Errorf("published %s, want %s", got, want)
+	}
+
+	actions := reply(t, replies, 2).([]any)
+	if len(actions) != 2 {
+		t.Fatalf("expected a quick fix and fix all, got %v", actions)
+	}
+	fix := actions[0].(map[string]any)
+	edit := fix["edit"].(map[string]any)["changes"].(map[string]any)[page].([]any)[0].(map[string]any)
+	// The button starts after "<p>é</p>", where é is one UTF-16 code unit
+	wantEdit := `{"newText":" type=\"button\"","range":{"end":{"character":15,"line":0},"start":{"character":15,"line":0}}}`
+	if b, _ := json.Marshal(edit); string(b) != wantEdit {
+		t.Errorf("quick fix edit %s, want %s", b, wantEdit)
+	}
+	if kind := actions[1].(map[string]any)["kind"]; kind != "source.fixAll" {
+		t.Errorf("second action kind %v, want source.fixAll", kind)
+	}
+
+	hover := reply(t, replies, 3).(map[string]any)
+	value := hover["contents"].(map[string]any)["value"].(string)
+	if !strings.Contains(value, "**img-alt**: ") {
+		t.Errorf("hover %q, want the img-alt description", value)
+	}
+
+	for _, r := range replies {
+		if r["id"] == float64(4) {
+			if code := r["error"].(map[string]any)["code"]; code != float64(-32601) {
+				t.Errorf("unknown method error code %v", code)
+			}
+		}
+	}
+	if reply(t, replies, 5) != nil {
+		t.Error("expected null shutdown result")
+	}
+}
+
+func TestServer_WorkspaceFolderPaths(t *testing.T) {
+	// Documents are linted by absolute path, with overrides still matched
+	// relative to the workspace folder
+	dir := t.TempDir()
+	cfg := `{"templates": {"overrides": [{"files": "web/**", "delims": ["[[", "]]"], "data": "net/url.URL"}]}}`
+	if err := os.WriteFile(filepath.Join(dir, ".htmlvalidate.json"), []byte(cfg), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	root := "file://" + filepath.ToSlash(dir)
+
+	replies := session(t,
+		map[string]any{"id": 1, "method": "initialize", "params": map[string]any{
+			"workspaceFolders": []any{map[string]any{"uri": root, "name": "site"}},
+		}},
+		map[string]any{"method": "textDocument/didOpen", "params": map[string]any{
+			"textDocument": map[string]any{"uri": root + "/web/page.gohtml", "text": "<p>[[ .Host ]] [[ .Hots ]] {{ .Hots }}</p>"},
+		}},
+		map[string]any{"id": 2, "method": "shutdown"},
+		map[string]any{"method": "exit"},
+	)
+
+	var messages []string
+	for _, r := range replies {
+		if r["method"] != "textDocument/publishDiagnostics" {
+			continue
+		}
+		for _, d := range r["params"].(map[string]any)["diagnostics"].([]any) {
+			messages = append(messages, d.(map[string]any)["message"].(string))
+		}
+	}
+	want := []string{"can't evaluate field Hots in type url.URL"}
+	if fmt.Sprint(messages) != fmt.Sprint(want) {
+		t.Errorf("diagnostics %q, want %q", messages, want)
+	}
+}
@@ -3,10 +3,17 @@
 // Usage:
 //
 //	htmlint [options] <files or directories>
+//	htmlint lsp
+//
+// The lsp subcommand serves the Language Server Protocol on stdin and
+// stdout, for editors.
 //
 // Options:
 //
-//	-f, --format     Output format: text, json (default: text)
+//	-f, --format     Output format: text, codeframe, json, sarif, github, gitlab,
+//	                 checkstyle, junit (default: text). Give name:path to write
+//	                 to a file; repeat for several outputs
+//	-o, --output-file Write the output format to a file instead of stdout
 //	-q, --quiet      Only show errors, not warnings
 //	--no-color       Disable colored output
 //	--ignore         Glob patterns to ignore (can be repeated)
@@ -14,6 +21,16 @@
 //	--config         Path to config file
 //	--no-config      Disable config file loading
 //	--print-config   Print resolved configuration and exit
+//	-j, --jobs       Files to lint concurrently (default: GOMAXPROCS)
+//	--cache          Only lint files changed since the last cached run
+//	--cache-location Cache file or directory (default: .htmlintcache)
+//	--fix            Fix problems that have a mechanical fix
+//	--fix-dry-run    Print fixes as a unified diff without changing files
+//	--stdin          Lint standard input instead of files
+//	--stdin-filename Path to lint standard input as (default: <stdin>)
+//	--watch          Re-lint files as they change, printing new and fixed problems
+//	--changed-since  Only lint files changed since a git revision
+//	--changed-lines-only Only report problems on lines changed since the revision
 //	-h, --help       Show help
 //
 // Examples:
@@ -21,19 +38,29 @@
 //	htmlint web/
 //	htmlint -q web/**/*.html
 //	htmlint --format=json web/ > lint-results.json
+//	htmlint -f text -f json:lint.json -f sarif:lint.sarif web/
+//	htmlint --stdin --stdin-filename=web/page.gohtml < page.gohtml
+//	htmlint --watch web/
+//	htmlint --changed-since=origin/main --changed-lines-only web/
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"runtime/debug"
+	"slices"
 	"strings"
 
 	"github.com/toba/go-html-validate/config"
 	"github.com/toba/go-html-validate/linter"
+	"github.com/toba/go-html-validate/lsp"
 	"github.com/toba/go-html-validate/reporter"
 	"github.com/toba/go-html-validate/rules"
 )
@@ -41,6 +68,145 @@ import (
 // version is set by ldflags during GoReleaser build.
 var version = ""
 
+// reporterOptions are the flags reporters are built with.
+type reporterOptions struct {
+	writer  io.Writer
+	noColor bool
+	version string
+	// rules are the rules the linter checks
+	rules []string
+}
+
+// format is an output format. Readable formats are meant for people, and
+// can share standard output with the diffs of --fix-dry-run.
+type format struct {
+	name     string
+	readable bool
+	new      func(opts reporterOptions) linter.Reporter
+}
+
+// formats are the output formats, in the order usage lists them.
+var formats = []format{
+	{"text", true, func(opts reporterOptions) linter.Reporter {
+		r := reporter.NewText()
+		r.Writer = opts.writer
+		r.NoColor = opts.noColor
+		return r
+	}},
+	{"codeframe", true, func(opts reporterOptions) linter.Reporter {
+		r := reporter.NewCodeFrame()
+		r.Writer = opts.writer
+		r.NoColor = opts.noColor
+		return r
+	}},
+	{"json", false, func(opts reporterOptions) linter.Reporter {
+		r := reporter.NewJSON()
+		r.Writer = opts.writer
+		return r
+	}},
+	{"sarif", false, func(opts reporterOptions) linter.Reporter {
+		r := reporter.NewSARIF()
+		r.Writer = opts.writer
+		r.Version = opts.version
+		return r
+	}},
+	{"github", false, func(opts reporterOptions) linter.Reporter {
+		r := reporter.NewGitHub()
+		r.Writer = opts.writer
+		return r
+	}},
+	{"gitlab", false, func(opts reporterOptions) linter.Reporter {
+		r := reporter.NewGitLab()
+		r.Writer = opts.writer
+		return r
+	}},
+	{"checkstyle", false, func(opts reporterOptions) linter.Reporter {
+		r := reporter.NewCheckstyle()
+		r.Writer = opts.writer
+		return r
+	}},
+	{"junit", false, func(opts reporterOptions) linter.Reporter {
+		r := reporter.NewJUnit()
+		r.Writer = opts.writer
+		r.Rules = opts.rules
+		return r
+	}},
+}
+
+// output is an output format and the file it's written to, or "" for
+// standard output.
+type output struct {
+	format   string
+	readable bool
+	new      func(opts reporterOptions) linter.Reporter
+	path     string
+}
+
+// parseOutputs reads --format values, each name or name:path, and applies
+// --output-file to the one without a path.
+func parseOutputs(specs []string, outputFile string) ([]output, error) {
+	if len(specs) == 0 {
+		specs = []string{"text"}
+	}
+	var outputs []output
+	paths := make(map[string]bool)
+	toStdout := 0
+	for _, spec := range specs {
+		name, path, _ := strings.Cut(spec, ":")
+		f, ok := formatByName(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown format %q; use one of: %s", name, strings.Join(formatNames(), ", "))
+		}
+		if path == "" {
+			toStdout++
+		}
+		outputs = append(outputs, output{format: name, readable: f.readable, new: f.new, path: path})
+	}
+
+	if outputFile != "" {
+		if toStdout != 1 {
+			return nil, errors.New("--output-file needs exactly one --format without a path")
+		}
+		for i := range outputs {
+			if outputs[i].path == "" {
+				outputs[i].path = outputFile
+			}
+		}
+		toStdout = 0
+	}
+	if toStdout > 1 {
+		return nil, errors.New("only one format can write to standard output; give the others a path with --format name:path")
+	}
+	for _, o := range outputs {
+		if o.path != "" {
+			if paths[o.path] {
+				return nil, fmt.Errorf("more than one format writes to %s", o.path)
+			}
+			paths[o.path] = true
+		}
+	}
+	return outputs, nil
+}
+
+// formatByName returns the named output format.
+func formatByName(name string) (format, bool) {
+	for _, f := range formats {
+		if f.name == name {
+			return f, true
+		}
+	}
+	return format{}, false
+}
+
+// formatNames lists the output formats.
+func formatNames() []string {
+	names := make([]string, 0, len(formats))
+	for _, f := range formats {
+		names = append(names, f.name)
+	}
+	return names
+}
+
 type stringSlice []string
 
 func (s *stringSlice) String() string { return strings.Join(*s, ",") }
@@ -54,8 +220,13 @@ func main() {
 }
 
 func run() int {
+	if len(os.Args) > 1 && os.Args[1] == "lsp" {
+		return runLSP()
+	}
+
 	var (
-		format       string
+		formatFlags  stringSlice
+		outputFile   string
 		quiet        bool
 		noColor      bool
 		ignoreFlags  stringSlice
@@ -66,10 +237,22 @@ func run() int {
 		configPath   string
 		noConfig     bool
 		printConfig  bool
+		jobs         int
+		useCache     bool
+		cachePath    string
+		fix          bool
+		fixDryRun    bool
+		useStdin     bool
+		stdinName    string
+		watch        bool
+		changedSince string
+		changedLines bool
 	)
 
-	flag.StringVar(&format, "format", "text", "Output format: text, json")
-	flag.StringVar(&format, "f", "text", "Output format (shorthand)")
+	flag.Var(&formatFlags, "format", "Output format, as name or name:path (can be repeated)")
+	flag.Var(&formatFlags, "f", "Output format (shorthand)")
+	flag.StringVar(&outputFile, "output-file", "", "Write the output format to a file")
+	flag.StringVar(&outputFile, "o", "", "Write the output format to a file (shorthand)")
 	flag.BoolVar(&quiet, "quiet", false, "Only show errors")
 	flag.BoolVar(&quiet, "q", false, "Only show errors (shorthand)")
 	flag.BoolVar(&noColor, "no-color", false, "Disable colored output")
@@ -83,6 +266,17 @@ func run() int {
 	flag.StringVar(&configPath, "config", "", "Path to config file")
 	flag.BoolVar(&noConfig, "no-config", false, "Disable config file loading")
 	flag.BoolVar(&printConfig, "print-config", false, "Print resolved configuration")
+	flag.IntVar(&jobs, "jobs", 0, "Files to lint concurrently (default: GOMAXPROCS)")
+	flag.IntVar(&jobs, "j", 0, "Files to lint concurrently (shorthand)")
+	flag.BoolVar(&useCache, "cache", false, "Only lint files changed since the last cached run")
+	flag.StringVar(&cachePath, "cache-location", linter.DefaultCacheFile, "Cache file or directory")
+	flag.BoolVar(&fix, "fix", false, "Fix problems that have a mechanical fix")
+	flag.BoolVar(&fixDryRun, "fix-dry-run", false, "Print fixes as a unified diff")
+	flag.BoolVar(&useStdin, "stdin", false, "Lint standard input")
+	flag.StringVar(&stdinName, "stdin-filename", "", "Path to lint standard input as")
+	flag.BoolVar(&watch, "watch", false, "Re-lint files as they change")
+	flag.StringVar(&changedSince, "changed-since", "", "Only lint files changed since a git revision")
+	flag.BoolVar(&changedLines, "changed-lines-only", false, "Only report problems on changed lines")
 
 	flag.Usage = usage
 	flag.Parse()
@@ -104,9 +298,64 @@ func run() int {
 
 	args := flag.Args()
 
-	// Determine search directory for config
+	if stdinName != "" && !useStdin {
+		fmt.Fprintln(os.Stderr, "error: --stdin-filename requires --stdin")
+		return 1
+	}
+	if useStdin && len(args) > 0 {
+		fmt.Fprintln(os.Stderr, "error: --stdin can't be combined with files or directories")
+		return 1
+	}
+	if fix && fixDryRun {
+		fmt.Fprintln(os.Stderr, "error: --fix and --fix-dry-run can't be combined")
+		return 1
+	}
+	if fix && useStdin {
+		fmt.Fprintln(os.Stderr, "error: --fix can't write to standard input; use --fix-dry-run")
+		return 1
+	}
+	if watch && (useStdin || fix || fixDryRun || useCache) {
+		fmt.Fprintln(os.Stderr, "error: --watch can't be combined with --stdin, --fix, --fix-dry-run or --cache")
+		return 1
+	}
+	if changedLines && changedSince == "" {
+		fmt.Fprintln(os.Stderr, "error: --changed-lines-only requires --changed-since")
+		return 1
+	}
+	if changedSince != "" && (useStdin || watch) {
+		fmt.Fprintln(os.Stderr, "error: --changed-since can't be combined with --stdin or --watch")
+		return 1
+	}
+	if changedLines && fix {
+		fmt.Fprintln(os.Stderr, "error: --fix changes whole files and can't be combined with --changed-lines-only")
+		return 1
+	}
+	outputs, err := parseOutputs(formatFlags, outputFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	if fixDryRun {
+		if i := slices.IndexFunc(outputs, func(o output) bool { return o.path == "" && !o.readable }); i >= 0 {
+			fmt.Fprintf(os.Stderr, "error: --fix-dry-run prints diffs to standard output; write %s to a file with --format %s:path\n",
+				outputs[i].format, outputs[i].format)
+			return 1
+		}
+	}
+	if watch && slices.ContainsFunc(outputs, func(o output) bool { return o.path != "" }) {
+		fmt.Fprintln(os.Stderr, "error: --watch writes to standard output and can't be combined with output files")
+		return 1
+	}
+	if useStdin && stdinName == "" {
+		stdinName = "<stdin>"
+	}
+
+	// Determine search directory for config. Standard input is linted as if
+	// it were the file at stdinName, which need not exist.
 	searchDir := "."
-	if len(args) > 0 {
+	if useStdin {
+		searchDir = filepath.Dir(stdinName)
+	} else if len(args) > 0 {
 		if info, err := os.Stat(args[0]); err == nil && info.IsDir() {
 			searchDir = args[0]
 		} else if err == nil {
@@ -114,51 +363,75 @@ func run() int {
 		}
 	}
 
-	// Load config file
-	var fileCfg *config.FileConfig
-	var loadedConfigPath string
-	if !noConfig {
-		var err error
-		if configPath != "" {
-			fileCfg, err = config.LoadFile(configPath)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "error: %v\n", err)
-				return 1
+	// loadConfig builds the linter config from the config and ignore files
+	// and the flags, returning the config file it loaded and the config and
+	// ignore files watch mode reloads it on.
+	loadConfig := func() (*linter.Config, string, []string, error) {
+		var fileCfg *config.FileConfig
+		var loadedConfigPath string
+		var watched []string
+		if !noConfig {
+			var err error
+			if configPath != "" {
+				fileCfg, err = config.LoadFile(configPath)
+				if err != nil {
+					return nil, "", nil, err
+				}
+				loadedConfigPath = configPath
+				// Resolve extends for explicit config
+				fileCfg, err = resolveExtendsFromPath(fileCfg, configPath)
+				if err != nil {
+					return nil, "", nil, err
+				}
+			} else {
+				fileCfg, loadedConfigPath, err = config.Resolve(searchDir)
+				if err != nil {
+					return nil, "", nil, fmt.Errorf("loading config: %w", err)
+				}
 			}
-			loadedConfigPath = configPath
-			// Resolve extends for explicit config
-			fileCfg, err = resolveExtendsFromPath(fileCfg, configPath)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "error: %v\n", err)
-				return 1
+			if loadedConfigPath != "" {
+				watched = append(watched, loadedConfigPath)
+			} else {
+				watched = append(watched, filepath.Join(searchDir, config.ConfigFileName))
 			}
+		}
+
+		// Load ignore patterns
+		ignorePatterns, err := config.LoadIgnorePatterns(searchDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: error loading ignore file: %v\n", err)
+		}
+		if ignorePath, _ := config.FindIgnoreFile(searchDir); ignorePath != "" {
+			watched = append(watched, ignorePath)
 		} else {
-			fileCfg, loadedConfigPath, err = config.Resolve(searchDir)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "error loading config: %v\n", err)
-				return 1
-			}
+			watched = append(watched, filepath.Join(searchDir, config.IgnoreFileName))
 		}
-	}
 
-	// Load ignore patterns
-	ignorePatterns, err := config.LoadIgnorePatterns(searchDir)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "warning: error loading ignore file: %v\n", err)
-	}
+		// Build linter config from file config
+		cfg := config.ToLinterConfig(fileCfg, loadedConfigPath)
+
+		// Add ignore patterns from ignore file
+		cfg.IgnorePatterns = append(cfg.IgnorePatterns, ignorePatterns...)
 
-	// Build linter config from file config
-	cfg := config.ToLinterConfig(fileCfg, loadedConfigPath)
+		// CLI flags override config file
+		cfg.DisabledRules = append(cfg.DisabledRules, disableFlags...)
+		cfg.IgnorePatterns = append(cfg.IgnorePatterns, ignoreFlags...)
 
-	// Add ignore patterns from ignore file
-	cfg.IgnorePatterns = append(cfg.IgnorePatterns, ignorePatterns...)
+		if quiet {
+			cfg.ErrorsOnly()
+		}
+		cfg.Jobs = jobs
 
-	// CLI flags override config file
-	cfg.DisabledRules = append(cfg.DisabledRules, disableFlags...)
-	cfg.IgnorePatterns = append(cfg.IgnorePatterns, ignoreFlags...)
+		if err := cfg.Validate(); err != nil {
+			return nil, "", nil, err
+		}
+		return cfg, loadedConfigPath, watched, nil
+	}
 
-	if quiet {
-		cfg.ErrorsOnly()
+	cfg, loadedConfigPath, _, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
 	}
 
 	// Print config and exit if requested
@@ -167,40 +440,142 @@ func run() int {
 		return 0
 	}
 
-	if len(args) == 0 {
+	if len(args) == 0 && !useStdin {
 		fmt.Fprintln(os.Stderr, "error: no files or directories specified")
 		fmt.Fprintln(os.Stderr, "usage: htmlint [options] <files or directories>")
 		return 1
 	}
 
+	// Output files are created up front, so a bad path fails before linting
+	writers := make([]io.Writer, len(outputs))
+	for i, o := range outputs {
+		if o.path == "" {
+			writers[i] = os.Stdout
+			continue
+		}
+		f, err := os.Create(o.path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			return 1
+		}
+		defer func() {
+			if err := f.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "error: writing %s: %v\n", o.path, err)
+			}
+		}()
+		writers[i] = f
+	}
+
+	setReporters := func(l *linter.Linter) {
+		for i, o := range outputs {
+			r := o.new(reporterOptions{
+				writer: writers[i],
+				// Files get plain text
+				noColor: noColor || o.path != "",
+				version: getVersion(),
+				rules:   l.RuleNames(),
+			})
+			if i == 0 {
+				l.SetReporter(r)
+			} else {
+				l.AddReporter(r)
+			}
+		}
+	}
+
+	if watch {
+		return runWatch(args, func() (*linter.Linter, []string, error) {
+			cfg, _, watched, err := loadConfig()
+			if err != nil {
+				return nil, nil, err
+			}
+			l := linter.New(cfg)
+			setReporters(l)
+			return l, watched, nil
+		})
+	}
+
 	// Create linter
 	l := linter.New(cfg)
+	setReporters(l)
+
+	switch {
+	case fix:
+		l.SetFix(linter.FixWrite, os.Stdout)
+	case fixDryRun:
+		l.SetFix(linter.FixDryRun, os.Stdout)
+	}
 
-	// Set reporter
-	var rep linter.Reporter
-	switch format {
-	case "json":
-		rep = reporter.NewJSON()
-	default:
-		textRep := reporter.NewText()
-		textRep.NoColor = noColor
-		rep = textRep
+	if changedSince != "" {
+		changes, err := linter.GitChanges(searchDir, changedSince)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			return 1
+		}
+		l.SetChanges(changes, changedLines)
+	}
+
+	var cache *linter.Cache
+	if useCache {
+		cache, err = l.OpenCache(cachePath, getVersion())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			return 1
+		}
 	}
-	l.SetReporter(rep)
 
 	// Run linting
-	errorCount, err := l.Run(args)
+	var errorCount int
+	if useStdin {
+		var content []byte
+		content, err = io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: reading standard input: %v\n", err)
+			return 1
+		}
+		errorCount, err = l.RunContent(stdinName, content)
+	} else {
+		errorCount, err = l.Run(args)
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		return 1
 	}
 
+	if cache != nil {
+		if err := cache.Save(); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: error saving cache: %v\n", err)
+		}
+	}
+
 	if errorCount > 0 {
 		return 1
 	}
 	return 0
 }
 
+// runWatch lints paths, then re-lints them as they change until
+// interrupted.
+func runWatch(paths []string, load func() (*linter.Linter, []string, error)) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	w := &linter.Watcher{Paths: paths, Load: load}
+	if err := w.Run(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// runLSP serves the Language Server Protocol on stdin and stdout.
+func runLSP() int {
+	if err := lsp.NewServer(os.Stdin, os.Stdout, getVersion()).Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
 // resolveExtendsFromPath resolves extends for a config loaded from an explicit path.
 func resolveExtendsFromPath(cfg *config.FileConfig, path string) (*config.FileConfig, error) {
 	if len(cfg.Extends) == 0 {
@@ -212,6 +587,7 @@ func resolveExtendsFromPath(cfg *config.FileConfig, path string) (*config.FileCo
 	result := &config.FileConfig{
 		Root:       cfg.Root,
 		Frameworks: cfg.Frameworks,
+		Templates:  cfg.Templates,
 		Rules:      make(map[string]config.RuleConfig),
 	}
 
@@ -247,13 +623,15 @@ func resolveExtendsFromPath(cfg *config.FileConfig, path string) (*config.FileCo
 
 func printResolvedConfig(cfg *linter.Config, configPath string) {
 	output := struct {
-		ConfigFile     string            `json:"configFile,omitempty"`
-		DisabledRules  []string          `json:"disabledRules,omitempty"`
-		RuleSeverities map[string]string `json:"ruleSeverities,omitempty"`
-		IgnorePatterns []string          `json:"ignorePatterns,omitempty"`
+		ConfigFile     string                    `json:"configFile,omitempty"`
+		DisabledRules  []string                  `json:"disabledRules,omitempty"`
+		RuleSeverities map[string]string         `json:"ruleSeverities,omitempty"`
+		RuleOptions    map[string]map[string]any `json:"ruleOptions,omitempty"`
+		IgnorePatterns []string                  `json:"ignorePatterns,omitempty"`
 	}{
 		ConfigFile:     configPath,
 		DisabledRules:  cfg.DisabledRules,
+		RuleOptions:    cfg.RuleOptions,
 		IgnorePatterns: cfg.IgnorePatterns,
 	}
 
@@ -274,9 +652,17 @@ func usage() {
 
 Usage:
   htmlint [options] <files or directories>
+  htmlint lsp       Serve the Language Server Protocol on stdin/stdout
 
 Options:
-  -f, --format      Output format: text, json (default: text)
+  -f, --format NAME[:PATH]
+                    Output format: text, codeframe (text with source
+                    snippets), json, sarif, github (Actions annotations),
+                    gitlab (Code Quality), checkstyle, junit (default: text).
+                    With PATH, written to that file; repeat for several
+                    outputs from one run
+  -o, --output-file PATH
+                    Write the format given without a path to PATH
   -q, --quiet       Only show errors, not warnings
   --no-color        Disable colored output
   --ignore PATTERN  Glob pattern to ignore (can be repeated)
@@ -284,6 +670,24 @@ Options:
   --config PATH     Path to config file (.htmlvalidate.json)
   --no-config       Disable config file loading
   --print-config    Print resolved configuration and exit
+  -j, --jobs N      Files to lint concurrently (default: GOMAXPROCS)
+  --cache           Only lint files changed since the last cached run
+  --cache-location PATH
+                    Cache file or directory (default: .htmlintcache)
+  --fix             Fix problems that have a mechanical fix, in place
+  --fix-dry-run     Print fixes as a unified diff without changing files
+  --stdin           Lint standard input instead of files
+  --stdin-filename PATH
+                    Lint standard input as if it were PATH, for config,
+                    ignore files and results (default: <stdin>)
+  --watch           Keep running, re-linting files as they change and
+                    printing the problems that appear or go away
+  --changed-since REV
+                    Only lint files that changed since the git revision
+                    REV, including uncommitted and untracked files
+  --changed-lines-only
+                    With --changed-since, only report problems on the
+                    lines that changed
   --list-rules      List available rules
   -v, --version     Show version
   -h, --help        Show this help
@@ -296,7 +700,14 @@ Examples:
   htmlint web/
   htmlint -q web/**/*.html
   htmlint --format=json web/ > lint-results.json
+  htmlint --format=sarif web/ > htmlint.sarif
+  htmlint --format=gitlab web/ > gl-code-quality-report.json
+  htmlint -f text -f json:lint.json -f sarif:lint.sarif web/
   htmlint --disable=prefer-aria web/
+  htmlint --stdin --stdin-filename=web/page.gohtml < page.gohtml
+  htmlint --fix-dry-run web/ | less
+  htmlint --watch web/
+  htmlint --changed-since=origin/main --changed-lines-only web/
 `)
 }
 
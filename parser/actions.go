@@ -0,0 +1,337 @@
+package parser
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// Delims are the action delimiters of a template, as set with
+// template.Delims. The zero value means the default "{{" and "}}".
+type Delims struct {
+	Left  string
+	Right string
+}
+
+// DefaultDelims are the delimiters text/template uses unless told otherwise.
+var DefaultDelims = Delims{Left: "{{", Right: "}}"}
+
+// OrDefault returns d, or DefaultDelims if either delimiter is empty.
+func (d Delims) OrDefault() Delims {
+	if d.Left == "" || d.Right == "" {
+		return DefaultDelims
+	}
+	return d
+}
+
+// ActionKind identifies what a template action does.
+type ActionKind int
+
+const (
+	// ActionOutput prints a value: {{ .Field }}, {{ func .Arg }}.
+	ActionOutput ActionKind = iota
+	// ActionVariable declares or assigns a variable: {{ $x := .Field }}.
+	ActionVariable
+	// ActionComment is a template comment: {{/* ... */}}.
+	ActionComment
+	// ActionIf opens a conditional: {{ if .Cond }}.
+	ActionIf
+	// ActionElse starts an else branch: {{ else }}.
+	ActionElse
+	// ActionElseIf starts a chained conditional branch: {{ else if .Cond }}.
+	ActionElseIf
+	// ActionElseWith starts a chained with branch: {{ else with .Value }}.
+	ActionElseWith
+	// ActionEnd closes a control structure: {{ end }}.
+	ActionEnd
+	// ActionRange opens a loop: {{ range .Items }}.
+	ActionRange
+	// ActionWith opens a with block: {{ with .Value }}.
+	ActionWith
+	// ActionTemplate includes another template: {{ template "name" . }}.
+	ActionTemplate
+	// ActionBlock defines and includes a template: {{ block "name" . }}.
+	ActionBlock
+	// ActionDefine defines a template: {{ define "name" }}.
+	ActionDefine
+	// ActionBreak exits a range loop: {{ break }}.
+	ActionBreak
+	// ActionContinue skips to the next range iteration: {{ continue }}.
+	ActionContinue
+)
+
+func (k ActionKind) String() string {
+	switch k {
+	case ActionOutput:
+		return "output"
+	case ActionVariable:
+		return "variable"
+	case ActionComment:
+		return "comment"
+	case ActionIf:
+		return "if"
+	case ActionElse:
+		return "else"
+	case ActionElseIf:
+		return "else if"
+	case ActionElseWith:
+		return "else with"
+	case ActionEnd:
+		return "end"
+	case ActionRange:
+		return "range"
+	case ActionWith:
+		return "with"
+	case ActionTemplate:
+		return "template"
+	case ActionBlock:
+		return "block"
+	case ActionDefine:
+		return "define"
+	case ActionBreak:
+		return "break"
+	case ActionContinue:
+		return "continue"
+	default:
+		return "unknown"
+	}
+}
+
+// actionKeywords maps the leading keyword of an action to its kind.
+var actionKeywords = map[string]ActionKind{
+	"if":       ActionIf,
+	"else":     ActionElse,
+	"end":      ActionEnd,
+	"range":    ActionRange,
+	"with":     ActionWith,
+	"template": ActionTemplate,
+	"block":    ActionBlock,
+	"define":   ActionDefine,
+	"break":    ActionBreak,
+	"continue": ActionContinue,
+}
+
+// TemplateAction is a single template action in the original source.
+type TemplateAction struct {
+	Kind ActionKind
+	// Pipeline is the action text after its keyword and trim markers,
+	// e.g. ".User.Name" for {{ if .User.Name }}. Empty for comments.
+	Pipeline string
+	// Name is the template name for template, block and define actions.
+	Name string
+	// Span covers the action from its opening to its closing delimiter.
+	Span Span
+	// TrimLeft and TrimRight report the "{{- " and " -}}" trim markers
+	// (or their equivalents with custom delimiters).
+	TrimLeft, TrimRight bool
+	// Closed is false when the action runs to the end of input without a
+	// closing delimiter.
+	Closed bool
+	// Context is where the action sits in the surrounding HTML, which
+	// decides how html/template escapes its value.
+	Context EscapeContext
+}
+
+// IsControl reports whether the action is part of a control structure.
+func (a TemplateAction) IsControl() bool {
+	switch a.Kind {
+	case ActionIf, ActionElse, ActionElseIf, ActionElseWith, ActionEnd,
+		ActionRange, ActionWith, ActionBlock, ActionDefine, ActionBreak, ActionContinue:
+		return true
+	}
+	return false
+}
+
+// isTemplateSpace reports whether c is whitespace as far as trim markers go.
+func isTemplateSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+// ScanActions finds every template action in content delimited by delims.
+// It follows the text/template lexer closely enough to skip delimiters inside
+// string literals and comments, which a regular expression can't, and works
+// on templates that don't parse. Each action's escape context is filled in
+// from the HTML around it.
+func ScanActions(content []byte, delims Delims) []TemplateAction {
+	var actions []TemplateAction
+	lines := newLineIndex(content)
+	delims = delims.OrDefault()
+	left, right := []byte(delims.Left), []byte(delims.Right)
+
+	for pos := 0; pos < len(content); {
+		i := bytes.Index(content[pos:], left)
+		if i < 0 {
+			break
+		}
+		start := pos + i
+		inner := start + len(left)
+
+		action := TemplateAction{}
+		if inner+1 < len(content) && content[inner] == '-' && isTemplateSpace(content[inner+1]) {
+			action.TrimLeft = true
+			inner += 2
+		}
+
+		end, closeAt := scanActionEnd(content, inner, right)
+		action.Closed = closeAt >= 0
+		if !action.Closed {
+			closeAt = len(content)
+		}
+
+		// Trailing trim marker: " -" right before the closing delimiter
+		body := content[inner:closeAt]
+		if n := len(body); n >= 2 && body[n-1] == '-' && isTemplateSpace(body[n-2]) {
+			action.TrimRight = true
+			body = body[:n-2]
+		}
+
+		classifyAction(&action, strings.TrimSpace(string(body)))
+		action.Span = Span{Start: lines.position(start), End: lines.position(end)}
+		actions = append(actions, action)
+		pos = end
+	}
+
+	assignContexts(content, actions)
+	return actions
+}
+
+// scanActionEnd finds the closing delimiter of an action whose body starts at
+// from. Returns the offset just past the delimiter and the offset of the
+// delimiter itself, or (len(content), -1) if the action is never closed.
+func scanActionEnd(content []byte, from int, right []byte) (end, closeAt int) {
+	i := from
+	for i < len(content) && isTemplateSpace(content[i]) {
+		i++
+	}
+	// Comments must directly follow the delimiter and may contain anything
+	if bytes.HasPrefix(content[i:], []byte("/*")) {
+		c := bytes.Index(content[i+2:], []byte("*/"))
+		if c < 0 {
+			return len(content), -1
+		}
+		i += 2 + c + 2
+	}
+
+	for i < len(content) {
+		switch c := content[i]; c {
+		case '"', '\'':
+			// Interpreted string or character constant, with escapes
+			i++
+			for i < len(content) && content[i] != c && content[i] != '\n' {
+				if content[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			i++
+		case '`':
+			// Raw string
+			r := bytes.IndexByte(content[i+1:], '`')
+			if r < 0 {
+				return len(content), -1
+			}
+			i += 1 + r + 1
+		default:
+			if bytes.HasPrefix(content[i:], right) {
+				return i + len(right), i
+			}
+			i++
+		}
+	}
+	return len(content), -1
+}
+
+// classifyAction sets the kind, pipeline and name of an action from its
+// trimmed body text.
+func classifyAction(a *TemplateAction, body string) {
+	if strings.HasPrefix(body, "/*") {
+		a.Kind = ActionComment
+		return
+	}
+
+	keyword, rest := splitKeyword(body)
+	kind, ok := actionKeywords[keyword]
+	if !ok {
+		a.Kind = ActionOutput
+		a.Pipeline = body
+		if isVariableAction(body) {
+			a.Kind = ActionVariable
+		}
+		return
+	}
+
+	a.Kind = kind
+	a.Pipeline = rest
+	if kind == ActionElse {
+		switch chained, chainedRest := splitKeyword(rest); chained {
+		case "if":
+			a.Kind, a.Pipeline = ActionElseIf, chainedRest
+		case "with":
+			a.Kind, a.Pipeline = ActionElseWith, chainedRest
+		}
+	}
+	if kind == ActionTemplate || kind == ActionBlock || kind == ActionDefine {
+		a.Name = templateName(rest)
+	}
+}
+
+// splitKeyword splits the first identifier off an action body.
+func splitKeyword(body string) (keyword, rest string) {
+	end := 0
+	for end < len(body) && (body[end] >= 'a' && body[end] <= 'z') {
+		end++
+	}
+	// A keyword must stand alone: "end" but not "endpoint" or "end.X"
+	if end < len(body) && !isTemplateSpace(body[end]) && body[end] != '(' {
+		return "", body
+	}
+	return body[:end], strings.TrimSpace(body[end:])
+}
+
+// isVariableAction reports whether an action declares or assigns a
+// variable, which produces no output.
+func isVariableAction(body string) bool {
+	if !strings.HasPrefix(body, "$") {
+		return false
+	}
+	i := 1
+	for i < len(body) && (body[i] == '_' || body[i] == ',' || body[i] == '$' ||
+		isTemplateSpace(body[i]) || (body[i] >= 'a' && body[i] <= 'z') ||
+		(body[i] >= 'A' && body[i] <= 'Z') || (body[i] >= '0' && body[i] <= '9')) {
+		i++
+	}
+	rest := body[i:]
+	return strings.HasPrefix(rest, ":=") || (strings.HasPrefix(rest, "=") && !strings.HasPrefix(rest, "=="))
+}
+
+// templateName extracts the quoted name argument of a template, block or
+// define action.
+func templateName(rest string) string {
+	if rest == "" {
+		return ""
+	}
+	end := 1
+	switch rest[0] {
+	case '"':
+		for end < len(rest) && rest[end] != '"' {
+			if rest[end] == '\\' {
+				end++
+			}
+			end++
+		}
+	case '`':
+		for end < len(rest) && rest[end] != '`' {
+			end++
+		}
+	default:
+		return ""
+	}
+	if end >= len(rest) {
+		return ""
+	}
+	name, err := strconv.Unquote(rest[:end+1])
+	if err != nil {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,439 @@
+package parser
+
+import (
+	"bytes"
+	"strings"
+)
+
+// EscapeState is the kind of HTML context a template action appears in, as
+// html/template's contextual autoescaper sees it. The escaper picks how to
+// encode the action's value from this context.
+type EscapeState int
+
+const (
+	// StateText is HTML text between tags.
+	StateText EscapeState = iota
+	// StateRCDATA is the text of a <title> or <textarea> element.
+	StateRCDATA
+	// StateComment is inside an HTML comment, which html/template strips.
+	StateComment
+	// StateTag is inside a start tag where an attribute name would go:
+	// <div {{.Attrs}}>.
+	StateTag
+	// StateAttrName is inside an attribute name: <div data-{{.Key}}="x">.
+	StateAttrName
+	// StateAttr is an attribute value with no special meaning.
+	StateAttr
+	// StateURL is the value of a URL attribute such as href or src.
+	StateURL
+	// StateJS is JavaScript: a <script> element or an on* event handler.
+	StateJS
+	// StateCSS is CSS: a <style> element or a style attribute.
+	StateCSS
+)
+
+func (s EscapeState) String() string {
+	switch s {
+	case StateText:
+		return "text"
+	case StateRCDATA:
+		return "rcdata"
+	case StateComment:
+		return "comment"
+	case StateTag:
+		return "tag"
+	case StateAttrName:
+		return "attribute name"
+	case StateAttr:
+		return "attribute"
+	case StateURL:
+		return "url"
+	case StateJS:
+		return "js"
+	case StateCSS:
+		return "css"
+	default:
+		return "unknown"
+	}
+}
+
+// URLPart is the part of a URL attribute value an action falls in.
+type URLPart int
+
+const (
+	// URLPartNone means nothing precedes the action in the value, so the
+	// action can set the URL's scheme.
+	URLPartNone URLPart = iota
+	// URLPartPreQuery is after the start of the URL but before any '?' or '#'.
+	URLPartPreQuery
+	// URLPartQueryOrFrag is in the query string or fragment.
+	URLPartQueryOrFrag
+)
+
+// EscapeContext describes where a template action sits in the surrounding
+// HTML.
+type EscapeContext struct {
+	State EscapeState
+	// Element is the lowercase name of the element whose tag or raw text
+	// (script, style, title, textarea) contains the action.
+	Element string
+	// Attr is the lowercase name of the attribute whose name or value
+	// contains the action.
+	Attr string
+	// Quote is the character quoting the attribute value, or 0 when the
+	// value is unquoted.
+	Quote byte
+	// URLPart is the part of the URL the action falls in, for StateURL.
+	URLPart URLPart
+	// JSQuote is the delimiter of the JavaScript string literal containing
+	// the action (', " or `), or 0 in code, for StateJS.
+	JSQuote byte
+}
+
+// InAttrValue reports whether the context is an attribute value.
+func (c EscapeContext) InAttrValue() bool {
+	switch c.State {
+	case StateAttr, StateURL:
+		return true
+	case StateJS, StateCSS:
+		return c.Attr != ""
+	}
+	return false
+}
+
+// urlAttrs are the attributes html/template escapes as URLs.
+var urlAttrs = map[string]bool{
+	"action": true, "archive": true, "background": true, "cite": true,
+	"classid": true, "codebase": true, "data": true, "formaction": true,
+	"href": true, "icon": true, "longdesc": true, "manifest": true,
+	"poster": true, "profile": true, "src": true, "srcset": true,
+	"usemap": true, "xmlns": true,
+}
+
+// attrState returns the context of a value of the named attribute, following
+// html/template's attribute classification.
+func attrState(name string) EscapeState {
+	name = strings.TrimPrefix(name, "data-")
+	if i := strings.IndexByte(name, ':'); i >= 0 {
+		if name[:i] == "xmlns" {
+			return StateURL
+		}
+		name = name[i+1:]
+	}
+	switch {
+	case strings.HasPrefix(name, "on"):
+		return StateJS
+	case name == "style":
+		return StateCSS
+	case urlAttrs[name], strings.Contains(name, "src"),
+		strings.Contains(name, "uri"), strings.Contains(name, "url"):
+		return StateURL
+	}
+	return StateAttr
+}
+
+// scanState is the position of contextScanner within the HTML grammar.
+type scanState int
+
+const (
+	scanText scanState = iota
+	scanRCDATA
+	scanRawText
+	scanComment
+	scanEndTag
+	scanDecl
+	scanTagName
+	scanTag
+	scanAttrName
+	scanAfterAttrName
+	scanBeforeValue
+	scanValue
+)
+
+// contextScanner tracks the HTML context through a template's text, skipping
+// over actions. It's a much smaller cousin of html/template's escaper: it
+// follows every branch in source order and doesn't report errors.
+type contextScanner struct {
+	content []byte
+	state   scanState
+	// element is the current tag, attr the current attribute
+	element, attr string
+	name          []byte
+	quote         byte
+	urlPart       URLPart
+	// jsQuote is the open JavaScript string delimiter; jsComment is '/' or
+	// '*' inside a line or block comment
+	jsQuote, jsComment byte
+	jsEscape           bool
+}
+
+// assignContexts sets the escape context of each action in content.
+func assignContexts(content []byte, actions []TemplateAction) {
+	s := &contextScanner{content: content}
+	pos := 0
+	for i := range actions {
+		a := &actions[i]
+		s.scan(pos, a.Span.Start.Offset)
+		a.Context = s.context()
+		// An output action stands for some unknown value
+		if a.Kind == ActionOutput {
+			s.value()
+		}
+		pos = a.Span.End.Offset
+	}
+}
+
+// scan advances through content[from:to].
+func (s *contextScanner) scan(from, to int) {
+	c := s.content[:to]
+	for i := from; i < len(c); {
+		i += s.step(c, i)
+	}
+}
+
+// step handles the byte at c[i] and returns how many bytes it consumed.
+// Transitions that consume nothing always change state, so the loop in scan
+// makes progress.
+func (s *contextScanner) step(c []byte, i int) int {
+	ch := c[i]
+	switch s.state {
+	case scanText:
+		if ch != '<' {
+			return 1
+		}
+		rest := c[i+1:]
+		switch {
+		case bytes.HasPrefix(rest, []byte("!--")):
+			s.state = scanComment
+			return 4
+		case len(rest) > 0 && isASCIILetter(rest[0]):
+			s.state, s.name = scanTagName, s.name[:0]
+		case len(rest) > 1 && rest[0] == '/' && isASCIILetter(rest[1]):
+			s.state = scanEndTag
+		case len(rest) > 0 && (rest[0] == '!' || rest[0] == '?'):
+			s.state = scanDecl
+		}
+		return 1
+
+	case scanComment:
+		if bytes.HasPrefix(c[i:], []byte("-->")) {
+			s.state = scanText
+			return 3
+		}
+		return 1
+
+	case scanEndTag, scanDecl:
+		if ch == '>' {
+			s.state = scanText
+		}
+		return 1
+
+	case scanRCDATA, scanRawText:
+		if ch == '<' && isEndTag(c[i:], s.element) {
+			s.state = scanEndTag
+			return 2
+		}
+		if s.state == scanRawText && s.element == "script" {
+			return s.jsStep(c, i)
+		}
+		return 1
+
+	case scanTagName:
+		if isTemplateSpace(ch) || ch == '/' || ch == '>' {
+			s.element = strings.ToLower(string(s.name))
+			s.state = scanTag
+			return 0
+		}
+		s.name = append(s.name, ch)
+		return 1
+
+	case scanTag:
+		switch {
+		case isTemplateSpace(ch) || ch == '/':
+			return 1
+		case ch == '>':
+			s.endStartTag()
+			return 1
+		}
+		s.state, s.name = scanAttrName, s.name[:0]
+		return 0
+
+	case scanAttrName:
+		switch {
+		case isTemplateSpace(ch):
+			s.attr, s.state = strings.ToLower(string(s.name)), scanAfterAttrName
+			return 1
+		case ch == '=':
+			s.attr, s.state = strings.ToLower(string(s.name)), scanBeforeValue
+			return 1
+		case ch == '/' || ch == '>':
+			s.attr, s.state = strings.ToLower(string(s.name)), scanTag
+			return 0
+		}
+		s.name = append(s.name, ch)
+		return 1
+
+	case scanAfterAttrName:
+		switch {
+		case isTemplateSpace(ch):
+			return 1
+		case ch == '=':
+			s.state = scanBeforeValue
+			return 1
+		}
+		s.state = scanTag
+		return 0
+
+	case scanBeforeValue:
+		switch {
+		case isTemplateSpace(ch):
+			return 1
+		case ch == '"' || ch == '\'':
+			s.startValue(ch)
+			return 1
+		case ch == '>':
+			s.state = scanTag
+			return 0
+		}
+		s.startValue(0)
+		return 0
+
+	case scanValue:
+		if s.quote != 0 && ch == s.quote {
+			s.state = scanTag
+			return 1
+		}
+		if s.quote == 0 && (isTemplateSpace(ch) || ch == '>') {
+			s.state = scanTag
+			return 0
+		}
+		switch attrState(s.attr) {
+		case StateURL:
+			s.urlByte(ch)
+		case StateJS:
+			return s.jsStep(c, i)
+		}
+		return 1
+	}
+	return 1
+}
+
+// value accounts for an output action's value at the current position.
+func (s *contextScanner) value() {
+	switch s.state {
+	case scanBeforeValue:
+		s.startValue(0)
+		s.value()
+	case scanValue:
+		if s.urlPart == URLPartNone {
+			s.urlPart = URLPartPreQuery
+		}
+	}
+}
+
+// endStartTag moves past the '>' of a start tag into the element's content.
+func (s *contextScanner) endStartTag() {
+	s.attr = ""
+	s.jsQuote, s.jsComment, s.jsEscape = 0, 0, false
+	switch s.element {
+	case "script", "style":
+		s.state = scanRawText
+	case "title", "textarea":
+		s.state = scanRCDATA
+	default:
+		s.state = scanText
+	}
+}
+
+func (s *contextScanner) startValue(quote byte) {
+	s.state, s.quote = scanValue, quote
+	s.urlPart = URLPartNone
+	s.jsQuote, s.jsComment, s.jsEscape = 0, 0, false
+}
+
+func (s *contextScanner) urlByte(ch byte) {
+	switch {
+	case ch == '?' || ch == '#':
+		s.urlPart = URLPartQueryOrFrag
+	case s.urlPart == URLPartNone && !isTemplateSpace(ch):
+		s.urlPart = URLPartPreQuery
+	}
+}
+
+// jsStep follows string literals and comments in JavaScript.
+func (s *contextScanner) jsStep(c []byte, i int) int {
+	ch := c[i]
+	switch {
+	case s.jsComment == '/':
+		if ch == '\n' {
+			s.jsComment = 0
+		}
+	case s.jsComment == '*':
+		if bytes.HasPrefix(c[i:], []byte("*/")) {
+			s.jsComment = 0
+			return 2
+		}
+	case s.jsQuote != 0:
+		switch {
+		case s.jsEscape:
+			s.jsEscape = false
+		case ch == '\\':
+			s.jsEscape = true
+		case ch == s.jsQuote:
+			s.jsQuote = 0
+		}
+	case ch == '"' || ch == '\'' || ch == '`':
+		s.jsQuote = ch
+	case ch == '/' && i+1 < len(c) && (c[i+1] == '/' || c[i+1] == '*'):
+		s.jsComment = c[i+1]
+		return 2
+	}
+	return 1
+}
+
+// context returns the escape context at the scanner's position.
+func (s *contextScanner) context() EscapeContext {
+	switch s.state {
+	case scanRCDATA:
+		return EscapeContext{State: StateRCDATA, Element: s.element}
+	case scanRawText:
+		if s.element == "style" {
+			return EscapeContext{State: StateCSS, Element: s.element}
+		}
+		return EscapeContext{State: StateJS, Element: s.element, JSQuote: s.jsQuote}
+	case scanComment:
+		return EscapeContext{State: StateComment}
+	case scanTagName:
+		return EscapeContext{State: StateTag, Element: strings.ToLower(string(s.name))}
+	case scanTag, scanAfterAttrName:
+		return EscapeContext{State: StateTag, Element: s.element}
+	case scanAttrName:
+		return EscapeContext{State: StateAttrName, Element: s.element, Attr: strings.ToLower(string(s.name))}
+	case scanBeforeValue:
+		return EscapeContext{State: attrState(s.attr), Element: s.element, Attr: s.attr}
+	case scanValue:
+		ctx := EscapeContext{State: attrState(s.attr), Element: s.element, Attr: s.attr, Quote: s.quote}
+		switch ctx.State {
+		case StateURL:
+			ctx.URLPart = s.urlPart
+		case StateJS:
+			ctx.JSQuote = s.jsQuote
+		}
+		return ctx
+	}
+	return EscapeContext{State: StateText}
+}
+
+// isEndTag reports whether c starts with the end tag of element, such as
+// "</script" followed by a space, '/' or '>'.
+func isEndTag(c []byte, element string) bool {
+	n := 2 + len(element)
+	if len(c) < n || c[1] != '/' || !strings.EqualFold(string(c[2:n]), element) {
+		return false
+	}
+	return len(c) == n || isTemplateSpace(c[n]) || c[n] == '/' || c[n] == '>'
+}
+
+func isASCIILetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
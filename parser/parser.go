@@ -13,19 +13,99 @@ import (
 type Document struct {
 	Root     *Node
 	Filename string
-	// IsTemplateFragment indicates file starts with {{define - a Go template partial
+	// IsTemplateFragment indicates file starts with a {{define}} action - a Go template partial
 	IsTemplateFragment bool
 	// sourceMap for converting positions back to original
 	sourceMap *SourceMap
 }
 
+// SourceMap returns the mapping from preprocessed HTML back to the original
+// template source.
+func (d *Document) SourceMap() *SourceMap {
+	return d.sourceMap
+}
+
+// TemplateActions returns the template actions in the original source, in
+// source order.
+func (d *Document) TemplateActions() []TemplateAction {
+	if d.sourceMap == nil {
+		return nil
+	}
+	return d.sourceMap.Actions
+}
+
+// ActionsIn returns the template actions that start within span, in source
+// order.
+func (d *Document) ActionsIn(span Span) []TemplateAction {
+	var actions []TemplateAction
+	for _, a := range d.TemplateActions() {
+		if a.Span.Start.Offset >= span.Start.Offset && a.Span.Start.Offset < span.End.Offset {
+			actions = append(actions, a)
+		}
+	}
+	return actions
+}
+
+// Templates returns the template set the document belongs to, or nil if
+// none was configured.
+func (d *Document) Templates() *TemplateSet {
+	if d.sourceMap == nil {
+		return nil
+	}
+	return d.sourceMap.Templates
+}
+
+// Delims returns the template delimiters the document was processed with.
+func (d *Document) Delims() Delims {
+	if d.sourceMap == nil {
+		return DefaultDelims
+	}
+	return d.sourceMap.Delims.OrDefault()
+}
+
 // Node wraps html.Node with source location and traversal helpers.
 type Node struct {
 	*html.Node
 	Line     int
 	Col      int
+	Offset   int // 0-indexed byte offset of the node's start
 	Parent   *Node
 	Children []*Node
+	// attrs holds the source location of each attribute, in source order
+	attrs []AttrSpan
+}
+
+// AttrPosition returns where the named attribute starts in the source.
+// Falls back to the element's own position when the attribute has no
+// recorded location (e.g. attributes added by the parser).
+func (n *Node) AttrPosition(name string) Position {
+	if a, ok := n.AttrSpan(name); ok {
+		return a.Key.Start
+	}
+	return Position{Offset: n.Offset, Line: n.Line, Col: n.Col}
+}
+
+// AttrSpan returns the source location of the first attribute with the
+// given name. Returns false if the attribute has no recorded location.
+func (n *Node) AttrSpan(name string) (AttrSpan, bool) {
+	for _, a := range n.attrs {
+		if strings.EqualFold(a.Name, name) {
+			return a, true
+		}
+	}
+	return AttrSpan{}, false
+}
+
+// AttrSpans returns the source locations of every attribute with the given
+// name, in source order. Duplicated attributes yield more than one span.
+func (n *Node) AttrSpans(name string) []AttrSpan {
+	var spans []AttrSpan
+	for _, a := range n.attrs {
+		if strings.EqualFold(a.Name, name) {
+			spans = append(spans, a)
+		}
+	}
+	return spans
 }
 
 // HasAttr checks if the node has an attribute with the given name.
@@ -103,9 +183,26 @@ func (n *Node) walk(fn WalkFunc) bool {
 
 // Parse parses HTML content and returns a Document with line tracking.
 func Parse(filename string, content []byte) (*Document, error) {
+	return NewPreprocessor().Parse(filename, content)
+}
+
+// ParseFragment parses an HTML fragment (like a template partial).
+func ParseFragment(filename string, content []byte) (*Document, error) {
+	return NewPreprocessor().ParseFragment(filename, content)
+}
+
+// ParseFragmentVariants parses up to maxVariants versions of a template
+// fragment, one per combination of template branches (see
+// Preprocessor.ProcessVariants). The first document takes the first branch
+// of every conditional.
+func ParseFragmentVariants(filename string, content []byte, maxVariants int) ([]*Document, error) {
+	return NewPreprocessor().ParseFragmentVariants(filename, content, maxVariants)
+}
+
+// Parse is like the package-level Parse, using p's delimiters.
+func (p *Preprocessor) Parse(filename string, content []byte) (*Document, error) {
 	// Preprocess to handle Go template syntax
-	prep := NewPreprocessor()
-	processed, sourceMap, err := prep.Process(content)
+	processed, sourceMap, err := p.Process(content)
 	if err != nil {
 		return nil, err
 	}
@@ -123,22 +220,45 @@ func Parse(filename string, content []byte) (*Document, error) {
 
 	// Build our node tree
 	doc.Root = buildNodeTree(root, nil)
+	doc.assignPositions(processed)
 
 	return doc, nil
 }
 
-// ParseFragment parses an HTML fragment (like a template partial).
-func ParseFragment(filename string, content []byte) (*Document, error) {
-	// Detect Go template fragments (files starting with {{define)
-	isTemplateFragment := bytes.HasPrefix(bytes.TrimSpace(content), []byte("{{define"))
-
+// ParseFragment is like the package-level ParseFragment, using p's delimiters.
+func (p *Preprocessor) ParseFragment(filename string, content []byte) (*Document, error) {
 	// Preprocess to handle Go template syntax
-	prep := NewPreprocessor()
-	processed, sourceMap, err := prep.Process(content)
+	_, sourceMap, err := p.Process(content)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseFragment(filename, content, sourceMap)
+}
+
+// ParseFragmentVariants is like the package-level ParseFragmentVariants,
+// using p's delimiters.
+func (p *Preprocessor) ParseFragmentVariants(filename string, content []byte, maxVariants int) ([]*Document, error) {
+	sourceMaps, err := p.ProcessVariants(content, maxVariants)
 	if err != nil {
 		return nil, err
 	}
 
+	docs := make([]*Document, 0, len(sourceMaps))
+	for _, sm := range sourceMaps {
+		doc, err := parseFragment(filename, content, sm)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// parseFragment parses preprocessed fragment content from a source map.
+func parseFragment(filename string, content []byte, sourceMap *SourceMap) (*Document, error) {
+	processed := sourceMap.Processed
+
 	// Create a context element for fragment parsing
 	context := &html.Node{
 		Type:     html.ElementNode,
@@ -154,7 +274,7 @@ func ParseFragment(filename string, content []byte) (*Document, error) {
 
 	doc := &Document{
 		Filename:           filename,
-		IsTemplateFragment: isTemplateFragment,
+		IsTemplateFragment: startsWithDefine(content, sourceMap.Actions),
 		sourceMap:          sourceMap,
 	}
 
@@ -171,24 +291,28 @@ func ParseFragment(filename string, content []byte) (*Document, error) {
 	}
 
 	doc.Root = syntheticRoot
+	doc.assignPositions(processed)
 	return doc, nil
 }
 
-// buildNodeTree converts html.Node tree to our Node tree.
-// Note: golang.org/x/net/html doesn't provide source positions,
-// so all nodes have line=1, col=1 as placeholders.
-func buildNodeTree(n *html.Node, parent *Node) *Node {
-	line, col := 1, 1
-	if parent != nil {
-		line = parent.Line
-		col = parent.Col
+// startsWithDefine reports whether the first thing in content is a
+// {{define}} action, marking the file as a Go template partial.
+func startsWithDefine(content []byte, actions []TemplateAction) bool {
+	if len(actions) == 0 || actions[0].Kind != ActionDefine {
+		return false
 	}
+	leading := len(content) - len(bytes.TrimLeft(content, " \t\r\n"))
+	return actions[0].Span.Start.Offset == leading
+}
 
+// buildNodeTree converts html.Node tree to our Node tree.
+// Positions are filled in afterwards by assignPositions.
+func buildNodeTree(n *html.Node, parent *Node) *Node {
 	node := &Node{
 		Node:   n,
 		Parent: parent,
-		Line:   line,
-		Col:    col,
+		Line:   1,
+		Col:    1,
 	}
 
 	// Process children
@@ -200,6 +324,18 @@ func buildNodeTree(n *html.Node, parent *Node) *Node {
 	return node
 }
 
+// assignPositions records source locations on every node.
+// golang.org/x/net/html doesn't expose positions, so the processed content is
+// tokenized separately and the tokens are matched against the tree. Offsets
+// are then mapped back through the source map to the original template.
+func (d *Document) assignPositions(processed []byte) {
+	m := &positionMatcher{
+		toks:     scanTokens(processed),
+		position: d.sourceMap.OriginalPositionFor,
+	}
+	m.assign(d.Root)
+}
+
 // ParseReader parses HTML from an io.Reader.
 func ParseReader(filename string, r io.Reader) (*Document, error) {
 	content, err := io.ReadAll(r)
@@ -0,0 +1,350 @@
+package parser
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Position is a location in source content.
+type Position struct {
+	Offset int // 0-indexed byte offset
+	Line   int // 1-indexed line number
+	Col    int // 1-indexed column (in bytes)
+}
+
+// Span is a range of source content. End is exclusive.
+type Span struct {
+	Start Position
+	End   Position
+}
+
+// AttrSpan is the source location of a single attribute.
+type AttrSpan struct {
+	Name  string // lowercase attribute name as written in the source
+	Span  Span   // whole attribute, from the name through the value
+	Key   Span   // attribute name
+	Value Span   // value without quotes; empty (Start == End) when absent
+}
+
+// ValueOrKey returns the value span, or the key span when the attribute has
+// no value. Useful for pointing at the part of an attribute that is wrong.
+func (a AttrSpan) ValueOrKey() Span {
+	if a.Value.Start.Offset == a.Value.End.Offset {
+		return a.Key
+	}
+	return a.Value
+}
+
+// lineIndex holds the byte offset at which each line starts, for converting
+// offsets into line and column numbers.
+type lineIndex []int
+
+func newLineIndex(content []byte) lineIndex {
+	idx := lineIndex{0}
+	for i, c := range content {
+		if c == '\n' {
+			idx = append(idx, i+1)
+		}
+	}
+	return idx
+}
+
+// position converts a byte offset into a Position.
+func (li lineIndex) position(offset int) Position {
+	// Find the last line starting at or before offset
+	line := sort.Search(len(li), func(i int) bool { return li[i] > offset }) - 1
+	if line < 0 {
+		line = 0
+	}
+	return Position{
+		Offset: offset,
+		Line:   line + 1,
+		Col:    offset - li[line] + 1,
+	}
+}
+
+// attrLoc records where an attribute's key and value appear in source.
+// Offsets are absolute byte offsets; valStart == valEnd for valueless attributes,
+// and end includes any closing quote.
+type attrLoc struct {
+	key              string
+	keyStart, keyEnd int
+	valStart, valEnd int
+	end              int
+}
+
+// sourceToken is a token from the source along with its location.
+type sourceToken struct {
+	offset int
+	data   string // lowercase tag name, text, or comment data
+	attrs  []attrLoc
+}
+
+// sourceTokens holds the start tags, text runs, comments and doctypes of a
+// document, each in source order.
+type sourceTokens struct {
+	tags     []sourceToken
+	texts    []sourceToken
+	comments []sourceToken
+	doctypes []sourceToken
+}
+
+// scanTokens tokenizes content, recording the offset of every token.
+// The tokenizer follows the same raw text rules as the parser, so tokens
+// line up with the nodes it produces.
+func scanTokens(content []byte) *sourceTokens {
+	toks := &sourceTokens{}
+	z := html.NewTokenizer(bytes.NewReader(content))
+	offset := 0
+
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		raw := z.Raw()
+		switch tt {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, _ := z.TagName()
+			tag := string(name)
+			if tag == "image" {
+				// The parser renames <image> to <img>
+				tag = "img"
+			}
+			toks.tags = append(toks.tags, sourceToken{
+				offset: offset,
+				data:   tag,
+				attrs:  scanAttrs(raw, offset),
+			})
+		case html.TextToken:
+			toks.texts = append(toks.texts, sourceToken{offset: offset, data: string(z.Text())})
+		case html.CommentToken:
+			toks.comments = append(toks.comments, sourceToken{offset: offset, data: string(z.Text())})
+		case html.DoctypeToken:
+			toks.doctypes = append(toks.doctypes, sourceToken{offset: offset})
+		}
+		offset += len(raw)
+	}
+
+	return toks
+}
+
+// scanAttrs finds attribute key and value offsets in the raw bytes of a start
+// tag. It mirrors the attribute reading of the html.Tokenizer, which does not
+// expose positions itself.
+func scanAttrs(raw []byte, base int) []attrLoc {
+	var attrs []attrLoc
+	i := 1 // skip '<'
+
+	isSpace := func(c byte) bool {
+		return c == ' ' || c == '\n' || c == '\r' || c == '\t' || c == '\f'
+	}
+	skipSpace := func() {
+		for i < len(raw) && isSpace(raw[i]) {
+			i++
+		}
+	}
+
+	// Tag name
+	for i < len(raw) && !isSpace(raw[i]) && raw[i] != '/' && raw[i] != '>' {
+		i++
+	}
+	skipSpace()
+
+	for i < len(raw) && raw[i] != '>' {
+		// Key
+		keyStart := i
+		for i < len(raw) {
+			c := raw[i]
+			if c == '=' && i == keyStart {
+				i++
+				continue
+			}
+			if isSpace(c) || c == '/' || c == '>' || c == '=' {
+				break
+			}
+			i++
+		}
+		keyEnd := i
+		valStart, valEnd, attrEnd := i, i, i
+
+		// Value
+		skipSpace()
+		switch {
+		case i < len(raw) && raw[i] == '/':
+			i++
+		case i < len(raw) && raw[i] == '=':
+			i++
+			skipSpace()
+			if i >= len(raw) || raw[i] == '>' {
+				break
+			}
+			if quote := raw[i]; quote == '"' || quote == '\'' {
+				i++
+				valStart = i
+				for i < len(raw) && raw[i] != quote {
+					i++
+				}
+				valEnd = i
+				if i < len(raw) {
+					i++
+				}
+				attrEnd = i
+			} else {
+				valStart = i
+				for i < len(raw) && !isSpace(raw[i]) && raw[i] != '>' {
+					i++
+				}
+				valEnd = i
+			}
+		}
+
+		if keyEnd > keyStart {
+			attrs = append(attrs, attrLoc{
+				key:      strings.ToLower(string(raw[keyStart:keyEnd])),
+				keyStart: base + keyStart,
+				keyEnd:   base + keyEnd,
+				valStart: base + valStart,
+				valEnd:   base + valEnd,
+				end:      base + max(keyEnd, valEnd, attrEnd),
+			})
+		}
+		skipSpace()
+	}
+
+	return attrs
+}
+
+// droppableTags are start tags the HTML parser may discard depending on the
+// insertion mode, such as <html> and <body> when parsing a fragment, or table
+// parts that appear outside a table.
+var droppableTags = map[string]bool{
+	"html": true, "head": true, "body": true, "frameset": true, "frame": true,
+	"caption": true, "col": true, "colgroup": true, "tbody": true, "thead": true,
+	"tfoot": true, "tr": true, "td": true, "th": true, "form": true,
+}
+
+// impliedTags are elements the parser inserts without a start tag. They only
+// match a token found exactly at the current position, so an implied <tbody>
+// can't claim an explicit one from a later table.
+var impliedTags = map[string]bool{
+	"html": true, "head": true, "body": true, "tbody": true, "tr": true, "colgroup": true,
+}
+
+// positionMatcher assigns source positions to parsed nodes by walking the
+// tree in document order and matching each node against the next unconsumed
+// token of the same kind. Nodes the parser synthesized (implied <tbody>,
+// reconstructed formatting elements) have no token of their own and are
+// placed at the next source token instead.
+type positionMatcher struct {
+	toks                        *sourceTokens
+	tag, text, comment, doctype int
+	position                    func(offset int) Position
+}
+
+func (m *positionMatcher) assign(n *Node) {
+	switch n.Type {
+	case html.ElementNode:
+		m.assignElement(n)
+	case html.TextNode:
+		m.assignText(n)
+	case html.CommentNode:
+		m.assignComment(n)
+	case html.DoctypeNode:
+		if m.doctype < len(m.toks.doctypes) {
+			m.setPosition(n, m.toks.doctypes[m.doctype].offset)
+			m.doctype++
+		} else {
+			m.inherit(n)
+		}
+	default:
+		m.inherit(n)
+	}
+
+	for _, child := range n.Children {
+		m.assign(child)
+	}
+}
+
+func (m *positionMatcher) assignElement(n *Node) {
+	for j := m.tag; j < len(m.toks.tags); j++ {
+		tok := m.toks.tags[j]
+		if strings.EqualFold(tok.data, n.Data) {
+			m.tag = j + 1
+			m.setPosition(n, tok.offset)
+			for _, a := range tok.attrs {
+				n.attrs = append(n.attrs, AttrSpan{
+					Name:  a.key,
+					Span:  m.span(a.keyStart, a.end),
+					Key:   m.span(a.keyStart, a.keyEnd),
+					Value: m.span(a.valStart, a.valEnd),
+				})
+			}
+			return
+		}
+		if impliedTags[n.Data] || !droppableTags[tok.data] {
+			break
+		}
+	}
+
+	// No token of its own: the parser created this element
+	if m.tag < len(m.toks.tags) {
+		m.setPosition(n, m.toks.tags[m.tag].offset)
+		return
+	}
+	m.inherit(n)
+}
+
+func (m *positionMatcher) assignText(n *Node) {
+	for j := m.text; j < len(m.toks.texts); j++ {
+		tok := m.toks.texts[j]
+		if tok.data != "" && (n.Data == tok.data ||
+			strings.HasPrefix(n.Data, tok.data) ||
+			strings.HasSuffix(tok.data, n.Data)) {
+			m.text = j + 1
+			m.setPosition(n, tok.offset)
+			return
+		}
+		// Only whitespace runs are discarded by the parser
+		if strings.TrimSpace(tok.data) != "" {
+			break
+		}
+	}
+	m.inherit(n)
+}
+
+func (m *positionMatcher) assignComment(n *Node) {
+	for j := m.comment; j < len(m.toks.comments); j++ {
+		if m.toks.comments[j].data == n.Data {
+			m.comment = j + 1
+			m.setPosition(n, m.toks.comments[j].offset)
+			return
+		}
+	}
+	m.inherit(n)
+}
+
+func (m *positionMatcher) setPosition(n *Node, offset int) {
+	pos := m.position(offset)
+	n.Offset = pos.Offset
+	n.Line = pos.Line
+	n.Col = pos.Col
+}
+
+func (m *positionMatcher) span(start, end int) Span {
+	return Span{Start: m.position(start), End: m.position(end)}
+}
+
+// inherit gives a node without a source token its parent's position.
+func (m *positionMatcher) inherit(n *Node) {
+	if n.Parent == nil {
+		n.Line, n.Col = 1, 1
+		return
+	}
+	n.Offset = n.Parent.Offset
+	n.Line = n.Parent.Line
+	n.Col = n.Parent.Col
+}
@@ -2,21 +2,9 @@
 package parser
 
 import (
-	"bytes"
-	"regexp"
+	"sort"
 )
 
-// templatePattern matches Go template syntax: {{ ... }}
-// Uses non-greedy matching to handle nested braces correctly.
-var templatePattern = regexp.MustCompile(`\{\{[\s\S]*?\}\}`)
-
-// ifElseEndPattern matches {{if ...}}...{{else}}...{{end}} blocks including nested.
-// Captures: full match with if-branch content kept, else-branch removed.
-var ifElseEndPattern = regexp.MustCompile(`(?s)\{\{-?\s*if\s[^}]*\}\}(.*?)\{\{-?\s*else\s*-?\}\}.*?\{\{-?\s*end\s*-?\}\}`)
-
-// ifEndPattern matches {{if ...}}...{{end}} blocks without else.
-var ifEndPattern = regexp.MustCompile(`(?s)\{\{-?\s*if\s[^}]*\}\}(.*?)\{\{-?\s*end\s*-?\}\}`)
-
 // SourceMap tracks the mapping between processed and original source positions.
 // Used to report errors at their original line/column locations.
 type SourceMap struct {
@@ -24,17 +12,124 @@ type SourceMap struct {
 	Original []byte
 	// Processed content with templates replaced
 	Processed []byte
+	// segments cover Processed in order, each mapping a run of bytes back
+	// to where it came from in Original
+	segments []segment
+	// Actions lists every template action in Original, in source order
+	Actions []TemplateAction
+	// Delims are the action delimiters used to process Original
+	Delims Delims
+	// Templates is the template set Original belongs to, if known
+	Templates *TemplateSet
+	// line indexes for converting offsets, built on first use
+	origLines, procLines lineIndex
+}
+
+// segment maps a run of processed bytes back to the original source.
+// Copied runs map byte for byte; synthetic runs (placeholders such as TMPL)
+// all map to the start of the template expression they replaced.
+type segment struct {
+	procStart int
+	origStart int
+	length    int
+	synthetic bool
+}
+
+// OriginalOffset converts a byte offset in processed content to the
+// corresponding offset in the original source.
+func (sm *SourceMap) OriginalOffset(offset int) int {
+	if len(sm.segments) == 0 {
+		return min(offset, len(sm.Original))
+	}
+	// Find the last segment starting at or before offset
+	i := sort.Search(len(sm.segments), func(i int) bool {
+		return sm.segments[i].procStart > offset
+	}) - 1
+	if i < 0 {
+		return 0
+	}
+	seg := sm.segments[i]
+	delta := offset - seg.procStart
+	if seg.synthetic {
+		return seg.origStart
+	}
+	if delta > seg.length {
+		// Past the end of processed content
+		delta = seg.length
+	}
+	return seg.origStart + delta
 }
 
-// OriginalPosition converts a position in processed content to original position.
+// OriginalPosition converts a line and column in processed content to the
+// corresponding line and column in the original source.
 func (sm *SourceMap) OriginalPosition(line, col int) (origLine, origCol int) {
-	// For now, line numbers are preserved since we only replace inline content
-	// Column offsets would need more sophisticated tracking for accuracy
-	return line, col
+	if sm.procLines == nil {
+		sm.procLines = newLineIndex(sm.Processed)
+	}
+	if line < 1 || line > len(sm.procLines) {
+		return line, col
+	}
+	pos := sm.OriginalPositionFor(sm.procLines[line-1] + col - 1)
+	return pos.Line, pos.Col
+}
+
+// OriginalPositionFor converts a byte offset in processed content to a
+// position in the original source.
+func (sm *SourceMap) OriginalPositionFor(offset int) Position {
+	return sm.Position(sm.OriginalOffset(offset))
+}
+
+// Position converts a byte offset in the original source to a Position.
+func (sm *SourceMap) Position(offset int) Position {
+	if sm.origLines == nil {
+		sm.origLines = newLineIndex(sm.Original)
+	}
+	return sm.origLines.position(offset)
+}
+
+// sourceBuilder accumulates processed content along with segments tracing
+// each byte back to the input it was built from.
+type sourceBuilder struct {
+	out  []byte
+	segs []segment
+}
+
+// copy appends bytes taken verbatim from the input at offset from.
+func (b *sourceBuilder) copy(data []byte, from int) {
+	b.add(data, from, false)
+}
+
+// synthesize appends generated bytes standing in for input at offset at.
+func (b *sourceBuilder) synthesize(data []byte, at int) {
+	b.add(data, at, true)
+}
+
+func (b *sourceBuilder) add(data []byte, from int, synthetic bool) {
+	if len(data) == 0 {
+		return
+	}
+	b.segs = append(b.segs, segment{
+		procStart: len(b.out),
+		origStart: from,
+		length:    len(data),
+		synthetic: synthetic,
+	})
+	b.out = append(b.out, data...)
 }
 
 // Preprocessor handles Go template syntax in HTML files.
-type Preprocessor struct{}
+type Preprocessor struct {
+	// Delims are the template action delimiters; the zero value means
+	// DefaultDelims.
+	Delims Delims
+	// Templates is the template set the input belongs to, if known. It is
+	// made available to rules through Document.Templates.
+	Templates *TemplateSet
+	// Inline renders the input as an entry-point page: {{template}} calls
+	// are replaced by the called templates, looked up in the input itself
+	// and then in Templates.
+	Inline bool
+}
 
 // NewPreprocessor creates a new template preprocessor.
 func NewPreprocessor() *Preprocessor {
@@ -43,80 +138,83 @@ func NewPreprocessor() *Preprocessor {
 
 // Process replaces Go template syntax with placeholders to produce valid HTML.
 // Returns the processed content and a source map for error location recovery.
+// The source map records every substitution, so any offset in the processed
+// content can be traced back to the original template source, and lists the
+// template actions found in the input.
+//
+// The template is parsed with text/template/parse and rendered as follows:
+//   - {{ .Field }} and other output actions → "TMPL" placeholder
+//   - {{if}}...{{else}}...{{end}} → content of the first branch only
+//   - {{range}} and {{with}} → body content, without any {{else}}
+//   - {{define}} and {{block}} bodies → rendered in place
+//   - {{template "name"}}, comments, variable declarations → removed
+//
+// With Inline set, only the main template is rendered and {{template}}
+// calls are replaced by the body of the named template; inlined content maps
+// to the position of the call.
 //
-// Replacement strategies:
-//   - {{ .Field }} in text content → empty string (preserves structure)
-//   - {{ .Field }} in attribute values → "tmpl" (keeps attribute valid)
-//   - {{if}}...{{else}}...{{end}} blocks → content of if-branch kept only
-//   - {{if}}...{{end}} blocks → content kept
-//   - {{range}}...{{end}} → single iteration content
-//   - {{template "name"}} → empty (included template not available)
+// Templates that don't parse (e.g. with unbalanced {{end}}) have their actions
+// stripped individually instead, keeping the content of every branch.
 func (p *Preprocessor) Process(input []byte) ([]byte, *SourceMap, error) {
-	sm := &SourceMap{
-		Original: input,
+	delims := p.Delims.OrDefault()
+	actions := ScanActions(input, delims)
+
+	var sm *SourceMap
+	if file, err := parseTemplateFile(input, delims, actions); err == nil {
+		sm = file.render(branchChoices{}, p.inlineFrom())
+	} else {
+		sm = stripActions(input, actions)
 	}
+	sm.Actions = actions
+	sm.Delims = delims
+	sm.Templates = p.Templates
 
-	// First, handle {{if}}...{{else}}...{{end}} blocks - keep only if-branch
-	processed := ifElseEndPattern.ReplaceAll(input, []byte("$1"))
-
-	// Then handle {{if}}...{{end}} without else - keep content
-	processed = ifEndPattern.ReplaceAll(processed, []byte("$1"))
-
-	// Replace remaining template expressions with appropriate placeholders
-	processed = templatePattern.ReplaceAllFunc(processed, func(match []byte) []byte {
-		return p.replaceTemplate(match)
-	})
-
-	sm.Processed = processed
-	return processed, sm, nil
+	return sm.Processed, sm, nil
 }
 
-// replaceTemplate determines the appropriate replacement for a template expression.
-func (p *Preprocessor) replaceTemplate(match []byte) []byte {
-	content := bytes.TrimSpace(match[2 : len(match)-2]) // Remove {{ and }}
-
-	// Handle different template constructs
-	switch {
-	case bytes.HasPrefix(content, []byte("/*")):
-		// Template comment: {{/* comment */}} → empty
-		return nil
-
-	case bytes.HasPrefix(content, []byte("if ")),
-		bytes.HasPrefix(content, []byte("if(")),
-		bytes.Equal(content, []byte("else")),
-		bytes.HasPrefix(content, []byte("else if")):
-		// Control flow: keep structure, remove directive
-		return nil
-
-	case bytes.HasPrefix(content, []byte("end")):
-		// End of block
-		return nil
-
-	case bytes.HasPrefix(content, []byte("range ")):
-		// Range loop start
-		return nil
-
-	case bytes.HasPrefix(content, []byte("template ")),
-		bytes.HasPrefix(content, []byte("block ")):
-		// Template inclusion
-		return nil
-
-	case bytes.HasPrefix(content, []byte("define ")):
-		// Template definition
-		return nil
-
-	case bytes.HasPrefix(content, []byte("with ")):
-		// With block
+// inlineFrom returns the set to inline {{template}} calls from, or nil when
+// calls are not inlined.
+func (p *Preprocessor) inlineFrom() *TemplateSet {
+	if !p.Inline {
 		return nil
+	}
+	if p.Templates == nil {
+		return NewTemplateSet()
+	}
+	return p.Templates
+}
 
-	case bytes.HasPrefix(content, []byte("-")):
-		// Whitespace trimming prefix
-		return nil
+// stripActions removes template actions from input without interpreting
+// control structures, replacing output actions with placeholders. Trim
+// markers are honoured so the surrounding whitespace matches what the
+// template would produce.
+func stripActions(input []byte, actions []TemplateAction) *SourceMap {
+	var b sourceBuilder
+	last := 0
+	for _, a := range actions {
+		text := input[last:a.Span.Start.Offset]
+		if a.TrimLeft {
+			for len(text) > 0 && isTemplateSpace(text[len(text)-1]) {
+				text = text[:len(text)-1]
+			}
+		}
+		b.copy(text, last)
+		if a.Kind == ActionOutput {
+			b.synthesize(placeholder, a.Span.Start.Offset)
+		}
+		last = a.Span.End.Offset
+		if a.TrimRight {
+			for last < len(input) && isTemplateSpace(input[last]) {
+				last++
+			}
+		}
+	}
+	b.copy(input[last:], last)
 
-	default:
-		// Variable or function call: {{ .Field }} or {{ func .arg }}
-		// Replace with placeholder that works in most contexts
-		return []byte("TMPL")
+	return &SourceMap{
+		Original:  input,
+		Processed: b.out,
+		segments:  b.segs,
 	}
 }
 
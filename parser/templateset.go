@@ -0,0 +1,100 @@
+package parser
+
+import (
+	"fmt"
+	"slices"
+	"text/template/parse"
+)
+
+// TemplateSet holds the named templates of several files, like the result of
+// template.ParseGlob. It lets an entry-point page be rendered with the
+// templates it calls inlined (see Preprocessor.Templates).
+type TemplateSet struct {
+	defs map[string]*TemplateDef
+	// refs counts the {{template}} calls to each name
+	refs map[string]int
+}
+
+// TemplateDef is a named template defined with {{define}} or {{block}}.
+type TemplateDef struct {
+	Name     string
+	Filename string
+	// Span is the location of the {{define}} or {{block}} action in Filename
+	Span Span
+	tree *parse.Tree
+}
+
+// NewTemplateSet creates an empty template set.
+func NewTemplateSet() *TemplateSet {
+	return &TemplateSet{
+		defs: make(map[string]*TemplateDef),
+		refs: make(map[string]int),
+	}
+}
+
+// Add parses a template file and adds its {{define}} and {{block}}
+// templates to the set. Later definitions replace earlier ones with the same
+// name, as they do in text/template.
+func (s *TemplateSet) Add(filename string, content []byte, delims Delims) error {
+	delims = delims.OrDefault()
+	actions := ScanActions(content, delims)
+	file, err := parseTemplateFile(content, delims, actions)
+	if err != nil {
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+
+	for _, a := range actions {
+		if a.Kind == ActionTemplate {
+			s.refs[a.Name]++
+		}
+		if a.Kind != ActionDefine && a.Kind != ActionBlock {
+			continue
+		}
+		tree := file.named[a.Name]
+		if tree == nil || tree == file.main {
+			continue
+		}
+		s.defs[a.Name] = &TemplateDef{
+			Name:     a.Name,
+			Filename: filename,
+			Span:     a.Span,
+			tree:     tree,
+		}
+	}
+	return nil
+}
+
+// Lookup returns the template with the given name.
+func (s *TemplateSet) Lookup(name string) (*TemplateDef, bool) {
+	if s == nil {
+		return nil, false
+	}
+	def, ok := s.defs[name]
+	return def, ok
+}
+
+// IsReferenced reports whether any file in the set calls the named template
+// with {{template}}.
+func (s *TemplateSet) IsReferenced(name string) bool {
+	return s != nil && s.refs[name] > 0
+}
+
+// Names returns the names of all templates in the set, sorted.
+func (s *TemplateSet) Names() []string {
+	if s == nil {
+		return nil
+	}
+	names := make([]string, 0, len(s.defs))
+	for name := range s.defs {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func (s *TemplateSet) lookup(name string) *parse.Tree {
+	if def, ok := s.Lookup(name); ok {
+		return def.tree
+	}
+	return nil
+}
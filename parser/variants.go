@@ -0,0 +1,360 @@
+package parser
+
+import (
+	"bytes"
+	"maps"
+	"slices"
+	"sort"
+	"text/template/parse"
+)
+
+// DefaultMaxVariants is the default cap on branch combinations linted per file.
+const DefaultMaxVariants = 16
+
+// placeholder is the text substituted for template actions that produce output.
+var placeholder = []byte("TMPL")
+
+// branchChoices records which branch to render at each {{if}}, {{range}} or
+// {{with}} node. Nodes without an entry render their first branch.
+type branchChoices map[parse.Node]int
+
+// templateFile is the parse tree of a single template file, including the
+// bodies of any {{define}} and {{block}} templates it contains.
+type templateFile struct {
+	content []byte
+	trees   []*parse.Tree
+	actions []TemplateAction
+	// main is the top-level template, outside any {{define}}
+	main *parse.Tree
+	// named holds the templates defined in the file by name
+	named map[string]*parse.Tree
+}
+
+// parseTemplateFile parses content with text/template/parse. Function names
+// are not checked since the FuncMap used at runtime isn't known here.
+// actions are the actions of content as found by ScanActions.
+func parseTemplateFile(content []byte, delims Delims, actions []TemplateAction) (*templateFile, error) {
+	t := parse.New("")
+	t.Mode = parse.ParseComments | parse.SkipFuncCheck
+	treeSet := make(map[string]*parse.Tree)
+	if _, err := t.Parse(string(content), delims.Left, delims.Right, treeSet); err != nil {
+		return nil, err
+	}
+
+	file := &templateFile{content: content, actions: actions, main: t, named: treeSet}
+	for _, tree := range treeSet {
+		file.trees = append(file.trees, tree)
+	}
+	// The main tree may be missing from the set if a define shares its name
+	if treeSet[""] != t {
+		file.trees = append(file.trees, t)
+	}
+	// Keep variant enumeration deterministic
+	sort.Slice(file.trees, func(i, j int) bool {
+		a, b := file.trees[i], file.trees[j]
+		if a.Root.Pos != b.Root.Pos {
+			return a.Root.Pos < b.Root.Pos
+		}
+		return a.Name < b.Name
+	})
+	return file, nil
+}
+
+// branches returns the alternative lists of a branching node, or nil if the
+// node does not branch. A missing {{else}} is not an alternative: its content
+// is always kept so the markup inside the branch gets linted.
+func branches(n parse.Node) []*parse.ListNode {
+	var b *parse.BranchNode
+	switch n := n.(type) {
+	case *parse.IfNode:
+		b = &n.BranchNode
+	case *parse.RangeNode:
+		b = &n.BranchNode
+	case *parse.WithNode:
+		b = &n.BranchNode
+	default:
+		return nil
+	}
+	if b.ElseList == nil {
+		return []*parse.ListNode{b.List}
+	}
+	return []*parse.ListNode{b.List, b.ElseList}
+}
+
+// variants enumerates up to maxVariants branch combinations. The first
+// variant always takes the first branch everywhere. When every combination
+// fits under the cap they are all returned; otherwise each alternative branch
+// is covered by at least one variant, as far as the cap allows.
+func (s *templateFile) variants(maxVariants int) []branchChoices {
+	if maxVariants < 1 {
+		maxVariants = 1
+	}
+
+	total := 1
+	for _, tree := range s.trees {
+		total = saturatingMul(total, countCombinations(tree.Root, maxVariants+1), maxVariants+1)
+	}
+
+	if total <= maxVariants {
+		all := []branchChoices{{}}
+		for _, tree := range s.trees {
+			all = crossChoices(all, listCombinations(tree.Root))
+		}
+		return all
+	}
+
+	result := []branchChoices{{}}
+	for _, tree := range s.trees {
+		coverBranches(tree.Root, branchChoices{}, &result)
+	}
+	if len(result) > maxVariants {
+		result = result[:maxVariants]
+	}
+	return result
+}
+
+// countCombinations counts the distinct branch combinations in a list,
+// saturating at limit.
+func countCombinations(list *parse.ListNode, limit int) int {
+	if list == nil {
+		return 1
+	}
+	total := 1
+	for _, n := range list.Nodes {
+		alts := branches(n)
+		if alts == nil {
+			continue
+		}
+		sum := 0
+		for _, alt := range alts {
+			sum = min(sum+countCombinations(alt, limit), limit)
+		}
+		total = saturatingMul(total, sum, limit)
+	}
+	return total
+}
+
+func saturatingMul(a, b, limit int) int {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	if a > limit/b {
+		return limit
+	}
+	return min(a*b, limit)
+}
+
+// listCombinations returns every branch combination in a list.
+func listCombinations(list *parse.ListNode) []branchChoices {
+	result := []branchChoices{{}}
+	if list == nil {
+		return result
+	}
+	for _, n := range list.Nodes {
+		alts := branches(n)
+		if alts == nil {
+			continue
+		}
+		var options []branchChoices
+		for i, alt := range alts {
+			for _, c := range listCombinations(alt) {
+				c[n] = i
+				options = append(options, c)
+			}
+		}
+		result = crossChoices(result, options)
+	}
+	return result
+}
+
+// crossChoices returns the cross product of two sets of combinations.
+func crossChoices(a, b []branchChoices) []branchChoices {
+	result := make([]branchChoices, 0, len(a)*len(b))
+	for _, x := range a {
+		for _, y := range b {
+			c := maps.Clone(x)
+			maps.Copy(c, y)
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// coverBranches appends one combination per alternative branch in list.
+// path holds the choices needed to reach list itself.
+func coverBranches(list *parse.ListNode, path branchChoices, result *[]branchChoices) {
+	if list == nil {
+		return
+	}
+	for _, n := range list.Nodes {
+		for i, alt := range branches(n) {
+			choice := maps.Clone(path)
+			choice[n] = i
+			if i > 0 {
+				*result = append(*result, choice)
+			}
+			coverBranches(alt, choice, result)
+		}
+	}
+}
+
+// piece is a run of rendered output along with where it came from.
+type piece struct {
+	data      []byte
+	offset    int
+	synthetic bool
+}
+
+// render produces HTML for one branch combination. Text is copied from the
+// source, actions that produce output become placeholders, and everything
+// else (control structures, comments, template calls) is dropped. Bodies of
+// {{define}} and {{block}} are rendered in place.
+//
+// When includes is non-nil the file is rendered the way it would execute
+// instead: only the main template is rendered, and each {{template}} call is
+// replaced by the named template, looked up first in the file itself and
+// then in includes.
+func (s *templateFile) render(choices branchChoices, includes *TemplateSet) *SourceMap {
+	r := &renderer{file: s, choices: choices, includes: includes, callSite: -1}
+	if includes == nil {
+		for _, tree := range s.trees {
+			r.renderList(tree.Root)
+		}
+	} else {
+		r.renderList(s.main.Root)
+	}
+	sort.SliceStable(r.pieces, func(i, j int) bool {
+		return r.pieces[i].offset < r.pieces[j].offset
+	})
+
+	var b sourceBuilder
+	for _, p := range r.pieces {
+		if p.synthetic {
+			b.synthesize(p.data, p.offset)
+		} else {
+			b.copy(p.data, p.offset)
+		}
+	}
+
+	return &SourceMap{
+		Original:  s.content,
+		Processed: b.out,
+		segments:  b.segs,
+	}
+}
+
+// renderer collects the output pieces of a templateFile.
+type renderer struct {
+	file     *templateFile
+	choices  branchChoices
+	includes *TemplateSet
+	pieces   []piece
+	// callSite is the offset of the {{template}} action whose body is being
+	// inlined, or -1 while rendering the file's own templates. Inlined output
+	// comes from other trees, so it all maps to the call site.
+	callSite int
+	// inlining holds the names of templates being inlined, to stop recursion
+	inlining []string
+}
+
+func (r *renderer) add(data []byte, offset int, synthetic bool) {
+	if r.callSite >= 0 {
+		offset, synthetic = r.callSite, true
+	}
+	r.pieces = append(r.pieces, piece{data: data, offset: offset, synthetic: synthetic})
+}
+
+func (r *renderer) renderList(list *parse.ListNode) {
+	if list == nil {
+		return
+	}
+	for _, n := range list.Nodes {
+		switch n := n.(type) {
+		case *parse.TextNode:
+			r.add(n.Text, int(n.Pos), false)
+		case *parse.ActionNode:
+			// Variable declarations produce no output
+			if len(n.Pipe.Decl) == 0 {
+				r.add(placeholder, r.file.actionStart(int(n.Pos)), true)
+			}
+		case *parse.TemplateNode:
+			if r.includes != nil {
+				r.inline(n)
+			}
+		default:
+			if alts := branches(n); alts != nil {
+				r.renderList(alts[r.choices[n]])
+			}
+		}
+	}
+}
+
+// inline renders the template called by n in place of the call.
+func (r *renderer) inline(n *parse.TemplateNode) {
+	if slices.Contains(r.inlining, n.Name) {
+		return
+	}
+	// Like a template set built with ParseGlob, all files share one
+	// namespace; the file's own definitions take precedence
+	tree := r.file.named[n.Name]
+	if tree == nil {
+		tree = r.includes.lookup(n.Name)
+	}
+	if tree == nil {
+		return
+	}
+
+	outer := r.callSite
+	if outer < 0 {
+		r.callSite = r.file.actionStart(int(n.Pos))
+	}
+	r.inlining = append(r.inlining, n.Name)
+	r.renderList(tree.Root)
+	r.inlining = r.inlining[:len(r.inlining)-1]
+	r.callSite = outer
+}
+
+// actionStart finds the opening delimiter of the action containing pos.
+// Parse tree positions point at the action's first token, not at the
+// delimiter.
+func (s *templateFile) actionStart(pos int) int {
+	i := sort.Search(len(s.actions), func(i int) bool {
+		return s.actions[i].Span.Start.Offset > pos
+	}) - 1
+	if i >= 0 && pos < s.actions[i].Span.End.Offset {
+		return s.actions[i].Span.Start.Offset
+	}
+	return pos
+}
+
+// ProcessVariants renders up to maxVariants versions of a template, each
+// taking a different combination of {{if}}/{{else}}, {{range}}/{{else}} and
+// {{with}}/{{else}} branches, so markup in every branch gets linted.
+// Falls back to the single result of Process when the template doesn't parse.
+func (p *Preprocessor) ProcessVariants(input []byte, maxVariants int) ([]*SourceMap, error) {
+	delims := p.Delims.OrDefault()
+	actions := ScanActions(input, delims)
+	file, err := parseTemplateFile(input, delims, actions)
+	if err != nil {
+		_, sm, err := p.Process(input)
+		if err != nil {
+			return nil, err
+		}
+		return []*SourceMap{sm}, nil
+	}
+
+	var result []*SourceMap
+	for _, choices := range file.variants(maxVariants) {
+		sm := file.render(choices, p.inlineFrom())
+		sm.Actions = actions
+		sm.Delims = delims
+		sm.Templates = p.Templates
+		// Different choices can render the same HTML (e.g. identical branches)
+		if !slices.ContainsFunc(result, func(prev *SourceMap) bool {
+			return bytes.Equal(prev.Processed, sm.Processed)
+		}) {
+			result = append(result, sm)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,120 @@
+package reporter
+
+import (
+	"encoding/xml"
+	"io"
+	"os"
+
+	"github.com/toba/go-html-validate/rules"
+)
+
+// Checkstyle outputs results as Checkstyle XML, which many CI servers and
+// code review tools read.
+type Checkstyle struct {
+	Writer io.Writer
+}
+
+// NewCheckstyle creates a Checkstyle reporter writing to stdout.
+func NewCheckstyle() *Checkstyle {
+	return &Checkstyle{Writer: os.Stdout}
+}
+
+type checkstyleOutput struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []checkstyleFile `xml:"file"`
+}
+
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+type checkstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Column   int    `xml:"column,attr,omitempty"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+// Report outputs results grouped by file, listing only files with results.
+func (c *Checkstyle) Report(results []rules.Result) error {
+	return c.ReportFiles(nil, results)
+}
+
+// ReportFiles outputs results grouped by file, listing every file linted so
+// clean files show as checked.
+func (c *Checkstyle) ReportFiles(files []string, results []rules.Result) error {
+	output := checkstyleOutput{Version: "4.3"}
+	for _, group := range groupByFile(files, results) {
+		file := checkstyleFile{Name: group.name}
+		for _, r := range group.results {
+			file.Errors = append(file.Errors, checkstyleError{
+				Line:     r.Line,
+				Column:   r.Col,
+				Severity: checkstyleSeverity(r.Severity),
+				Message:  r.Message,
+				Source:   "htmlint." + r.Rule,
+			})
+		}
+		output.Files = append(output.Files, file)
+	}
+	return writeXML(c.Writer, output)
+}
+
+// checkstyleSeverity maps a severity to a Checkstyle severity.
+func checkstyleSeverity(s rules.Severity) string {
+	switch s {
+	case rules.Error:
+		return "error"
+	case rules.Warning:
+		return "warning"
+	default:
+		return "info"
+	}
+}
+
+// fileResults are the results for one file.
+type fileResults struct {
+	name    string
+	results []rules.Result
+}
+
+// groupByFile groups results by file, with files in the order given and
+// then any others in the order their first result appears.
+func groupByFile(files []string, results []rules.Result) []fileResults {
+	var groups []fileResults
+	index := make(map[string]int)
+	add := func(name string) int {
+		i, ok := index[name]
+		if !ok {
+			i = len(groups)
+			index[name] = i
+			groups = append(groups, fileResults{name: name})
+		}
+		return i
+	}
+	for _, f := range files {
+		add(f)
+	}
+	for _, r := range results {
+		i := add(r.Filename)
+		groups[i].results = append(groups[i].results, r)
+	}
+	return groups
+}
+
+// writeXML writes v as an indented XML document.
+func writeXML(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,39 @@
+package reporter_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/toba/go-html-validate/reporter"
+	"github.com/toba/go-html-validate/rules"
+)
+
+func TestCheckstyle(t *testing.T) {
+	var buf bytes.Buffer
+	c := reporter.NewCheckstyle()
+	c.Writer = &buf
+	results := []rules.Result{
+		{Rule: rules.RuleImgAlt, Message: "<img> needs alt", Filename: "b.html", Line: 2, Col: 3, Severity: rules.Error},
+		{Rule: rules.RuleButtonType, Message: "missing type", Filename: "b.html", Line: 4, Col: 1, Severity: rules.Warning},
+		{Rule: rules.RuleNoInlineStyle, Message: "inline style", Filename: "c.html", Line: 1, Col: 1, Severity: rules.Info},
+	}
+	if err := c.ReportFiles([]string{"a.html", "b.html"}, results); err != nil {
+		t.Fatalf("ReportFiles() error = %v", err)
+	}
+
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<checkstyle version="4.3">
+  <file name="a.html"></file>
+  <file name="b.html">
+    <error line="2" column="3" severity="error" message="&lt;img&gt; needs alt" source="htmlint.img-alt"></error>
+    <error line="4" column="1" severity="warning" message="missing type" source="htmlint.button-type"></error>
+  </file>
+  <file name="c.html">
+    <error line="1" column="1" severity="info" message="inline style" source="htmlint.no-inline-style"></error>
+  </file>
+</checkstyle>
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
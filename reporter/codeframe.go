@@ -0,0 +1,143 @@
+package reporter
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/toba/go-html-validate/rules"
+)
+
+// DefaultCodeFrameContext is how many lines of source a CodeFrame shows
+// around each result when Context isn't set.
+const DefaultCodeFrameContext = 2
+
+// CodeFrame outputs each result followed by the source around it, with line
+// numbers in a gutter and the offending span underlined.
+type CodeFrame struct {
+	Writer    io.Writer
+	NoColor   bool
+	ShowRules bool // Include rule name in output
+	// Context is how many lines to show before and after a result's line;
+	// DefaultCodeFrameContext when zero, and none when negative
+	Context int
+	// ReadFile reads the linted files. Results in files it can't read are
+	// shown without source.
+	ReadFile func(path string) ([]byte, error)
+}
+
+// NewCodeFrame creates a code frame reporter writing to stdout.
+func NewCodeFrame() *CodeFrame {
+	return &CodeFrame{
+		Writer:    os.Stdout,
+		NoColor:   false,
+		ShowRules: true,
+		ReadFile:  os.ReadFile,
+	}
+}
+
+// SetSource sets the function reading linted files, replacing ReadFile.
+func (c *CodeFrame) SetSource(read func(path string) ([]byte, error)) {
+	c.ReadFile = read
+}
+
+// Report outputs results with their source, sorted by file and position.
+func (c *CodeFrame) Report(results []rules.Result) error {
+	if len(results) == 0 {
+		return nil
+	}
+
+	text := &Text{Writer: c.Writer, NoColor: c.NoColor, ShowRules: c.ShowRules}
+	src := newSources(c.ReadFile)
+	for _, r := range sortByPosition(results) {
+		_, _ = fmt.Fprintln(c.Writer, text.formatResult(r))
+		if frame := c.frame(src.lines(r.Filename), r); frame != "" {
+			_, _ = fmt.Fprint(c.Writer, frame)
+		}
+		_, _ = fmt.Fprintln(c.Writer)
+	}
+
+	if summary := text.summary(results); summary != "" {
+		_, _ = fmt.Fprintln(c.Writer, summary)
+	}
+	return nil
+}
+
+// frame renders the lines around r, marking its line with ">" and drawing
+// a caret under its column, or an underline under its span on that line.
+func (c *CodeFrame) frame(lines [][]byte, r rules.Result) string {
+	if r.Line < 1 || r.Line > len(lines) {
+		return ""
+	}
+	context := c.Context
+	if context == 0 {
+		context = DefaultCodeFrameContext
+	}
+	context = max(context, 0)
+	first := max(r.Line-context, 1)
+	last := min(r.Line+context, len(lines))
+	// A trailing newline doesn't start a line worth showing
+	if last == len(lines) && last > r.Line && len(lines[last-1]) == 0 {
+		last--
+	}
+	width := len(strconv.Itoa(last))
+
+	var b strings.Builder
+	for n := first; n <= last; n++ {
+		line := bytes.TrimRight(lines[n-1], "\r")
+		marker := "  "
+		if n == r.Line {
+			marker = c.paint("> ", r.Severity)
+		}
+		gutter := fmt.Sprintf("%*d |", width, n)
+		if len(line) == 0 {
+			fmt.Fprintf(&b, "%s%s\n", marker, gutter)
+		} else {
+			fmt.Fprintf(&b, "%s%s %s\n", marker, gutter, line)
+		}
+		if n == r.Line {
+			pad, mark := underline(line, r)
+			fmt.Fprintf(&b, "  %*s | %s%s\n", width, "", pad, c.paint(mark, r.Severity))
+		}
+	}
+	return b.String()
+}
+
+// underline returns the padding up to r's column on its line, keeping tabs
+// so it lines up, and the marks under r: a caret, or an underline to r's
+// end or the end of the line.
+func underline(line []byte, r rules.Result) (pad, mark string) {
+	start := min(max(r.Col, 1)-1, len(line))
+	end := start + 1
+	switch {
+	case r.EndLine == r.Line && r.EndCol > r.Col:
+		end = min(r.EndCol-1, len(line))
+	case r.EndLine > r.Line:
+		end = len(line)
+	}
+
+	var p strings.Builder
+	for _, ch := range string(line[:start]) {
+		if ch == '\t' {
+			p.WriteByte('\t')
+		} else {
+			p.WriteByte(' ')
+		}
+	}
+	if end-start <= 1 {
+		return p.String(), "^"
+	}
+	return p.String(), strings.Repeat("^", max(utf8.RuneCount(line[start:end]), 1))
+}
+
+// paint colorizes text for severity unless color is off.
+func (c *CodeFrame) paint(text string, severity rules.Severity) string {
+	if c.NoColor {
+		return text
+	}
+	return colorize(text, severity)
+}
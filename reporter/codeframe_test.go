@@ -0,0 +1,82 @@
+package reporter_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/toba/go-html-validate/reporter"
+	"github.com/toba/go-html-validate/rules"
+)
+
+func TestCodeFrame(t *testing.T) {
+	source := "<div>\n\t<p>é</p><img src=\"a.png\">\n</div>\n<main>\n<button>Go</button>\n</main>\n"
+	tests := []struct {
+		name    string
+		context int
+		result  rules.Result
+		want    string
+	}{
+		{
+			name: "span underlined after a tab and multibyte text",
+			result: rules.Result{
+				Rule: rules.RuleImgAlt, Message: "missing alt", Filename: "page.html",
+				Line: 2, Col: 11, EndLine: 2, EndCol: 28, Severity: rules.Error,
+			},
+			want: "page.html:2:11: error: missing alt [img-alt]\n" +
+				"  1 | <div>\n" +
+				"> 2 | \t<p>é</p><img src=\"a.png\">\n" +
+				"    | \t        ^^^^^^^^^^^^^^^^^\n" +
+				"  3 | </div>\n" +
+				"  4 | <main>\n" +
+				"\n" +
+				"Found 1 error(s)\n",
+		},
+		{
+			name:    "caret without an end, one line of context",
+			context: 1,
+			result: rules.Result{
+				Rule: rules.RuleButtonType, Message: "missing type", Filename: "page.html",
+				Line: 5, Col: 1, Severity: rules.Warning,
+			},
+			want: "page.html:5:1: warning: missing type [button-type]\n" +
+				"  4 | <main>\n" +
+				"> 5 | <button>Go</button>\n" +
+				"    | ^\n" +
+				"  6 | </main>\n" +
+				"\n" +
+				"Found 1 warning(s)\n",
+		},
+		{
+			name: "unreadable file",
+			result: rules.Result{
+				Rule: "parse-error", Message: "no such file", Filename: "missing.html",
+				Line: 1, Col: 1, Severity: rules.Error,
+			},
+			want: "missing.html:1:1: error: no such file [parse-error]\n" +
+				"\n" +
+				"Found 1 error(s)\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			c := reporter.NewCodeFrame()
+			c.Writer = &buf
+			c.NoColor = true
+			c.Context = tt.context
+			c.SetSource(func(path string) ([]byte, error) {
+				if path == "page.html" {
+					return []byte(source), nil
+				}
+				return nil, errors.New("not found")
+			})
+			if err := c.Report([]rules.Result{tt.result}); err != nil {
+				t.Fatalf("Report() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package reporter
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"regexp"
+
+	"github.com/toba/go-html-validate/rules"
+)
+
+// sources reads linted files a line at a time for reporters that show or
+// hash source text, reading each file once.
+type sources struct {
+	readFile func(path string) ([]byte, error)
+	files    map[string][][]byte
+}
+
+func newSources(readFile func(path string) ([]byte, error)) *sources {
+	return &sources{readFile: readFile, files: make(map[string][][]byte)}
+}
+
+// lines returns the lines of the file at path, without their newlines, or
+// nil if it can't be read.
+func (s *sources) lines(path string) [][]byte {
+	if l, ok := s.files[path]; ok {
+		return l
+	}
+	var l [][]byte
+	if s.readFile != nil {
+		if content, err := s.readFile(path); err == nil {
+			l = bytes.Split(content, []byte("\n"))
+		}
+	}
+	s.files[path] = l
+	return l
+}
+
+// line returns the text of a 1-based line of the file at path, or nil.
+func (s *sources) line(path string, line int) []byte {
+	l := s.lines(path)
+	if line < 1 || line > len(l) {
+		return nil
+	}
+	return l[line-1]
+}
+
+// fingerprinter identifies results in a way that survives unrelated edits,
+// for dashboards that track findings from run to run.
+type fingerprinter struct {
+	src *sources
+	// occurrences numbers results whose hashes would otherwise collide,
+	// such as the same problem on identical lines
+	occurrences map[string]int
+}
+
+func newFingerprinter(src *sources) *fingerprinter {
+	return &fingerprinter{src: src, occurrences: make(map[string]int)}
+}
+
+// linePosition matches the positions some messages point at, such as
+// "(first defined at line 12)".
+var linePosition = regexp.MustCompile(`\bline \d+`)
+
+// fingerprint hashes what a result is and the text of its line, not where
+// the line is, so findings keep their identity as lines are added above
+// them. Line numbers in the message are left out for the same reason.
+// Results must be fingerprinted in a stable order.
+func (f *fingerprinter) fingerprint(r rules.Result) string {
+	message := linePosition.ReplaceAllString(r.Message, "line")
+	sum := sha256.Sum256(fmt.Appendf(nil, "%s\x00%s\x00%s\x00%s",
+		filepath.ToSlash(r.Filename), r.Rule, message, bytes.TrimSpace(f.src.line(r.Filename, r.Line))))
+	hash := hex.EncodeToString(sum[:16])
+	f.occurrences[hash]++
+	return fmt.Sprintf("%s:%d", hash, f.occurrences[hash])
+}
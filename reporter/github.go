@@ -0,0 +1,67 @@
+package reporter
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/toba/go-html-validate/rules"
+)
+
+// GitHub outputs results as GitHub Actions workflow commands, which show up
+// as annotations on the lines of a pull request.
+type GitHub struct {
+	Writer io.Writer
+}
+
+// NewGitHub creates a GitHub Actions reporter writing to stdout.
+func NewGitHub() *GitHub {
+	return &GitHub{Writer: os.Stdout}
+}
+
+// Report outputs a ::error, ::warning or ::notice command per result.
+func (g *GitHub) Report(results []rules.Result) error {
+	for _, r := range results {
+		command := "notice"
+		switch r.Severity {
+		case rules.Error:
+			command = "error"
+		case rules.Warning:
+			command = "warning"
+		}
+
+		props := []string{"file=" + githubProperty(filepath.ToSlash(r.Filename))}
+		if r.Line > 0 {
+			props = append(props, fmt.Sprintf("line=%d", r.Line))
+			if r.Col > 0 {
+				props = append(props, fmt.Sprintf("col=%d", r.Col))
+			}
+			if r.EndLine > 0 {
+				props = append(props, fmt.Sprintf("endLine=%d", r.EndLine))
+				// Columns only span a single line
+				if r.EndLine == r.Line && r.EndCol > 0 {
+					props = append(props, fmt.Sprintf("endColumn=%d", r.EndCol))
+				}
+			}
+		}
+		props = append(props, "title="+githubProperty(r.Rule))
+
+		if _, err := fmt.Fprintf(g.Writer, "::%s %s::%s\n", command, strings.Join(props, ","), githubData(r.Message)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// githubData escapes a workflow command's message.
+func githubData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// githubProperty escapes a workflow command property value, which also
+// can't contain the separators between properties.
+func githubProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
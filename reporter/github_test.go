@@ -0,0 +1,55 @@
+package reporter_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/toba/go-html-validate/reporter"
+	"github.com/toba/go-html-validate/rules"
+)
+
+func TestGitHub(t *testing.T) {
+	tests := []struct {
+		name   string
+		result rules.Result
+		want   string
+	}{
+		{
+			name: "error with span",
+			result: rules.Result{
+				Rule: rules.RuleImgAlt, Message: "img element missing alt attribute", Filename: "web/page.html",
+				Line: 3, Col: 5, EndLine: 3, EndCol: 22, Severity: rules.Error,
+			},
+			want: "::error file=web/page.html,line=3,col=5,endLine=3,endColumn=22,title=img-alt::img element missing alt attribute\n",
+		},
+		{
+			name: "span over lines has no end column",
+			result: rules.Result{
+				Rule: rules.RuleButtonType, Message: "button missing type", Filename: "a.html",
+				Line: 1, Col: 1, EndLine: 2, EndCol: 4, Severity: rules.Warning,
+			},
+			want: "::warning file=a.html,line=1,col=1,endLine=2,title=button-type::button missing type\n",
+		},
+		{
+			name: "escaping",
+			result: rules.Result{
+				Rule: "parse-error", Message: "100% wrong\nhere", Filename: "a,b:c.html",
+				Line: 1, Col: 1, Severity: rules.Info,
+			},
+			want: "::notice file=a%2Cb%3Ac.html,line=1,col=1,title=parse-error::100%25 wrong%0Ahere\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			g := reporter.NewGitHub()
+			g.Writer = &buf
+			if err := g.Report([]rules.Result{tt.result}); err != nil {
+				t.Fatalf("Report() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got  %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package reporter
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/toba/go-html-validate/rules"
+)
+
+// GitLab outputs results as a GitLab Code Quality report, which shows up
+// in merge request widgets and diffs.
+type GitLab struct {
+	Writer io.Writer
+	// ReadFile reads the linted files, to fingerprint results by their
+	// source line so they keep their identity as lines move
+	ReadFile func(path string) ([]byte, error)
+}
+
+// NewGitLab creates a GitLab Code Quality reporter writing to stdout.
+func NewGitLab() *GitLab {
+	return &GitLab{
+		Writer:   os.Stdout,
+		ReadFile: os.ReadFile,
+	}
+}
+
+// GitLabIssue is a Code Quality issue.
+type GitLabIssue struct {
+	Description string         `json:"description"`
+	CheckName   string         `json:"check_name"`
+	Fingerprint string         `json:"fingerprint"`
+	Severity    string         `json:"severity"`
+	Location    GitLabLocation `json:"location"`
+}
+
+// GitLabLocation is where a Code Quality issue is.
+type GitLabLocation struct {
+	Path  string      `json:"path"`
+	Lines GitLabLines `json:"lines"`
+}
+
+// GitLabLines is the line a Code Quality issue starts on.
+type GitLabLines struct {
+	Begin int `json:"begin"`
+}
+
+// SetSource sets the function reading linted files, replacing ReadFile.
+func (g *GitLab) SetSource(read func(path string) ([]byte, error)) {
+	g.ReadFile = read
+}
+
+// Report outputs results as a JSON array of Code Quality issues.
+func (g *GitLab) Report(results []rules.Result) error {
+	fingerprints := newFingerprinter(newSources(g.ReadFile))
+	issues := make([]GitLabIssue, 0, len(results))
+	for _, r := range results {
+		issues = append(issues, GitLabIssue{
+			Description: r.Message,
+			CheckName:   r.Rule,
+			Fingerprint: fingerprints.fingerprint(r),
+			Severity:    gitlabSeverity(r.Severity),
+			Location: GitLabLocation{
+				Path:  filepath.ToSlash(r.Filename),
+				Lines: GitLabLines{Begin: max(r.Line, 1)},
+			},
+		})
+	}
+	return json.NewEncoder(g.Writer).Encode(issues)
+}
+
+// gitlabSeverity maps a severity to a Code Quality severity.
+func gitlabSeverity(s rules.Severity) string {
+	switch s {
+	case rules.Error:
+		return "major"
+	case rules.Warning:
+		return "minor"
+	default:
+		return "info"
+	}
+}
@@ -0,0 +1,78 @@
+package reporter_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/toba/go-html-validate/reporter"
+	"github.com/toba/go-html-validate/rules"
+)
+
+func TestGitLab(t *testing.T) {
+	var buf bytes.Buffer
+	g := reporter.NewGitLab()
+	g.Writer = &buf
+	g.ReadFile = func(string) ([]byte, error) { return []byte("<img src=\"a.png\">\n<img src=\"a.png\">\n"), nil }
+	results := []rules.Result{
+		{Rule: rules.RuleImgAlt, Message: "missing alt", Filename: "web/page.html", Line: 1, Col: 1, Severity: rules.Error},
+		{Rule: rules.RuleImgAlt, Message: "missing alt", Filename: "web/page.html", Line: 2, Col: 1, Severity: rules.Error},
+		{Rule: rules.RuleButtonType, Message: "missing type", Filename: "web/page.html", Line: 2, Col: 1, Severity: rules.Warning},
+	}
+	if err := g.Report(results); err != nil {
+		t.Fatalf("Report() error = %v", err)
+	}
+
+	var issues []reporter.GitLabIssue
+	if err := json.Unmarshal(buf.Bytes(), &issues); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if len(issues) != 3 {
+		t.Fatalf("got %d issues, want 3", len(issues))
+	}
+	first := issues[0]
+	if first.CheckName != rules.RuleImgAlt || first.Severity != "major" || first.Location.Path != "web/page.html" || first.Location.Lines.Begin != 1 {
+		t.Errorf("first issue = %+v", first)
+	}
+	if issues[2].Severity != "minor" {
+		t.Errorf("warning severity = %q, want minor", issues[2].Severity)
+	}
+	// GitLab needs fingerprints unique across the report
+	seen := make(map[string]bool)
+	for _, issue := range issues {
+		if seen[issue.Fingerprint] {
+			t.Errorf("duplicate fingerprint %q", issue.Fingerprint)
+		}
+		seen[issue.Fingerprint] = true
+	}
+
+	// Fingerprints don't change when lines are added above a problem whose
+	// message names another line
+	fingerprint := func(source string) string {
+		t.Helper()
+		var buf bytes.Buffer
+		g := reporter.NewGitLab()
+		g.Writer = &buf
+		g.ReadFile = func(string) ([]byte, error) { return []byte(source), nil }
+		if err := g.Report(lintRule(t, rules.RuleDuplicateID, source)); err != nil {
+			t.Fatalf("Report() error = %v", err)
+		}
+		var issues []reporter.GitLabIssue
+		if err := json.Unmarshal(buf.Bytes(), &issues); err != nil {
+			t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+		}
+		return issues[0].Fingerprint
+	}
+	if before, after := fingerprint(shiftSource), fingerprint("\n\n"+shiftSource); before != after {
+		t.Errorf("fingerprint changed from %s to %s after a line shift", before, after)
+	}
+
+	// No results is an empty array, not null
+	buf.Reset()
+	if err := g.Report(nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != "[]\n" {
+		t.Errorf("empty report = %q, want []", got)
+	}
+}
@@ -24,12 +24,14 @@ func NewJSON() *JSON {
 
 // JSONResult is the JSON representation of a lint result.
 type JSONResult struct {
-	Rule     string `json:"rule"`
-	Message  string `json:"message"`
-	Filename string `json:"filename"`
-	Line     int    `json:"line"`
-	Column   int    `json:"column"`
-	Severity string `json:"severity"`
+	Rule      string `json:"rule"`
+	Message   string `json:"message"`
+	Filename  string `json:"filename"`
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+	EndLine   int    `json:"endLine,omitempty"`
+	EndColumn int    `json:"endColumn,omitempty"`
+	Severity  string `json:"severity"`
 }
 
 // JSONOutput is the top-level JSON structure.
@@ -54,12 +56,14 @@ func (j *JSON) Report(results []rules.Result) error {
 
 	for _, r := range results {
 		output.Results = append(output.Results, JSONResult{
-			Rule:     r.Rule,
-			Message:  r.Message,
-			Filename: r.Filename,
-			Line:     r.Line,
-			Column:   r.Col,
-			Severity: r.Severity.String(),
+			Rule:      r.Rule,
+			Message:   r.Message,
+			Filename:  r.Filename,
+			Line:      r.Line,
+			Column:    r.Col,
+			EndLine:   r.EndLine,
+			EndColumn: r.EndCol,
+			Severity:  r.Severity.String(),
 		})
 
 		output.Summary.Total++
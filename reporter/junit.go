@@ -0,0 +1,110 @@
+package reporter
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/toba/go-html-validate/rules"
+)
+
+// JUnit outputs results as JUnit XML, for CI servers that only show test
+// results. Each file is a test suite and each rule a test case, failing
+// when the rule found problems in the file.
+type JUnit struct {
+	Writer io.Writer
+	// Rules are the rules that were checked, one test case each; every
+	// registered rule when empty
+	Rules []string
+}
+
+// NewJUnit creates a JUnit reporter writing to stdout.
+func NewJUnit() *JUnit {
+	return &JUnit{Writer: os.Stdout}
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",cdata"`
+}
+
+// Report outputs a test suite for each file with results.
+func (j *JUnit) Report(results []rules.Result) error {
+	return j.ReportFiles(nil, results)
+}
+
+// ReportFiles outputs a test suite for every file linted, so clean files
+// show up as passing.
+func (j *JUnit) ReportFiles(files []string, results []rules.Result) error {
+	ruleNames := j.Rules
+	if len(ruleNames) == 0 {
+		for _, rule := range rules.NewRegistry().All() {
+			ruleNames = append(ruleNames, rule.Name())
+		}
+	}
+
+	output := junitTestSuites{Name: "htmlint"}
+	for _, group := range groupByFile(files, results) {
+		byRule := make(map[string][]rules.Result)
+		names := slices.Clone(ruleNames)
+		for _, r := range group.results {
+			// Parse errors and the like aren't among the rules checked
+			if _, ok := byRule[r.Rule]; !ok && !slices.Contains(names, r.Rule) {
+				names = append(names, r.Rule)
+			}
+			byRule[r.Rule] = append(byRule[r.Rule], r)
+		}
+
+		suite := junitTestSuite{Name: group.name}
+		for _, name := range names {
+			tc := junitTestCase{Name: name, ClassName: group.name}
+			if found := byRule[name]; len(found) > 0 {
+				var text strings.Builder
+				severity := rules.Info
+				for _, r := range found {
+					fmt.Fprintf(&text, "%s:%d:%d: %s: %s\n", r.Filename, r.Line, r.Col, r.Severity, r.Message)
+					// Lower severities are more severe
+					severity = min(severity, r.Severity)
+				}
+				tc.Failure = &junitFailure{
+					Message: fmt.Sprintf("%d problem(s)", len(found)),
+					Type:    severity.String(),
+					Text:    text.String(),
+				}
+				suite.Failures++
+			}
+			suite.TestCases = append(suite.TestCases, tc)
+		}
+		suite.Tests = len(suite.TestCases)
+		output.Tests += suite.Tests
+		output.Failures += suite.Failures
+		output.Suites = append(output.Suites, suite)
+	}
+	return writeXML(j.Writer, output)
+}
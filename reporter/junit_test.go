@@ -0,0 +1,70 @@
+package reporter_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"slices"
+	"testing"
+
+	"github.com/toba/go-html-validate/reporter"
+	"github.com/toba/go-html-validate/rules"
+)
+
+func TestJUnit(t *testing.T) {
+	var buf bytes.Buffer
+	j := reporter.NewJUnit()
+	j.Writer = &buf
+	j.Rules = []string{rules.RuleImgAlt, rules.RuleButtonType}
+	results := []rules.Result{
+		{Rule: rules.RuleImgAlt, Message: "missing alt", Filename: "page.html", Line: 1, Col: 1, Severity: rules.Error},
+		{Rule: rules.RuleImgAlt, Message: "missing alt", Filename: "page.html", Line: 2, Col: 1, Severity: rules.Error},
+		{Rule: "parse-error", Message: "bad", Filename: "page.html", Line: 1, Col: 1, Severity: rules.Error},
+	}
+	if err := j.ReportFiles([]string{"clean.html", "page.html"}, results); err != nil {
+		t.Fatalf("ReportFiles() error = %v", err)
+	}
+
+	var got struct {
+		Tests    int `xml:"tests,attr"`
+		Failures int `xml:"failures,attr"`
+		Suites   []struct {
+			Name      string `xml:"name,attr"`
+			Tests     int    `xml:"tests,attr"`
+			Failures  int    `xml:"failures,attr"`
+			TestCases []struct {
+				Name    string `xml:"name,attr"`
+				Failure *struct {
+					Message string `xml:"message,attr"`
+					Text    string `xml:",chardata"`
+				} `xml:"failure"`
+			} `xml:"testcase"`
+		} `xml:"testsuite"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, buf.String())
+	}
+
+	if got.Tests != 5 || got.Failures != 2 || len(got.Suites) != 2 {
+		t.Fatalf("%d tests, %d failures in %d suites:\n%s", got.Tests, got.Failures, len(got.Suites), buf.String())
+	}
+	// A clean file passes every rule
+	clean := got.Suites[0]
+	if clean.Name != "clean.html" || clean.Tests != 2 || clean.Failures != 0 {
+		t.Errorf("clean suite = %+v", clean)
+	}
+	page := got.Suites[1]
+	var names []string
+	for _, tc := range page.TestCases {
+		names = append(names, tc.Name)
+	}
+	if want := []string{rules.RuleImgAlt, rules.RuleButtonType, "parse-error"}; !slices.Equal(names, want) {
+		t.Errorf("page test cases %v, want %v", names, want)
+	}
+	imgAlt := page.TestCases[0].Failure
+	if imgAlt == nil || imgAlt.Message != "2 problem(s)" || imgAlt.Text != "page.html:1:1: error: missing alt\npage.html:2:1: error: missing alt\n" {
+		t.Errorf("img-alt failure = %+v", imgAlt)
+	}
+	if page.TestCases[1].Failure != nil {
+		t.Errorf("button-type failed: %+v", page.TestCases[1].Failure)
+	}
+}
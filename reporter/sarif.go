@@ -0,0 +1,218 @@
+package reporter
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"unicode/utf16"
+
+	"github.com/toba/go-html-validate/rules"
+)
+
+// sarifHelpURI documents the rules, for SARIF viewers to link to.
+const sarifHelpURI = "https://github.com/toba/go-html-validate#rule-categories"
+
+// SARIF outputs results as a SARIF 2.1.0 log, for code scanning dashboards.
+type SARIF struct {
+	Writer io.Writer
+	// Version is the htmlint version recorded as the tool's version
+	Version string
+	// ReadFile reads the linted files, to convert columns to the UTF-16
+	// code units SARIF counts and to fingerprint results by their source
+	// line. Files it can't read keep byte columns.
+	ReadFile func(path string) ([]byte, error)
+}
+
+// NewSARIF creates a SARIF reporter writing to stdout.
+func NewSARIF() *SARIF {
+	return &SARIF{
+		Writer:   os.Stdout,
+		ReadFile: os.ReadFile,
+	}
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	HelpURI              string             `json:"helpUri"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+// sarifFingerprint is the partialFingerprints key of the results' hash,
+// versioned in case what goes into it ever changes.
+const sarifFingerprint = "htmlintHash/v1"
+
+// SetSource sets the function reading linted files, replacing ReadFile.
+func (s *SARIF) SetSource(read func(path string) ([]byte, error)) {
+	s.ReadFile = read
+}
+
+// Report outputs results as a SARIF log with one run.
+func (s *SARIF) Report(results []rules.Result) error {
+	registry := rules.NewRegistry()
+	driver := sarifDriver{
+		Name:           "htmlint",
+		Version:        s.Version,
+		InformationURI: "https://github.com/toba/go-html-validate",
+		Rules:          make([]sarifRule, 0, len(registry.All())),
+	}
+	ruleIndex := make(map[string]int)
+	for _, rule := range registry.All() {
+		ruleIndex[rule.Name()] = len(driver.Rules)
+		driver.Rules = append(driver.Rules, sarifRule{
+			ID:                   rule.Name(),
+			ShortDescription:     sarifMessage{Text: rule.Description()},
+			HelpURI:              sarifHelpURI,
+			DefaultConfiguration: sarifConfiguration{Level: sarifLevel(rules.DefaultSeverity(rule))},
+		})
+	}
+
+	src := newSources(s.ReadFile)
+	fingerprints := newFingerprinter(src)
+	run := sarifRun{Tool: sarifTool{Driver: driver}, Results: make([]sarifResult, 0, len(results))}
+	for _, r := range results {
+		index, ok := ruleIndex[r.Rule]
+		if !ok {
+			// Parse errors and the like aren't registry rules
+			index = len(run.Tool.Driver.Rules)
+			ruleIndex[r.Rule] = index
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+				ID:                   r.Rule,
+				ShortDescription:     sarifMessage{Text: r.Rule},
+				HelpURI:              sarifHelpURI,
+				DefaultConfiguration: sarifConfiguration{Level: sarifLevel(r.Severity)},
+			})
+		}
+
+		lines := src.lines(r.Filename)
+		region := sarifRegion{StartLine: max(r.Line, 1), StartColumn: utf16Column(lines, r.Line, r.Col)}
+		if r.EndLine > 0 {
+			region.EndLine = r.EndLine
+			region.EndColumn = utf16Column(lines, r.EndLine, r.EndCol)
+		}
+
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    r.Rule,
+			RuleIndex: index,
+			Level:     sarifLevel(r.Severity),
+			Message:   sarifMessage{Text: r.Message},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: sarifURI(r.Filename)},
+				Region:           region,
+			}}},
+			PartialFingerprints: map[string]string{
+				sarifFingerprint: fingerprints.fingerprint(r),
+			},
+		})
+	}
+
+	encoder := json.NewEncoder(s.Writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	})
+}
+
+// sarifLevel maps a severity to a SARIF result level.
+func sarifLevel(s rules.Severity) string {
+	switch s {
+	case rules.Error:
+		return "error"
+	case rules.Warning:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// sarifURI returns path as a URI reference: relative paths stay relative,
+// to be resolved against the repository root, and absolute paths become
+// file URIs.
+func sarifURI(path string) string {
+	u := url.URL{Path: filepath.ToSlash(path)}
+	if filepath.IsAbs(path) {
+		u.Scheme = "file"
+		if u.Path[0] != '/' {
+			// Windows drive letters
+			u.Path = "/" + u.Path
+		}
+	}
+	return u.String()
+}
+
+// utf16Column converts a 1-based byte column on a 1-based line to the
+// UTF-16 code units SARIF counts columns in. Without the source, or for a
+// column past the line, the byte column is kept.
+func utf16Column(src [][]byte, line, col int) int {
+	if col <= 0 || line < 1 || line > len(src) || col-1 > len(src[line-1]) {
+		return col
+	}
+	units := 1
+	for _, r := range string(src[line-1][:col-1]) {
+		units += utf16.RuneLen(r)
+	}
+	return units
+}
@@ -0,0 +1,149 @@
+package reporter_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/toba/go-html-validate/linter"
+	"github.com/toba/go-html-validate/reporter"
+	"github.com/toba/go-html-validate/rules"
+)
+
+// sarifLog is the part of a SARIF log the tests look at.
+type sarifLog struct {
+	Version string `json:"version"`
+	Runs    []struct {
+		Tool struct {
+			Driver struct {
+				Rules []struct {
+					ID                   string `json:"id"`
+					DefaultConfiguration struct {
+						Level string `json:"level"`
+					} `json:"defaultConfiguration"`
+				} `json:"rules"`
+			} `json:"driver"`
+		} `json:"tool"`
+		Results []struct {
+			RuleID    string `json:"ruleId"`
+			RuleIndex int    `json:"ruleIndex"`
+			Level     string `json:"level"`
+			Locations []struct {
+				PhysicalLocation struct {
+					ArtifactLocation struct {
+						URI string `json:"uri"`
+					} `json:"artifactLocation"`
+					Region struct {
+						StartLine   int `json:"startLine"`
+						StartColumn int `json:"startColumn"`
+						EndLine     int `json:"endLine"`
+						EndColumn   int `json:"endColumn"`
+					} `json:"region"`
+				} `json:"physicalLocation"`
+			} `json:"locations"`
+			PartialFingerprints map[string]string `json:"partialFingerprints"`
+		} `json:"results"`
+	} `json:"runs"`
+}
+
+func reportSARIF(t *testing.T, source string, results []rules.Result) sarifLog {
+	t.Helper()
+	var buf bytes.Buffer
+	s := reporter.NewSARIF()
+	s.Writer = &buf
+	s.ReadFile = func(string) ([]byte, error) { return []byte(source), nil }
+	if err := s.Report(results); err != nil {
+		t.Fatalf("Report() error = %v", err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	return log
+}
+
+func TestSARIF(t *testing.T) {
+	imgAlt := rules.Result{
+		Rule: rules.RuleImgAlt, Message: "img element missing alt attribute",
+		Filename: "web/my page.html", Severity: rules.Error,
+	}
+
+	at := func(r rules.Result, line, col, endLine, endCol int) rules.Result {
+		r.Line, r.Col, r.EndLine, r.EndCol = line, col, endLine, endCol
+		return r
+	}
+	line := "<p>é</p><img src=\"a.png\">\n"
+	log := reportSARIF(t, line+line, []rules.Result{at(imgAlt, 1, 10, 1, 27), at(imgAlt, 2, 10, 0, 0)})
+
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("version %q with %d runs", log.Version, len(log.Runs))
+	}
+	run := log.Runs[0]
+	if got, want := len(run.Tool.Driver.Rules), len(rules.NewRegistry().All()); got != want {
+		t.Errorf("driver has %d rules, want %d", got, want)
+	}
+	if len(run.Results) != 2 {
+		t.Fatalf("got %d results, want 2", len(run.Results))
+	}
+	first := run.Results[0]
+	rule := run.Tool.Driver.Rules[first.RuleIndex]
+	if rule.ID != rules.RuleImgAlt || rule.DefaultConfiguration.Level != "error" || first.Level != "error" {
+		t.Errorf("result rule %q level %q points at rule %+v", first.RuleID, first.Level, rule)
+	}
+	loc := first.Locations[0].PhysicalLocation
+	if loc.ArtifactLocation.URI != "web/my%20page.html" {
+		t.Errorf("uri = %q", loc.ArtifactLocation.URI)
+	}
+	// é is two bytes but one UTF-16 code unit
+	if r := loc.Region; r.StartLine != 1 || r.StartColumn != 9 || r.EndLine != 1 || r.EndColumn != 26 {
+		t.Errorf("region = %+v, want 1:9-1:26", r)
+	}
+	// Identical problems on identical lines are told apart
+	if first.PartialFingerprints["htmlintHash/v1"] == run.Results[1].PartialFingerprints["htmlintHash/v1"] {
+		t.Error("identical lines share a fingerprint")
+	}
+
+	// Adding lines above a problem moves it without changing its fingerprint
+	shifted := reportSARIF(t, "<h1>Title</h1>\n\n<p>é</p><img src=\"a.png\">\n",
+		[]rules.Result{at(imgAlt, 3, 10, 3, 27)})
+	if got, want := shifted.Runs[0].Results[0].PartialFingerprints, first.PartialFingerprints; got["htmlintHash/v1"] != want["htmlintHash/v1"] {
+		t.Errorf("fingerprint changed from %v to %v after a line shift", want, got)
+	}
+
+	// Messages pointing at other lines don't tie the fingerprint to them
+	for _, rule := range []string{rules.RuleDuplicateID, rules.RuleUniqueLandmark} {
+		before := lintRule(t, rule, shiftSource)
+		after := lintRule(t, rule, "\n\n"+shiftSource)
+		got := reportSARIF(t, "\n\n"+shiftSource, after).Runs[0].Results[0].PartialFingerprints["htmlintHash/v1"]
+		want := reportSARIF(t, shiftSource, before).Runs[0].Results[0].PartialFingerprints["htmlintHash/v1"]
+		if got != want {
+			t.Errorf("%s fingerprint changed from %s to %s after a line shift", rule, want, got)
+		}
+	}
+}
+
+// shiftSource has problems whose messages name the line of an earlier
+// element.
+const shiftSource = `<nav aria-label="Main"><p id="a">A</p></nav>
+<nav aria-label="Main"><p id="a">B</p></nav>
+`
+
+// lintRule lints source as web/page.html, returning the results of rule,
+// which must find something.
+func lintRule(t *testing.T, rule, source string) []rules.Result {
+	t.Helper()
+	results, err := linter.New(nil).LintContent("web/page.html", []byte(source))
+	if err != nil {
+		t.Fatalf("LintContent() error = %v", err)
+	}
+	var found []rules.Result
+	for _, r := range results {
+		if r.Rule == rule {
+			found = append(found, r)
+		}
+	}
+	if len(found) == 0 {
+		t.Fatalf("no %s in %v", rule, results)
+	}
+	return found
+}
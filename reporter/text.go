@@ -32,6 +32,66 @@ func (t *Text) Report(results []rules.Result) error {
 		return nil
 	}
 
+	for _, r := range sortByPosition(results) {
+		line := t.formatResult(r)
+		_, _ = fmt.Fprintln(t.Writer, line)
+	}
+
+	// Summary
+	_, _ = fmt.Fprintln(t.Writer)
+	if summary := t.summary(results); summary != "" {
+		_, _ = fmt.Fprintln(t.Writer, summary)
+	}
+
+	return nil
+}
+
+// ReportDelta outputs the results that appeared, marked "+", and the results
+// that went away, marked "-", followed by a summary of all results.
+func (t *Text) ReportDelta(added, removed, all []rules.Result) error {
+	for _, r := range added {
+		_, _ = fmt.Fprintln(t.Writer, "+ "+t.formatResult(r))
+	}
+	for _, r := range removed {
+		_, _ = fmt.Fprintln(t.Writer, "- "+t.formatResult(r))
+	}
+
+	summary := t.summary(all)
+	if summary == "" {
+		summary = "No problems found"
+	}
+	_, _ = fmt.Fprintln(t.Writer, summary)
+	return nil
+}
+
+// summary counts errors and warnings, returning "" when there are none.
+func (t *Text) summary(results []rules.Result) string {
+	errorCount := 0
+	warningCount := 0
+	for _, r := range results {
+		switch r.Severity {
+		case rules.Error:
+			errorCount++
+		case rules.Warning:
+			warningCount++
+		}
+	}
+
+	if errorCount == 0 && warningCount == 0 {
+		return ""
+	}
+	parts := []string{}
+	if errorCount > 0 {
+		parts = append(parts, fmt.Sprintf("%d error(s)", errorCount))
+	}
+	if warningCount > 0 {
+		parts = append(parts, fmt.Sprintf("%d warning(s)", warningCount))
+	}
+	return "Found " + strings.Join(parts, ", ")
+}
+
+// sortByPosition returns results sorted by file, then line and column.
+func sortByPosition(results []rules.Result) []rules.Result {
 	// Group by file
 	byFile := make(map[string][]rules.Result)
 	for _, r := range results {
@@ -45,54 +105,26 @@ func (t *Text) Report(results []rules.Result) error {
 	}
 	sort.Strings(files)
 
+	sorted := make([]rules.Result, 0, len(results))
 	for _, file := range files {
 		fileResults := byFile[file]
 		// Sort by line, then column
-		sort.Slice(fileResults, func(i, j int) bool {
+		sort.SliceStable(fileResults, func(i, j int) bool {
 			if fileResults[i].Line != fileResults[j].Line {
 				return fileResults[i].Line < fileResults[j].Line
 			}
 			return fileResults[i].Col < fileResults[j].Col
 		})
-
-		for _, r := range fileResults {
-			line := t.formatResult(r)
-			_, _ = fmt.Fprintln(t.Writer, line)
-		}
-	}
-
-	// Summary
-	errorCount := 0
-	warningCount := 0
-	for _, r := range results {
-		switch r.Severity {
-		case rules.Error:
-			errorCount++
-		case rules.Warning:
-			warningCount++
-		}
+		sorted = append(sorted, fileResults...)
 	}
-
-	_, _ = fmt.Fprintln(t.Writer)
-	if errorCount > 0 || warningCount > 0 {
-		parts := []string{}
-		if errorCount > 0 {
-			parts = append(parts, fmt.Sprintf("%d error(s)", errorCount))
-		}
-		if warningCount > 0 {
-			parts = append(parts, fmt.Sprintf("%d warning(s)", warningCount))
-		}
-		_, _ = fmt.Fprintf(t.Writer, "Found %s\n", strings.Join(parts, ", "))
-	}
-
-	return nil
+	return sorted
 }
 
 func (t *Text) formatResult(r rules.Result) string {
 	// Format: file:line:col: severity: message [rule]
 	severity := r.Severity.String()
 	if !t.NoColor {
-		severity = t.colorize(severity, r.Severity)
+		severity = colorize(severity, r.Severity)
 	}
 
 	if t.ShowRules {
@@ -103,7 +135,8 @@ func (t *Text) formatResult(r rules.Result) string {
 		r.Filename, r.Line, r.Col, severity, r.Message)
 }
 
-func (t *Text) colorize(text string, severity rules.Severity) string {
+// colorize wraps text in the terminal color for severity.
+func colorize(text string, severity rules.Severity) string {
 	var code string
 	switch severity {
 	case rules.Error:
@@ -1,14 +1,18 @@
 package rules
 
 import (
+	"slices"
 	"strings"
 
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // AllowedLinks checks that link hrefs are valid.
-type AllowedLinks struct{}
+type AllowedLinks struct {
+	// Protocols, when set, lists the URL schemes links may use (without the
+	// trailing colon). Relative links are always allowed.
+	Protocols []string
+}
 
 // Name returns the rule identifier.
 func (r *AllowedLinks) Name() string { return RuleAllowedLinks }
@@ -18,81 +22,134 @@ func (r *AllowedLinks) Description() string {
 	return "links must have valid href values"
 }
 
-// Check examines the document for problematic link hrefs.
-func (r *AllowedLinks) Check(doc *parser.Document) []Result {
-	var results []Result
+// DefaultSeverity returns the most severe level this rule reports at.
+func (r *AllowedLinks) DefaultSeverity() Severity { return Error }
+
+// OptionsSchema describes the rule's options.
+func (r *AllowedLinks) OptionsSchema() *OptionsSchema {
+	return objectOptions(map[string]*OptionsSchema{
+		"protocols": {
+			Type:        "array",
+			Items:       &OptionsSchema{Type: "string"},
+			Description: "URL schemes links may use, such as https and mailto; any other scheme is reported",
+		},
+	})
+}
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
+// ConfigureOptions sets the allowed protocols from options.
+func (r *AllowedLinks) ConfigureOptions(options map[string]any) error {
+	if _, ok := options["protocols"]; ok {
+		r.Protocols = nil
+		for _, p := range stringsOption(options, "protocols") {
+			r.Protocols = append(r.Protocols, strings.ToLower(strings.TrimSuffix(p, ":")))
 		}
+	}
+	return nil
+}
 
-		tag := strings.ToLower(n.Data)
-		if tag != "a" && tag != "area" {
-			return true
-		}
+// Check examines the document for problematic link hrefs.
+func (r *AllowedLinks) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+// Visit checks each <a>, <area> element.
+func (r *AllowedLinks) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		href := n.GetAttr("href")
 
-		// Skip template expressions
-		if IsTemplateExpr(href) {
-			return true
+		// Template values can't be checked, but a literal scheme written
+		// in front of them can; template-escape-hazard covers the rest
+		if IsTemplateExprDelims(href, v.Doc.Delims()) {
+			prefix, ok := LiteralAttrPrefix(n, "href", v.Doc)
+			if !ok || prefix == "" {
+				return
+			}
+			href = prefix
+		}
+
+		// An explicit allowlist replaces the built-in scheme checks
+		if r.Protocols != nil {
+			if scheme := urlScheme(href); scheme != "" {
+				if !slices.Contains(r.Protocols, scheme) {
+					v.Report(Result{
+						Rule:     RuleAllowedLinks,
+						Message:  scheme + ": URLs are not allowed",
+						Filename: v.Doc.Filename,
+						Line:     n.Line,
+						Col:      n.Col,
+						Severity: Error,
+					})
+				}
+				return
+			}
 		}
 
 		// Check for javascript: protocol (security risk)
 		if strings.HasPrefix(strings.ToLower(strings.TrimSpace(href)), "javascript:") {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     RuleAllowedLinks,
 				Message:  "javascript: URLs are not allowed",
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Error,
 			})
-			return true
+			return
 		}
 
 		// Check for vbscript: protocol (security risk)
 		if strings.HasPrefix(strings.ToLower(strings.TrimSpace(href)), "vbscript:") {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     RuleAllowedLinks,
 				Message:  "vbscript: URLs are not allowed",
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Error,
 			})
-			return true
+			return
 		}
 
 		// Check for data: URLs in links (potential security risk)
 		if strings.HasPrefix(strings.ToLower(strings.TrimSpace(href)), "data:") {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     RuleAllowedLinks,
 				Message:  "data: URLs in links are discouraged",
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Warning,
 			})
-			return true
+			return
 		}
 
 		// Check for empty href (common mistake)
 		if href == "" && n.HasAttr("href") {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     RuleAllowedLinks,
 				Message:  "empty href is often a mistake; use # for placeholder links",
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Info,
 			})
-			return true
+			return
 		}
+	}, "a", "area")
+}
 
-		return true
-	})
-
-	return results
+// urlScheme returns the lowercase scheme of an absolute URL, without its
+// colon, or "" for relative URLs.
+func urlScheme(href string) string {
+	href = strings.TrimSpace(href)
+	for i := 0; i < len(href); i++ {
+		c := href[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case i > 0 && (c >= '0' && c <= '9' || c == '+' || c == '-' || c == '.'):
+		case i > 0 && c == ':':
+			return strings.ToLower(href[:i])
+		default:
+			return ""
+		}
+	}
+	return ""
 }
@@ -2,7 +2,6 @@ package rules
 
 import (
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // AreaAlt checks that <area> elements have alt text.
@@ -17,49 +16,45 @@ func (r *AreaAlt) Description() string {
 	return "area elements must have alt text describing the link destination"
 }
 
-// Check examines the document for area elements missing alt text.
-func (r *AreaAlt) Check(doc *parser.Document) []Result {
-	var results []Result
+// DefaultSeverity returns the most severe level this rule reports at.
+func (r *AreaAlt) DefaultSeverity() Severity { return Error }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode || !n.IsElement("area") {
-			return true
-		}
+// Check examines the document for area elements missing alt text.
+func (r *AreaAlt) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+// Visit checks each <area> element.
+func (r *AreaAlt) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		// Only areas with href need alt (non-link areas don't need it)
 		if !n.HasAttr("href") {
-			return true
+			return
 		}
 
 		// Check for alt attribute
 		if !n.HasAttr("alt") {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     RuleAreaAlt,
 				Message:  "area element with href must have alt attribute",
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Error,
 			})
-			return true
+			return
 		}
 
 		// Check for empty alt (allowed only if another area has same href with alt)
 		alt := n.GetAttr("alt")
 		if alt == "" || alt == TemplateExprPlaceholder {
 			// Empty alt is a warning - may be intentional for redundant areas
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     RuleAreaAlt,
 				Message:  "area element has empty alt attribute",
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Warning,
 			})
 		}
-
-		return true
-	})
-
-	return results
+	}, "area")
 }
@@ -2,7 +2,6 @@ package rules
 
 import (
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // AriaHiddenBody checks that aria-hidden is not set on the body element.
@@ -14,27 +13,21 @@ func (r *AriaHiddenBody) Description() string {
 	return "aria-hidden must not be set on body element"
 }
 
-func (r *AriaHiddenBody) Check(doc *parser.Document) []Result {
-	var results []Result
+func (r *AriaHiddenBody) DefaultSeverity() Severity { return Error }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode || !n.IsElement("body") {
-			return true
-		}
+func (r *AriaHiddenBody) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+func (r *AriaHiddenBody) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		if n.GetAttr("aria-hidden") == "true" {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     r.Name(),
 				Message:  "aria-hidden on body hides entire page from assistive technology",
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Error,
 			})
 		}
-
-		return true
-	})
-
-	return results
+	}, "body")
 }
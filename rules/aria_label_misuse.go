@@ -5,7 +5,6 @@ import (
 	"strings"
 
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // AriaLabelMisuse checks for aria-label on elements that don't support it.
@@ -17,36 +16,34 @@ func (r *AriaLabelMisuse) Description() string {
 	return "aria-label/aria-labelledby only allowed on labelable elements"
 }
 
-func (r *AriaLabelMisuse) Check(doc *parser.Document) []Result {
-	var results []Result
+func (r *AriaLabelMisuse) DefaultSeverity() Severity { return Error }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
+func (r *AriaLabelMisuse) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+func (r *AriaLabelMisuse) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		ariaLabel := n.GetAttr("aria-label")
 		ariaLabelledby := n.GetAttr("aria-labelledby")
 
 		if ariaLabel == "" && ariaLabelledby == "" {
-			return true
+			return
 		}
 
 		tagName := strings.ToLower(n.Data)
 
 		// Check if element allows aria-label
 		if AriaLabelableElements[tagName] {
-			return true
+			return
 		}
 
 		// Elements with role attribute can use aria-label
 		if n.GetAttr("role") != "" {
-			return true
+			return
 		}
 
 		// Elements with tabindex can use aria-label (they're interactive)
 		if n.GetAttr("tabindex") != "" {
-			return true
+			return
 		}
 
 		attr := "aria-label"
@@ -54,17 +51,13 @@ func (r *AriaLabelMisuse) Check(doc *parser.Document) []Result {
 			attr = "aria-labelledby"
 		}
 
-		results = append(results, Result{
+		v.Report(Result{
 			Rule:     r.Name(),
 			Message:  fmt.Sprintf("%s not allowed on <%s> without role attribute", attr, tagName),
-			Filename: doc.Filename,
+			Filename: v.Doc.Filename,
 			Line:     n.Line,
 			Col:      n.Col,
 			Severity: Error,
 		})
-
-		return true
 	})
-
-	return results
 }
@@ -5,7 +5,6 @@ import (
 	"strings"
 
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // Default patterns - kebab-case for CSS classes, camelCase or kebab-case for IDs/names.
@@ -18,6 +17,39 @@ var (
 	defaultNamePattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_\[\]]*$`)
 )
 
+// namedPatterns are naming conventions that can be given by name instead of
+// a regular expression, as with html-validate.
+var namedPatterns = map[string]string{
+	"kebabcase":  `^[a-z][a-z0-9]*(-[a-z0-9]+)*$`,
+	"camelcase":  `^[a-z][a-zA-Z0-9]*$`,
+	"snakecase":  `^[a-z][a-z0-9]*(_[a-z0-9]+)*$`,
+	"underscore": `^[a-z][a-z0-9]*(_[a-z0-9]+)*$`,
+	"bem":        `^[a-z][a-z0-9]*(-[a-z0-9]+)*(__[a-z0-9]+(-[a-z0-9]+)*)?(--[a-z0-9]+(-[a-z0-9]+)*)?$`,
+}
+
+// patternSchema describes the options of the pattern rules.
+func patternSchema(what string) *OptionsSchema {
+	return objectOptions(map[string]*OptionsSchema{
+		"pattern": {
+			Type:        "string",
+			Format:      "regex",
+			Description: "Regular expression " + what + " must match, or one of kebabcase, camelcase, snakecase, underscore, bem",
+		},
+	})
+}
+
+// patternOption compiles the pattern option. Returns nil if it isn't set.
+func patternOption(options map[string]any) (*regexp.Regexp, error) {
+	pattern, ok := options["pattern"].(string)
+	if !ok {
+		return nil, nil
+	}
+	if named, ok := namedPatterns[pattern]; ok {
+		pattern = named
+	}
+	return regexp.Compile(pattern)
+}
+
 // ClassPattern checks that class names follow a naming convention.
 type ClassPattern struct {
 	Pattern *regexp.Regexp
@@ -31,47 +63,55 @@ func (r *ClassPattern) Description() string {
 	return "class names should follow naming convention"
 }
 
+// DefaultSeverity returns the most severe level this rule reports at.
+func (r *ClassPattern) DefaultSeverity() Severity { return Info }
+
+// OptionsSchema describes the rule's options.
+func (r *ClassPattern) OptionsSchema() *OptionsSchema { return patternSchema("class names") }
+
+// ConfigureOptions sets the pattern from options.
+func (r *ClassPattern) ConfigureOptions(options map[string]any) error {
+	pattern, err := patternOption(options)
+	if pattern != nil {
+		r.Pattern = pattern
+	}
+	return err
+}
+
 // Check examines the document for class names not matching pattern.
-func (r *ClassPattern) Check(doc *parser.Document) []Result {
-	var results []Result
+func (r *ClassPattern) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+// Visit checks each element.
+func (r *ClassPattern) Visit(v *Visitor) {
 	pattern := r.Pattern
 	if pattern == nil {
 		pattern = defaultClassPattern
 	}
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
-
+	v.OnEnter(func(n *parser.Node) {
 		classAttr := n.GetAttr("class")
 		if classAttr == "" {
-			return true
+			return
 		}
 
 		// Check each class name
 		for class := range strings.FieldsSeq(classAttr) {
 			// Skip template expressions
-			if IsTemplateExpr(class) {
+			if IsTemplateExprDelims(class, v.Doc.Delims()) {
 				continue
 			}
 			if !pattern.MatchString(class) {
-				results = append(results, Result{
+				v.Report(Result{
 					Rule:     RuleClassPattern,
 					Message:  "class \"" + class + "\" does not match naming convention",
-					Filename: doc.Filename,
+					Filename: v.Doc.Filename,
 					Line:     n.Line,
 					Col:      n.Col,
 					Severity: Info,
 				})
 			}
 		}
-
-		return true
 	})
-
-	return results
 }
 
 // IDPattern checks that id attributes follow a naming convention.
@@ -87,45 +127,53 @@ func (r *IDPattern) Description() string {
 	return "id attributes should follow naming convention"
 }
 
+// DefaultSeverity returns the most severe level this rule reports at.
+func (r *IDPattern) DefaultSeverity() Severity { return Info }
+
+// OptionsSchema describes the rule's options.
+func (r *IDPattern) OptionsSchema() *OptionsSchema { return patternSchema("ids") }
+
+// ConfigureOptions sets the pattern from options.
+func (r *IDPattern) ConfigureOptions(options map[string]any) error {
+	pattern, err := patternOption(options)
+	if pattern != nil {
+		r.Pattern = pattern
+	}
+	return err
+}
+
 // Check examines the document for id values not matching pattern.
-func (r *IDPattern) Check(doc *parser.Document) []Result {
-	var results []Result
+func (r *IDPattern) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+// Visit checks each element.
+func (r *IDPattern) Visit(v *Visitor) {
 	pattern := r.Pattern
 	if pattern == nil {
 		pattern = defaultIDPattern
 	}
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
-
+	v.OnEnter(func(n *parser.Node) {
 		id := n.GetAttr("id")
 		if id == "" {
-			return true
+			return
 		}
 
 		// Skip template expressions
-		if IsTemplateExpr(id) {
-			return true
+		if IsTemplateExprDelims(id, v.Doc.Delims()) {
+			return
 		}
 
 		if !pattern.MatchString(id) {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     RuleIDPattern,
 				Message:  "id \"" + id + "\" does not match naming convention",
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Info,
 			})
 		}
-
-		return true
 	})
-
-	return results
 }
 
 // NamePattern checks that name attributes follow a naming convention.
@@ -141,43 +189,51 @@ func (r *NamePattern) Description() string {
 	return "name attributes should follow naming convention"
 }
 
+// DefaultSeverity returns the most severe level this rule reports at.
+func (r *NamePattern) DefaultSeverity() Severity { return Info }
+
+// OptionsSchema describes the rule's options.
+func (r *NamePattern) OptionsSchema() *OptionsSchema { return patternSchema("names") }
+
+// ConfigureOptions sets the pattern from options.
+func (r *NamePattern) ConfigureOptions(options map[string]any) error {
+	pattern, err := patternOption(options)
+	if pattern != nil {
+		r.Pattern = pattern
+	}
+	return err
+}
+
 // Check examines the document for name values not matching pattern.
-func (r *NamePattern) Check(doc *parser.Document) []Result {
-	var results []Result
+func (r *NamePattern) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+// Visit checks each element.
+func (r *NamePattern) Visit(v *Visitor) {
 	pattern := r.Pattern
 	if pattern == nil {
 		pattern = defaultNamePattern
 	}
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
-
+	v.OnEnter(func(n *parser.Node) {
 		name := n.GetAttr("name")
 		if name == "" {
-			return true
+			return
 		}
 
 		// Skip template expressions
-		if IsTemplateExpr(name) {
-			return true
+		if IsTemplateExprDelims(name, v.Doc.Delims()) {
+			return
 		}
 
 		if !pattern.MatchString(name) {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     RuleNamePattern,
 				Message:  "name \"" + name + "\" does not match naming convention",
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Info,
 			})
 		}
-
-		return true
 	})
-
-	return results
 }
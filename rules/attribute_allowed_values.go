@@ -4,7 +4,6 @@ import (
 	"strings"
 
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // AttributeAllowedValues checks that attributes have valid values.
@@ -18,44 +17,40 @@ func (r *AttributeAllowedValues) Description() string {
 	return "attributes must have allowed values"
 }
 
-// Check examines the document for attributes with invalid values.
-func (r *AttributeAllowedValues) Check(doc *parser.Document) []Result {
-	var results []Result
+// DefaultSeverity returns the most severe level this rule reports at.
+func (r *AttributeAllowedValues) DefaultSeverity() Severity { return Error }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
+// Check examines the document for attributes with invalid values.
+func (r *AttributeAllowedValues) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+// Visit checks each element.
+func (r *AttributeAllowedValues) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		tag := strings.ToLower(n.Data)
 
 		// Check element-specific attributes
 		switch tag {
 		case "input":
-			results = append(results, r.checkInputType(n, doc)...)
+			v.Report(r.checkInputType(n, v.Doc)...)
 		case "button":
-			results = append(results, r.checkButtonType(n, doc)...)
+			v.Report(r.checkButtonType(n, v.Doc)...)
 		case "form":
-			results = append(results, r.checkFormAttrs(n, doc)...)
+			v.Report(r.checkFormAttrs(n, v.Doc)...)
 		case "a":
-			results = append(results, r.checkAnchorRel(n, doc)...)
+			v.Report(r.checkAnchorRel(n, v.Doc)...)
 		case "link":
-			results = append(results, r.checkLinkRel(n, doc)...)
+			v.Report(r.checkLinkRel(n, v.Doc)...)
 		case "th":
-			results = append(results, r.checkThScope(n, doc)...)
+			v.Report(r.checkThScope(n, v.Doc)...)
 		case "img", "iframe":
-			results = append(results, r.checkLoadingDecoding(n, doc)...)
+			v.Report(r.checkLoadingDecoding(n, v.Doc)...)
 		}
 
 		// Check global attributes
-		results = append(results, r.checkDirAttr(n, doc)...)
-		results = append(results, r.checkCrossOrigin(n, doc)...)
-		results = append(results, r.checkReferrerPolicy(n, doc)...)
-
-		return true
+		v.Report(r.checkDirAttr(n, v.Doc)...)
+		v.Report(r.checkCrossOrigin(n, v.Doc)...)
+		v.Report(r.checkReferrerPolicy(n, v.Doc)...)
 	})
-
-	return results
 }
 
 func (r *AttributeAllowedValues) checkInputType(n *parser.Node, doc *parser.Document) []Result {
@@ -65,14 +60,7 @@ func (r *AttributeAllowedValues) checkInputType(n *parser.Node, doc *parser.Docu
 	}
 	val = strings.ToLower(val)
 	if !ValidInputTypes[val] {
-		return []Result{{
-			Rule:     RuleAttributeAllowedValues,
-			Message:  "invalid input type: " + val,
-			Filename: doc.Filename,
-			Line:     n.Line,
-			Col:      n.Col,
-			Severity: Error,
-		}}
+		return []Result{NewAttrResult(RuleAttributeAllowedValues, "invalid input type: "+val, n, "type", doc, Error)}
 	}
 	return nil
 }
@@ -84,14 +72,7 @@ func (r *AttributeAllowedValues) checkButtonType(n *parser.Node, doc *parser.Doc
 	}
 	val = strings.ToLower(val)
 	if !ValidButtonTypes[val] {
-		return []Result{{
-			Rule:     RuleAttributeAllowedValues,
-			Message:  "invalid button type: " + val,
-			Filename: doc.Filename,
-			Line:     n.Line,
-			Col:      n.Col,
-			Severity: Error,
-		}}
+		return []Result{NewAttrResult(RuleAttributeAllowedValues, "invalid button type: "+val, n, "type", doc, Error)}
 	}
 	return nil
 }
@@ -102,28 +83,14 @@ func (r *AttributeAllowedValues) checkFormAttrs(n *parser.Node, doc *parser.Docu
 	// Check method
 	if method := n.GetAttr("method"); method != "" {
 		if !ValidFormMethods[strings.ToLower(method)] {
-			results = append(results, Result{
-				Rule:     RuleAttributeAllowedValues,
-				Message:  "invalid form method: " + method,
-				Filename: doc.Filename,
-				Line:     n.Line,
-				Col:      n.Col,
-				Severity: Error,
-			})
+			results = append(results, NewAttrResult(RuleAttributeAllowedValues, "invalid form method: "+method, n, "method", doc, Error))
 		}
 	}
 
 	// Check enctype
 	if enctype := n.GetAttr("enctype"); enctype != "" {
 		if !ValidFormEnctypes[strings.ToLower(enctype)] {
-			results = append(results, Result{
-				Rule:     RuleAttributeAllowedValues,
-				Message:  "invalid form enctype: " + enctype,
-				Filename: doc.Filename,
-				Line:     n.Line,
-				Col:      n.Col,
-				Severity: Error,
-			})
+			results = append(results, NewAttrResult(RuleAttributeAllowedValues, "invalid form enctype: "+enctype, n, "enctype", doc, Error))
 		}
 	}
 
@@ -141,14 +108,7 @@ func (r *AttributeAllowedValues) checkAnchorRel(n *parser.Node, doc *parser.Docu
 	for rel := range strings.FieldsSeq(val) {
 		rel = strings.ToLower(rel)
 		if !ValidAnchorRels[rel] {
-			results = append(results, Result{
-				Rule:     RuleAttributeAllowedValues,
-				Message:  "invalid anchor rel value: " + rel,
-				Filename: doc.Filename,
-				Line:     n.Line,
-				Col:      n.Col,
-				Severity: Warning,
-			})
+			results = append(results, NewAttrResult(RuleAttributeAllowedValues, "invalid anchor rel value: "+rel, n, "rel", doc, Warning))
 		}
 	}
 	return results
@@ -165,14 +125,7 @@ func (r *AttributeAllowedValues) checkLinkRel(n *parser.Node, doc *parser.Docume
 	for rel := range strings.FieldsSeq(val) {
 		rel = strings.ToLower(rel)
 		if !ValidLinkRels[rel] {
-			results = append(results, Result{
-				Rule:     RuleAttributeAllowedValues,
-				Message:  "invalid link rel value: " + rel,
-				Filename: doc.Filename,
-				Line:     n.Line,
-				Col:      n.Col,
-				Severity: Warning,
-			})
+			results = append(results, NewAttrResult(RuleAttributeAllowedValues, "invalid link rel value: "+rel, n, "rel", doc, Warning))
 		}
 	}
 	return results
@@ -185,14 +138,7 @@ func (r *AttributeAllowedValues) checkThScope(n *parser.Node, doc *parser.Docume
 	}
 	val = strings.ToLower(val)
 	if !ValidScopeValues[val] {
-		return []Result{{
-			Rule:     RuleAttributeAllowedValues,
-			Message:  "invalid th scope value: " + val,
-			Filename: doc.Filename,
-			Line:     n.Line,
-			Col:      n.Col,
-			Severity: Error,
-		}}
+		return []Result{NewAttrResult(RuleAttributeAllowedValues, "invalid th scope value: "+val, n, "scope", doc, Error)}
 	}
 	return nil
 }
@@ -202,27 +148,13 @@ func (r *AttributeAllowedValues) checkLoadingDecoding(n *parser.Node, doc *parse
 
 	if loading := n.GetAttr("loading"); loading != "" {
 		if !ValidLoadingValues[strings.ToLower(loading)] {
-			results = append(results, Result{
-				Rule:     RuleAttributeAllowedValues,
-				Message:  "invalid loading value: " + loading,
-				Filename: doc.Filename,
-				Line:     n.Line,
-				Col:      n.Col,
-				Severity: Error,
-			})
+			results = append(results, NewAttrResult(RuleAttributeAllowedValues, "invalid loading value: "+loading, n, "loading", doc, Error))
 		}
 	}
 
 	if decoding := n.GetAttr("decoding"); decoding != "" {
 		if !ValidDecodingValues[strings.ToLower(decoding)] {
-			results = append(results, Result{
-				Rule:     RuleAttributeAllowedValues,
-				Message:  "invalid decoding value: " + decoding,
-				Filename: doc.Filename,
-				Line:     n.Line,
-				Col:      n.Col,
-				Severity: Error,
-			})
+			results = append(results, NewAttrResult(RuleAttributeAllowedValues, "invalid decoding value: "+decoding, n, "decoding", doc, Error))
 		}
 	}
 
@@ -236,14 +168,7 @@ func (r *AttributeAllowedValues) checkDirAttr(n *parser.Node, doc *parser.Docume
 	}
 	val = strings.ToLower(val)
 	if !ValidDirValues[val] {
-		return []Result{{
-			Rule:     RuleAttributeAllowedValues,
-			Message:  "invalid dir value: " + val,
-			Filename: doc.Filename,
-			Line:     n.Line,
-			Col:      n.Col,
-			Severity: Error,
-		}}
+		return []Result{NewAttrResult(RuleAttributeAllowedValues, "invalid dir value: "+val, n, "dir", doc, Error)}
 	}
 	return nil
 }
@@ -254,14 +179,7 @@ func (r *AttributeAllowedValues) checkCrossOrigin(n *parser.Node, doc *parser.Do
 	}
 	val := strings.ToLower(n.GetAttr("crossorigin"))
 	if !ValidCrossOriginValues[val] {
-		return []Result{{
-			Rule:     RuleAttributeAllowedValues,
-			Message:  "invalid crossorigin value: " + val,
-			Filename: doc.Filename,
-			Line:     n.Line,
-			Col:      n.Col,
-			Severity: Error,
-		}}
+		return []Result{NewAttrResult(RuleAttributeAllowedValues, "invalid crossorigin value: "+val, n, "crossorigin", doc, Error)}
 	}
 	return nil
 }
@@ -272,14 +190,7 @@ func (r *AttributeAllowedValues) checkReferrerPolicy(n *parser.Node, doc *parser
 	}
 	val := strings.ToLower(n.GetAttr("referrerpolicy"))
 	if !ValidReferrerPolicies[val] {
-		return []Result{{
-			Rule:     RuleAttributeAllowedValues,
-			Message:  "invalid referrerpolicy value: " + val,
-			Filename: doc.Filename,
-			Line:     n.Line,
-			Col:      n.Col,
-			Severity: Error,
-		}}
+		return []Result{NewAttrResult(RuleAttributeAllowedValues, "invalid referrerpolicy value: "+val, n, "referrerpolicy", doc, Error)}
 	}
 	return nil
 }
@@ -5,7 +5,6 @@ import (
 	"strings"
 
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // AttributeMisuse checks that attributes are used on correct elements.
@@ -26,6 +25,9 @@ func (r *AttributeMisuse) Description() string {
 	return "attributes must be used on appropriate elements"
 }
 
+// DefaultSeverity returns the most severe level this rule reports at.
+func (r *AttributeMisuse) DefaultSeverity() Severity { return Error }
+
 // attributeElementMap defines which attributes are valid on which elements.
 // Empty slice means the attribute is global.
 var attributeElementMap = map[string][]string{
@@ -131,19 +133,16 @@ var attributeElementMap = map[string][]string{
 }
 
 // Check examines the document for misused attributes.
-func (r *AttributeMisuse) Check(doc *parser.Document) []Result {
-	var results []Result
-
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
+func (r *AttributeMisuse) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+// Visit checks each element.
+func (r *AttributeMisuse) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		tag := strings.ToLower(n.Data)
 
 		// Skip custom elements
 		if IsCustomElement(tag) {
-			return true
+			return
 		}
 
 		// Check each attribute
@@ -178,56 +177,54 @@ func (r *AttributeMisuse) Check(doc *parser.Document) []Result {
 
 			// Check if element is in valid list
 			if !slices.Contains(validElements, tag) {
-				results = append(results, Result{
+				v.Report(Result{
 					Rule:     RuleAttributeMisuse,
 					Message:  "attribute '" + attrName + "' is not valid on <" + tag + ">",
-					Filename: doc.Filename,
+					Filename: v.Doc.Filename,
 					Line:     n.Line,
 					Col:      n.Col,
 					Severity: Error,
 				})
 			}
 		}
-
-		return true
 	})
+}
 
-	return results
+// globalAttributes are the attributes valid on all elements.
+var globalAttributes = map[string]bool{
+	"accesskey":          true,
+	"autocapitalize":     true,
+	"autofocus":          false, // Has specific elements
+	"class":              true,
+	"contenteditable":    true,
+	"dir":                true,
+	"draggable":          true,
+	"enterkeyhint":       true,
+	"hidden":             true,
+	"id":                 true,
+	"inert":              true,
+	"inputmode":          true,
+	"is":                 true,
+	"itemid":             true,
+	"itemprop":           true,
+	"itemref":            true,
+	"itemscope":          true,
+	"itemtype":           true,
+	"lang":               true,
+	"nonce":              true,
+	"part":               true,
+	"popover":            true,
+	"role":               true,
+	"slot":               true,
+	"spellcheck":         true,
+	"style":              true,
+	"tabindex":           true,
+	"title":              true,
+	"translate":          true,
+	"writingsuggestions": true,
 }
 
 // isGlobalAttribute returns true for attributes valid on all elements.
 func isGlobalAttribute(attr string) bool {
-	globalAttrs := map[string]bool{
-		"accesskey":          true,
-		"autocapitalize":     true,
-		"autofocus":          false, // Has specific elements
-		"class":              true,
-		"contenteditable":    true,
-		"dir":                true,
-		"draggable":          true,
-		"enterkeyhint":       true,
-		"hidden":             true,
-		"id":                 true,
-		"inert":              true,
-		"inputmode":          true,
-		"is":                 true,
-		"itemid":             true,
-		"itemprop":           true,
-		"itemref":            true,
-		"itemscope":          true,
-		"itemtype":           true,
-		"lang":               true,
-		"nonce":              true,
-		"part":               true,
-		"popover":            true,
-		"role":               true,
-		"slot":               true,
-		"spellcheck":         true,
-		"style":              true,
-		"tabindex":           true,
-		"title":              true,
-		"translate":          true,
-		"writingsuggestions": true,
-	}
-	return globalAttrs[attr]
+	return globalAttributes[attr]
 }
@@ -2,7 +2,6 @@ package rules
 
 import (
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // ButtonName checks that buttons have accessible names.
@@ -14,27 +13,21 @@ func (r *ButtonName) Description() string {
 	return "buttons must have text content or aria-label for accessibility"
 }
 
-func (r *ButtonName) Check(doc *parser.Document) []Result {
-	var results []Result
+func (r *ButtonName) DefaultSeverity() Severity { return Error }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode || !n.IsElement("button") {
-			return true
-		}
+func (r *ButtonName) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+func (r *ButtonName) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		if !HasAccessibleName(n) {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     r.Name(),
 				Message:  "button element missing accessible name",
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Error,
 			})
 		}
-
-		return true
-	})
-
-	return results
+	}, "button")
 }
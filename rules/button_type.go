@@ -2,7 +2,6 @@ package rules
 
 import (
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // ButtonType checks that buttons have explicit type attributes.
@@ -14,28 +13,22 @@ func (r *ButtonType) Description() string {
 	return "buttons should have explicit type attribute (submit, button, or reset)"
 }
 
-func (r *ButtonType) Check(doc *parser.Document) []Result {
-	var results []Result
+func (r *ButtonType) DefaultSeverity() Severity { return Warning }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode || !n.IsElement("button") {
-			return true
-		}
+func (r *ButtonType) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+func (r *ButtonType) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		// Check if button has type attribute
 		if !n.HasAttr("type") {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     r.Name(),
 				Message:  "button missing type attribute (defaults to submit)",
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Warning,
-			})
+			}.WithEdits(v.Doc, insertAttr(v.Doc, n, `type="button"`)...))
 		}
-
-		return true
-	})
-
-	return results
+	}, "button")
 }
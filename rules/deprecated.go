@@ -4,7 +4,6 @@ import (
 	"strings"
 
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // Deprecated checks for deprecated HTML elements.
@@ -18,31 +17,38 @@ func (r *Deprecated) Description() string {
 	return "deprecated HTML elements should not be used"
 }
 
-// Check examines the document for deprecated elements.
-func (r *Deprecated) Check(doc *parser.Document) []Result {
-	var results []Result
+// DefaultSeverity returns the most severe level this rule reports at.
+func (r *Deprecated) DefaultSeverity() Severity { return Warning }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
+// Check examines the document for deprecated elements.
+func (r *Deprecated) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+// Visit checks each element.
+func (r *Deprecated) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		tag := strings.ToLower(n.Data)
 
 		// Check if element is deprecated
 		if suggestion, deprecated := DeprecatedElements[tag]; deprecated {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     RuleDeprecated,
 				Message:  "element <" + tag + "> is deprecated; " + suggestion,
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Warning,
-			})
+			}.WithEdits(v.Doc, r.fix(v.Doc, n, tag)...))
 		}
-
-		return true
 	})
+}
 
-	return results
+// fix returns the edits replacing a deprecated element with a modern
+// equivalent, for the elements that have one.
+func (r *Deprecated) fix(doc *parser.Document, n *parser.Node, tag string) []Edit {
+	// <center> becomes a <div>. Centering is left to the stylesheet, as an
+	// inline style would only trade this problem for no-inline-style.
+	if tag != "center" {
+		return nil
+	}
+	return renameElement(doc, n, "div")
 }
@@ -0,0 +1,26 @@
+package rules
+
+import (
+	"github.com/toba/go-html-validate/parser"
+)
+
+// DisableDirective reports problems with inline directives: directives that
+// suppress nothing, name unknown rules, or aren't recognised at all.
+type DisableDirective struct{}
+
+// Name returns the rule identifier.
+func (r *DisableDirective) Name() string { return RuleDisableDirective }
+
+// Description returns what this rule checks.
+func (r *DisableDirective) Description() string {
+	return "inline directives must name known rules and suppress something"
+}
+
+// DefaultSeverity returns the most severe level this rule reports at.
+func (r *DisableDirective) DefaultSeverity() Severity { return Warning }
+
+// Check reports nothing on its own: directives span the results of every
+// other rule, so the linter checks them once the other rules have run.
+func (r *DisableDirective) Check(doc *parser.Document) []Result {
+	return nil
+}
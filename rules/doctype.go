@@ -24,6 +24,9 @@ func (r *DoctypeHTML) Description() string {
 	return "DOCTYPE must be html (HTML5)"
 }
 
+// DefaultSeverity returns the most severe level this rule reports at.
+func (r *DoctypeHTML) DefaultSeverity() Severity { return Warning }
+
 // Check examines the document for non-HTML5 doctypes.
 func (r *DoctypeHTML) Check(doc *parser.Document) []Result {
 	var results []Result
@@ -62,6 +65,9 @@ func (r *MissingDoctype) Description() string {
 	return "document must have DOCTYPE declaration"
 }
 
+// DefaultSeverity returns the most severe level this rule reports at.
+func (r *MissingDoctype) DefaultSeverity() Severity { return Warning }
+
 // Check examines the document for missing DOCTYPE.
 func (r *MissingDoctype) Check(doc *parser.Document) []Result {
 	// Only check full documents (not fragments)
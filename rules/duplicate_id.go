@@ -4,7 +4,6 @@ import (
 	"fmt"
 
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // DuplicateID checks that id attributes are unique within a document.
@@ -16,35 +15,37 @@ func (r *DuplicateID) Description() string {
 	return "id attributes must be unique within a document"
 }
 
+func (r *DuplicateID) DefaultSeverity() Severity { return Error }
+
+// IsDocumentRule marks the rule as checking the page as a whole.
+func (r *DuplicateID) IsDocumentRule() {}
+
 type idLocation struct {
 	line int
 	col  int
 }
 
-func (r *DuplicateID) Check(doc *parser.Document) []Result {
-	var results []Result
-	seenIDs := make(map[string]idLocation)
+func (r *DuplicateID) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
+func (r *DuplicateID) Visit(v *Visitor) {
+	seenIDs := make(map[string]idLocation)
 
+	v.OnEnter(func(n *parser.Node) {
 		id := n.GetAttr("id")
 		if id == "" {
-			return true
+			return
 		}
 
 		// Skip template placeholders
 		if id == TemplateExprPlaceholder {
-			return true
+			return
 		}
 
 		if first, exists := seenIDs[id]; exists {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     r.Name(),
 				Message:  fmt.Sprintf("duplicate id %q (first defined at line %d)", id, first.line),
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Error,
@@ -52,9 +53,5 @@ func (r *DuplicateID) Check(doc *parser.Document) []Result {
 		} else {
 			seenIDs[id] = idLocation{line: n.Line, col: n.Col}
 		}
-
-		return true
 	})
-
-	return results
 }
@@ -5,7 +5,6 @@ import (
 	"unicode"
 
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // ElementName checks that element names are valid.
@@ -19,59 +18,55 @@ func (r *ElementName) Description() string {
 	return "element names must be valid HTML element names or valid custom element names"
 }
 
-// Check examines the document for invalid element names.
-func (r *ElementName) Check(doc *parser.Document) []Result {
-	var results []Result
+// DefaultSeverity returns the most severe level this rule reports at.
+func (r *ElementName) DefaultSeverity() Severity { return Error }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
+// Check examines the document for invalid element names.
+func (r *ElementName) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+// Visit checks each element.
+func (r *ElementName) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		tagName := strings.ToLower(n.Data)
 
 		// Skip template placeholders
 		if tagName == "tmpl" || tagName == "" {
-			return true
+			return
 		}
 
 		// Check if it's a known valid element
 		if ValidElements[tagName] {
-			return true
+			return
 		}
 
 		// Check if it's a valid custom element
 		if IsCustomElement(tagName) {
-			return true
+			return
 		}
 
 		// Check for common typos or invalid characters
 		if !isValidElementName(tagName) {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     RuleElementName,
 				Message:  "invalid element name: " + tagName,
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Error,
 			})
-			return true
+			return
 		}
 
 		// Unknown element (not in valid list, not custom, but syntactically valid)
-		results = append(results, Result{
+		v.Report(Result{
 			Rule:     RuleElementName,
 			Message:  "unknown element: " + tagName,
-			Filename: doc.Filename,
+			Filename: v.Doc.Filename,
 			Line:     n.Line,
 			Col:      n.Col,
 			Severity: Warning,
 		})
-
-		return true
 	})
-
-	return results
 }
 
 // isValidElementName checks if a name follows element naming rules.
@@ -18,21 +18,21 @@ func (r *ElementPermittedContent) Description() string {
 	return "elements must contain only permitted child elements"
 }
 
-// Check examines the document for elements with invalid children.
-func (r *ElementPermittedContent) Check(doc *parser.Document) []Result {
-	var results []Result
+// DefaultSeverity returns the most severe level this rule reports at.
+func (r *ElementPermittedContent) DefaultSeverity() Severity { return Error }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
+// Check examines the document for elements with invalid children.
+func (r *ElementPermittedContent) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+// Visit checks each element.
+func (r *ElementPermittedContent) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		tag := strings.ToLower(n.Data)
 
 		// Get element spec
 		spec, hasSpec := ElementSpecs[tag]
 		if !hasSpec {
-			return true
+			return
 		}
 
 		// Check for forbidden descendants
@@ -44,10 +44,10 @@ func (r *ElementPermittedContent) Check(doc *parser.Document) []Result {
 				childTag := strings.ToLower(child.Data)
 				for _, forbidden := range spec.ForbiddenContent {
 					if childTag == forbidden {
-						results = append(results, Result{
+						v.Report(Result{
 							Rule:     RuleElementPermittedContent,
 							Message:  "<" + tag + "> must not contain <" + forbidden + ">",
-							Filename: doc.Filename,
+							Filename: v.Doc.Filename,
 							Line:     child.Line,
 							Col:      child.Col,
 							Severity: Error,
@@ -59,7 +59,7 @@ func (r *ElementPermittedContent) Check(doc *parser.Document) []Result {
 
 		// Check permitted content if specified
 		if len(spec.PermittedContent) == 0 {
-			return true
+			return
 		}
 
 		// Build permitted set for fast lookup
@@ -82,19 +82,15 @@ func (r *ElementPermittedContent) Check(doc *parser.Document) []Result {
 			}
 
 			if !permitted[childTag] {
-				results = append(results, Result{
+				v.Report(Result{
 					Rule:     RuleElementPermittedContent,
 					Message:  "<" + childTag + "> is not permitted as child of <" + tag + ">",
-					Filename: doc.Filename,
+					Filename: v.Doc.Filename,
 					Line:     child.Line,
 					Col:      child.Col,
 					Severity: Error,
 				})
 			}
 		}
-
-		return true
 	})
-
-	return results
 }
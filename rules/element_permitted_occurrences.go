@@ -18,28 +18,27 @@ func (r *ElementPermittedOccurrences) Description() string {
 	return "elements must not exceed permitted occurrences"
 }
 
-// Check examines the document for elements appearing more than allowed.
-func (r *ElementPermittedOccurrences) Check(doc *parser.Document) []Result {
-	var results []Result
+// DefaultSeverity returns the most severe level this rule reports at.
+func (r *ElementPermittedOccurrences) DefaultSeverity() Severity { return Error }
 
-	// Track occurrences within each context
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
+// Check examines the document for elements appearing more than allowed.
+func (r *ElementPermittedOccurrences) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+// Visit checks each element.
+func (r *ElementPermittedOccurrences) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		tag := strings.ToLower(n.Data)
 
 		// Check if this element type has uniqueness constraints
 		contextTag, hasConstraint := UniqueElements[tag]
 		if !hasConstraint {
-			return true
+			return
 		}
 
 		// Find the context element
 		context := AncestorWithTag(n, contextTag)
 		if context == nil {
-			return true
+			return
 		}
 
 		// Count occurrences of this tag within the context
@@ -65,18 +64,14 @@ func (r *ElementPermittedOccurrences) Check(doc *parser.Document) []Result {
 
 		// Report error only on second and subsequent occurrences
 		if count > 1 && n != firstOccurrence {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     RuleElementPermittedOccurrences,
 				Message:  "<" + tag + "> must only appear once within <" + contextTag + ">",
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Error,
 			})
 		}
-
-		return true
 	})
-
-	return results
 }
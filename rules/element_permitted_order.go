@@ -18,32 +18,28 @@ func (r *ElementPermittedOrder) Description() string {
 	return "elements must appear in correct order"
 }
 
-// Check examines the document for incorrectly ordered elements.
-func (r *ElementPermittedOrder) Check(doc *parser.Document) []Result {
-	var results []Result
+// DefaultSeverity returns the most severe level this rule reports at.
+func (r *ElementPermittedOrder) DefaultSeverity() Severity { return Error }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
+// Check examines the document for incorrectly ordered elements.
+func (r *ElementPermittedOrder) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+// Visit checks each element.
+func (r *ElementPermittedOrder) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		tag := strings.ToLower(n.Data)
 
 		switch tag {
 		case "html":
-			results = append(results, r.checkHTMLOrder(n, doc)...)
+			v.Report(r.checkHTMLOrder(n, v.Doc)...)
 		case "table":
-			results = append(results, r.checkTableOrder(n, doc)...)
+			v.Report(r.checkTableOrder(n, v.Doc)...)
 		case "details":
-			results = append(results, r.checkDetailsOrder(n, doc)...)
+			v.Report(r.checkDetailsOrder(n, v.Doc)...)
 		case "fieldset":
-			results = append(results, r.checkFieldsetOrder(n, doc)...)
+			v.Report(r.checkFieldsetOrder(n, v.Doc)...)
 		}
-
-		return true
 	})
-
-	return results
 }
 
 // checkHTMLOrder verifies head comes before body.
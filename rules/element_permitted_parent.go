@@ -19,48 +19,44 @@ func (r *ElementPermittedParent) Description() string {
 	return "elements must have permitted parent elements"
 }
 
-// Check examines the document for elements with invalid parents.
-func (r *ElementPermittedParent) Check(doc *parser.Document) []Result {
-	var results []Result
+// DefaultSeverity returns the most severe level this rule reports at.
+func (r *ElementPermittedParent) DefaultSeverity() Severity { return Error }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
+// Check examines the document for elements with invalid parents.
+func (r *ElementPermittedParent) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+// Visit checks each element.
+func (r *ElementPermittedParent) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		tag := strings.ToLower(n.Data)
 
 		// Get element spec
 		spec, hasSpec := ElementSpecs[tag]
 		if !hasSpec || len(spec.PermittedParents) == 0 {
-			return true
+			return
 		}
 
 		// Skip if no parent (document root)
 		if n.Parent == nil || n.Parent.Type != html.ElementNode {
-			return true
+			return
 		}
 
 		parentTag := strings.ToLower(n.Parent.Data)
 
 		// Check if parent is permitted
 		if slices.Contains(spec.PermittedParents, parentTag) {
-			return true
+			return
 		}
 
 		// Parent not permitted
 		parentList := strings.Join(spec.PermittedParents, ", ")
-		results = append(results, Result{
+		v.Report(Result{
 			Rule:     RuleElementPermittedParent,
 			Message:  "<" + tag + "> must be child of " + parentList + ", not <" + parentTag + ">",
-			Filename: doc.Filename,
+			Filename: v.Doc.Filename,
 			Line:     n.Line,
 			Col:      n.Col,
 			Severity: Error,
 		})
-
-		return true
 	})
-
-	return results
 }
@@ -4,7 +4,6 @@ import (
 	"strings"
 
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // ElementRequiredAncestor checks that elements have their required ancestors.
@@ -18,49 +17,45 @@ func (r *ElementRequiredAncestor) Description() string {
 	return "elements must have required ancestor elements"
 }
 
-// Check examines the document for elements missing required ancestors.
-func (r *ElementRequiredAncestor) Check(doc *parser.Document) []Result {
-	var results []Result
+// DefaultSeverity returns the most severe level this rule reports at.
+func (r *ElementRequiredAncestor) DefaultSeverity() Severity { return Error }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
+// Check examines the document for elements missing required ancestors.
+func (r *ElementRequiredAncestor) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+// Visit checks each element.
+func (r *ElementRequiredAncestor) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		tag := strings.ToLower(n.Data)
 
 		// Check if element has required ancestors
 		requiredAncestors, hasRequirement := RequiredAncestors[tag]
 		if !hasRequirement {
-			return true
+			return
 		}
 
 		// Check if any required ancestor is present
 		if HasAncestor(n, requiredAncestors...) {
-			return true
+			return
 		}
 
 		// For template fragments, skip errors for top-level orphaned elements.
 		// These are meant to be included into parent templates that provide ancestors.
-		if doc.IsTemplateFragment && isTopLevel(n) {
-			return true
+		if v.Doc.IsTemplateFragment && isTopLevel(n) {
+			return
 		}
 
 		// Missing required ancestor
 		ancestorList := strings.Join(requiredAncestors, ", ")
-		results = append(results, Result{
+		v.Report(Result{
 			Rule:     RuleElementRequiredAncestor,
 			Message:  "<" + tag + "> requires ancestor: " + ancestorList,
-			Filename: doc.Filename,
+			Filename: v.Doc.Filename,
 			Line:     n.Line,
 			Col:      n.Col,
 			Severity: Error,
 		})
-
-		return true
 	})
-
-	return results
 }
 
 // isTopLevel checks if a node is at or near the top level of the document.
@@ -4,7 +4,6 @@ import (
 	"strings"
 
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // ElementRequiredAttributes checks that elements have their required attributes.
@@ -18,39 +17,35 @@ func (r *ElementRequiredAttributes) Description() string {
 	return "elements must have required attributes"
 }
 
-// Check examines the document for elements missing required attributes.
-func (r *ElementRequiredAttributes) Check(doc *parser.Document) []Result {
-	var results []Result
+// DefaultSeverity returns the most severe level this rule reports at.
+func (r *ElementRequiredAttributes) DefaultSeverity() Severity { return Error }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
+// Check examines the document for elements missing required attributes.
+func (r *ElementRequiredAttributes) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+// Visit checks each element.
+func (r *ElementRequiredAttributes) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		tag := strings.ToLower(n.Data)
 
 		// Get element spec
 		spec, hasSpec := ElementSpecs[tag]
 		if !hasSpec || len(spec.RequiredAttributes) == 0 {
-			return true
+			return
 		}
 
 		// Check for each required attribute
 		for _, attr := range spec.RequiredAttributes {
 			if !n.HasAttr(attr) {
-				results = append(results, Result{
+				v.Report(Result{
 					Rule:     RuleElementRequiredAttributes,
 					Message:  "<" + tag + "> requires attribute: " + attr,
-					Filename: doc.Filename,
+					Filename: v.Doc.Filename,
 					Line:     n.Line,
 					Col:      n.Col,
 					Severity: Error,
 				})
 			}
 		}
-
-		return true
 	})
-
-	return results
 }
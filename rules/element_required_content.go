@@ -18,21 +18,21 @@ func (r *ElementRequiredContent) Description() string {
 	return "elements must have required child elements"
 }
 
-// Check examines the document for elements missing required children.
-func (r *ElementRequiredContent) Check(doc *parser.Document) []Result {
-	var results []Result
+// DefaultSeverity returns the most severe level this rule reports at.
+func (r *ElementRequiredContent) DefaultSeverity() Severity { return Error }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
+// Check examines the document for elements missing required children.
+func (r *ElementRequiredContent) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+// Visit checks each element.
+func (r *ElementRequiredContent) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		tag := strings.ToLower(n.Data)
 
 		// Get element spec
 		spec, hasSpec := ElementSpecs[tag]
 		if !hasSpec || len(spec.RequiredChildren) == 0 {
-			return true
+			return
 		}
 
 		// Build set of child element tags
@@ -46,19 +46,15 @@ func (r *ElementRequiredContent) Check(doc *parser.Document) []Result {
 		// Check for each required child
 		for _, required := range spec.RequiredChildren {
 			if !childTags[required] {
-				results = append(results, Result{
+				v.Report(Result{
 					Rule:     RuleElementRequiredContent,
 					Message:  "<" + tag + "> requires child element: <" + required + ">",
-					Filename: doc.Filename,
+					Filename: v.Doc.Filename,
 					Line:     n.Line,
 					Col:      n.Col,
 					Severity: Error,
 				})
 			}
 		}
-
-		return true
 	})
-
-	return results
 }
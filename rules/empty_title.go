@@ -4,7 +4,6 @@ import (
 	"strings"
 
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // EmptyTitle checks that title elements have text content.
@@ -16,32 +15,22 @@ func (r *EmptyTitle) Description() string {
 	return "<title> element must have text content"
 }
 
-func (r *EmptyTitle) Check(doc *parser.Document) []Result {
-	var results []Result
+func (r *EmptyTitle) DefaultSeverity() Severity { return Error }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
-
-		if strings.ToLower(n.Data) != "title" {
-			return true
-		}
+func (r *EmptyTitle) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+func (r *EmptyTitle) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		text := strings.TrimSpace(n.TextContent())
 		if text == "" {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     r.Name(),
 				Message:  "<title> cannot be empty, must have text content",
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Error,
 			})
 		}
-
-		return true
-	})
-
-	return results
+	}, "title")
 }
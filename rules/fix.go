@@ -0,0 +1,155 @@
+package rules
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/toba/go-html-validate/parser"
+	"golang.org/x/net/html"
+)
+
+// Edit replaces the original source between Start and End with Text. Start
+// == End inserts Text.
+type Edit struct {
+	Start int    // 0-indexed byte offset in the original source
+	End   int    // 0-indexed byte offset, exclusive
+	Text  string // replacement text
+}
+
+// WithEdits returns a copy of the result fixed by applying edits to doc's
+// original source. Node and attribute positions already map back through
+// the template source map, so edits built from them land in the original
+// template; any edit that would touch a template action, or that falls
+// outside the source, leaves the result without a fix instead.
+func (r Result) WithEdits(doc *parser.Document, edits ...Edit) Result {
+	sm := doc.SourceMap()
+	if len(edits) == 0 || sm == nil {
+		return r
+	}
+	for _, e := range edits {
+		if e.Start < 0 || e.End < e.Start || e.End > len(sm.Original) {
+			return r
+		}
+		for _, a := range sm.Actions {
+			start, end := a.Span.Start.Offset, a.Span.End.Offset
+			if (e.Start < end && start < e.End) || (start < e.Start && e.Start < end) {
+				return r
+			}
+		}
+	}
+	r.Edits = edits
+	return r
+}
+
+// startTagName returns the offset just past the tag name of n's start tag
+// in the original source. It returns false if n has no start tag of its own
+// there, as for elements the parser implied or templates inlined into a
+// page, or if template actions outside attribute values may add attributes
+// to the tag.
+func startTagName(doc *parser.Document, n *parser.Node) (int, bool) {
+	sm := doc.SourceMap()
+	if sm == nil {
+		return 0, false
+	}
+	src := sm.Original
+	nameEnd := n.Offset + 1 + len(n.Data)
+	if n.Offset < 0 || nameEnd > len(src) || src[n.Offset] != '<' ||
+		!strings.EqualFold(string(src[n.Offset+1:nameEnd]), n.Data) {
+		return 0, false
+	}
+
+	z := html.NewTokenizer(bytes.NewReader(src[n.Offset:]))
+	z.Next()
+	tagEnd := n.Offset + len(z.Raw())
+	for _, a := range sm.Actions {
+		if a.Span.Start.Offset < n.Offset || a.Span.Start.Offset >= tagEnd {
+			continue
+		}
+		if !inAttrValue(n, a.Span) {
+			return 0, false
+		}
+	}
+	return nameEnd, true
+}
+
+// inAttrValue reports whether span lies within the value of one of n's
+// attributes. Value spans can start after a leading action, so the whole
+// attribute after its name is checked.
+func inAttrValue(n *parser.Node, span parser.Span) bool {
+	for _, attr := range n.Attr {
+		for _, a := range n.AttrSpans(attr.Key) {
+			if a.Key.End.Offset <= span.Start.Offset && span.End.Offset <= a.Span.End.Offset {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// insertAttr returns the edit adding attr, such as `type="button"`, to n's
+// start tag, or nil if n's start tag isn't in the original source.
+func insertAttr(doc *parser.Document, n *parser.Node, attr string) []Edit {
+	at, ok := startTagName(doc, n)
+	if !ok {
+		return nil
+	}
+	return []Edit{{Start: at, End: at, Text: " " + attr}}
+}
+
+// setAttr returns the edit giving n's name attribute value, adding the
+// attribute if n doesn't have it.
+func setAttr(doc *parser.Document, n *parser.Node, name, value string) []Edit {
+	a, ok := n.AttrSpan(name)
+	if !ok {
+		return insertAttr(doc, n, name+`="`+value+`"`)
+	}
+	if a.Value.Start.Offset == a.Value.End.Offset {
+		// Valueless, or an empty value: rewrite the whole attribute
+		return []Edit{{Start: a.Span.Start.Offset, End: a.Span.End.Offset, Text: name + `="` + value + `"`}}
+	}
+	return []Edit{{Start: a.Value.Start.Offset, End: a.Value.End.Offset, Text: value}}
+}
+
+// renameElement returns the edits renaming n's start and end tags to tag,
+// or nil if either tag can't be found in the original source.
+func renameElement(doc *parser.Document, n *parser.Node, tag string) []Edit {
+	nameEnd, ok := startTagName(doc, n)
+	if !ok {
+		return nil
+	}
+	start, end, ok := matchingEndTag(doc.SourceMap().Original, n.Offset, strings.ToLower(n.Data))
+	if !ok {
+		return nil
+	}
+	return []Edit{
+		{Start: n.Offset + 1, End: nameEnd, Text: tag},
+		{Start: start, End: end, Text: "</" + tag + ">"},
+	}
+}
+
+// matchingEndTag finds the end tag closing the tag element whose start tag
+// is at offset in src, returning its start and end offsets.
+func matchingEndTag(src []byte, offset int, tag string) (start, end int, ok bool) {
+	z := html.NewTokenizer(bytes.NewReader(src[offset:]))
+	pos, depth := offset, 0
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			return 0, 0, false
+		}
+		raw := len(z.Raw())
+		name, _ := z.TagName()
+		if string(name) == tag {
+			switch tt {
+			case html.StartTagToken:
+				depth++
+			case html.EndTagToken:
+				depth--
+				if depth == 0 {
+					return pos, pos + raw, true
+				}
+			}
+		}
+		pos += raw
+	}
+}
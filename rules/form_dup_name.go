@@ -19,15 +19,15 @@ func (r *FormDupName) Description() string {
 	return "form controls should have unique names (except radio/checkbox groups)"
 }
 
-// Check examines the document for duplicate names within forms.
-func (r *FormDupName) Check(doc *parser.Document) []Result {
-	var results []Result
+// DefaultSeverity returns the most severe level this rule reports at.
+func (r *FormDupName) DefaultSeverity() Severity { return Warning }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode || !n.IsElement("form") {
-			return true
-		}
+// Check examines the document for duplicate names within forms.
+func (r *FormDupName) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+// Visit checks each <form> element.
+func (r *FormDupName) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		// Collect all named controls in this form
 		// Map name -> list of (element, type)
 		type controlInfo struct {
@@ -86,10 +86,10 @@ func (r *FormDupName) Check(doc *parser.Document) []Result {
 				// Report duplicate for non-radio/checkbox controls
 				for i := 1; i < len(controls); i++ {
 					ctrl := controls[i]
-					results = append(results, Result{
+					v.Report(Result{
 						Rule:     RuleFormDupName,
 						Message:  "duplicate form control name: " + name,
-						Filename: doc.Filename,
+						Filename: v.Doc.Filename,
 						Line:     ctrl.node.Line,
 						Col:      ctrl.node.Col,
 						Severity: Warning,
@@ -97,9 +97,5 @@ func (r *FormDupName) Check(doc *parser.Document) []Result {
 				}
 			}
 		}
-
-		return true
-	})
-
-	return results
+	}, "form")
 }
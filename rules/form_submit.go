@@ -14,35 +14,29 @@ func (r *FormSubmit) Description() string {
 	return "forms must have a submit button (WCAG H32)"
 }
 
-func (r *FormSubmit) Check(doc *parser.Document) []Result {
-	var results []Result
+func (r *FormSubmit) DefaultSeverity() Severity { return Error }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode || !n.IsElement("form") {
-			return true
-		}
+func (r *FormSubmit) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+func (r *FormSubmit) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		// Skip forms that use HTMX for submission (they submit via JavaScript)
 		if isHTMXForm(n) {
-			return true
+			return
 		}
 
 		// Check if form has a submit button
 		if !HasDescendant(n, isSubmitButton) {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     r.Name(),
 				Message:  "form has no submit button",
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Error,
 			})
 		}
-
-		return true
-	})
-
-	return results
+	}, "form")
 }
 
 // isHTMXForm checks if a form uses HTMX for submission via hx-* attributes.
@@ -2,10 +2,8 @@ package rules
 
 import (
 	"fmt"
-	"strings"
 
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // HeadingContent checks that heading elements have text content.
@@ -17,31 +15,21 @@ func (r *HeadingContent) Description() string {
 	return "heading elements (h1-h6) must have text content"
 }
 
-func (r *HeadingContent) Check(doc *parser.Document) []Result {
-	var results []Result
+func (r *HeadingContent) DefaultSeverity() Severity { return Error }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
-
-		if !HeadingTags[strings.ToLower(n.Data)] {
-			return true
-		}
+func (r *HeadingContent) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+func (r *HeadingContent) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		if !HasAccessibleName(n) {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     r.Name(),
 				Message:  fmt.Sprintf("<%s> element has no text content", n.Data),
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Error,
 			})
 		}
-
-		return true
-	})
-
-	return results
+	}, "h1", "h2", "h3", "h4", "h5", "h6")
 }
@@ -4,7 +4,6 @@ import (
 	"fmt"
 
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // HeadingLevel checks that heading levels don't skip (e.g., h1 to h3).
@@ -16,28 +15,27 @@ func (r *HeadingLevel) Description() string {
 	return "heading levels must not skip (h1 followed by h3 is invalid)"
 }
 
-func (r *HeadingLevel) Check(doc *parser.Document) []Result {
-	var results []Result
-	lastRank := 0
+func (r *HeadingLevel) DefaultSeverity() Severity { return Warning }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
+// IsDocumentRule marks the rule as checking the page as a whole.
+func (r *HeadingLevel) IsDocumentRule() {}
 
+func (r *HeadingLevel) Check(doc *parser.Document) []Result { return visit(r, doc) }
+
+func (r *HeadingLevel) Visit(v *Visitor) {
+	lastRank := 0
+
+	v.OnEnter(func(n *parser.Node) {
 		rank := HeadingRank(n.Data)
-		if rank == 0 {
-			return true
-		}
 
 		// Check for skipped levels
 		// First heading can be any level (templates may be partials)
 		// But subsequent headings must not skip more than one level
 		if lastRank > 0 && rank > lastRank+1 {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     r.Name(),
 				Message:  fmt.Sprintf("heading level skipped from h%d to h%d", lastRank, rank),
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Warning,
@@ -45,8 +43,5 @@ func (r *HeadingLevel) Check(doc *parser.Document) []Result {
 		}
 
 		lastRank = rank
-		return true
-	})
-
-	return results
+	}, "h1", "h2", "h3", "h4", "h5", "h6")
 }
@@ -13,7 +13,30 @@ const TemplateExprPlaceholder = "TMPL"
 
 // IsTemplateExpr returns true if value contains template placeholder.
 func IsTemplateExpr(s string) bool {
-	return strings.Contains(s, TemplateExprPlaceholder) || strings.Contains(s, "{{")
+	return IsTemplateExprDelims(s, parser.DefaultDelims)
+}
+
+// IsTemplateExprDelims is like IsTemplateExpr for templates using the given
+// action delimiters, such as those returned by Document.Delims.
+func IsTemplateExprDelims(s string, delims parser.Delims) bool {
+	return strings.Contains(s, TemplateExprPlaceholder) || strings.Contains(s, delims.OrDefault().Left)
+}
+
+// LiteralAttrPrefix returns the source text of an attribute value up to its
+// first template action, such as "javascript:" for
+// href="javascript:{{.Code}}". ok is false when the value has no actions
+// or no recorded location.
+func LiteralAttrPrefix(n *parser.Node, attr string, doc *parser.Document) (prefix string, ok bool) {
+	span, found := n.AttrSpan(attr)
+	sm := doc.SourceMap()
+	if !found || sm == nil {
+		return "", false
+	}
+	actions := doc.ActionsIn(span.Value)
+	if len(actions) == 0 {
+		return "", false
+	}
+	return string(sm.Original[span.Value.Start.Offset:actions[0].Span.Start.Offset]), true
 }
 
 // Tag returns the lowercase tag name of an element node.
@@ -48,6 +71,17 @@ func NewResult(rule, message string, n *parser.Node, doc *parser.Document, sev S
 	}
 }
 
+// NewAttrResult creates a Result covering an attribute's value, or its name
+// when the value is empty. Falls back to the element's position when the
+// attribute has no recorded source location.
+func NewAttrResult(rule, message string, n *parser.Node, attr string, doc *parser.Document, sev Severity) Result {
+	r := NewResult(rule, message, n, doc, sev)
+	if span, ok := n.AttrSpan(attr); ok {
+		r = r.WithSpan(span.ValueOrKey())
+	}
+	return r
+}
+
 // NormalizeText collapses whitespace and lowercases text for comparison.
 // Removes template placeholders (TMPL) used during parsing.
 func NormalizeText(s string) string {
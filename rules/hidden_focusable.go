@@ -2,7 +2,6 @@ package rules
 
 import (
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // HiddenFocusable checks that aria-hidden elements don't contain focusable content.
@@ -14,50 +13,34 @@ func (r *HiddenFocusable) Description() string {
 	return "focusable elements must not be inside aria-hidden containers"
 }
 
-func (r *HiddenFocusable) Check(doc *parser.Document) []Result {
-	var results []Result
+func (r *HiddenFocusable) DefaultSeverity() Severity { return Error }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
-
-		// Check if this element has aria-hidden="true"
-		if n.GetAttr("aria-hidden") != "true" {
-			return true
-		}
-
-		// Find focusable descendants
-		r.findFocusable(n, doc.Filename, &results)
-
-		// Don't recurse into children from Walk since we handled them
-		return true
-	})
+func (r *HiddenFocusable) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
-	return results
-}
-
-// findFocusable recursively finds focusable elements within a node.
-func (r *HiddenFocusable) findFocusable(n *parser.Node, filename string, results *[]Result) {
-	for _, child := range n.Children {
-		if child.Type != html.ElementNode {
-			r.findFocusable(child, filename, results)
-			continue
-		}
+func (r *HiddenFocusable) Visit(v *Visitor) {
+	// hidden counts the aria-hidden="true" containers the walk is inside
+	hidden := 0
 
-		if r.isFocusable(child) {
-			*results = append(*results, Result{
+	v.OnEnter(func(n *parser.Node) {
+		if hidden > 0 && r.isFocusable(n) {
+			v.Report(Result{
 				Rule:     r.Name(),
-				Message:  child.Data + " is focusable but inside aria-hidden container",
-				Filename: filename,
-				Line:     child.Line,
-				Col:      child.Col,
+				Message:  n.Data + " is focusable but inside aria-hidden container",
+				Filename: v.Doc.Filename,
+				Line:     n.Line,
+				Col:      n.Col,
 				Severity: Error,
 			})
 		}
-
-		r.findFocusable(child, filename, results)
-	}
+		if n.GetAttr("aria-hidden") == "true" {
+			hidden++
+		}
+	})
+	v.OnLeave(func(n *parser.Node) {
+		if n.GetAttr("aria-hidden") == "true" {
+			hidden--
+		}
+	})
 }
 
 // isFocusable checks if an element is natively or explicitly focusable.
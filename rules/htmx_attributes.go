@@ -40,6 +40,9 @@ func (r *HTMXAttributes) Description() string {
 	return "htmx attribute values must be valid"
 }
 
+// DefaultSeverity returns the most severe level this rule reports at.
+func (r *HTMXAttributes) DefaultSeverity() Severity { return Error }
+
 // Valid hx-swap base values.
 var validSwapValues = map[string]bool{
 	"innerhtml":   true,
@@ -212,18 +215,15 @@ var knownHTMXv4Actions = map[string]bool{
 }
 
 // Check examines the document for invalid htmx attribute values.
-func (r *HTMXAttributes) Check(doc *parser.Document) []Result {
+func (r *HTMXAttributes) Check(doc *parser.Document) []Result { return visit(r, doc) }
+
+// Visit checks the hx-* attributes of each element.
+func (r *HTMXAttributes) Visit(v *Visitor) {
 	if !r.htmxEnabled {
-		return nil
+		return
 	}
 
-	var results []Result
-
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
-
+	v.OnEnter(func(n *parser.Node) {
 		for _, attr := range n.Attr {
 			attrName := strings.ToLower(attr.Key)
 
@@ -233,86 +233,89 @@ func (r *HTMXAttributes) Check(doc *parser.Document) []Result {
 
 			// Handle :inherited and :append suffixes (htmx 4 only)
 			baseAttrName := attrName
+			suffixResult := Result{
+				Rule:     RuleHTMXAttributes,
+				Filename: v.Doc.Filename,
+				Line:     n.Line,
+				Col:      n.Col,
+				Severity: Warning,
+			}
+			if span, ok := n.AttrSpan(attr.Key); ok {
+				suffixResult = suffixResult.WithSpan(span.Key)
+			}
 			switch {
 			case strings.HasSuffix(attrName, ":inherited:append"):
 				baseAttrName = strings.TrimSuffix(attrName, ":inherited:append")
 				if r.htmxVersion != "4" {
-					results = append(results, Result{
-						Rule:     RuleHTMXAttributes,
-						Message:  ":inherited:append suffix is only available in htmx 4",
-						Filename: doc.Filename,
-						Line:     n.Line,
-						Col:      n.Col,
-						Severity: Warning,
-					})
+					suffixResult.Message = ":inherited:append suffix is only available in htmx 4"
+					v.Report(suffixResult)
 				}
 			case strings.HasSuffix(attrName, ":inherited"):
 				baseAttrName = strings.TrimSuffix(attrName, ":inherited")
 				if r.htmxVersion != "4" {
-					results = append(results, Result{
-						Rule:     RuleHTMXAttributes,
-						Message:  ":inherited suffix is only available in htmx 4",
-						Filename: doc.Filename,
-						Line:     n.Line,
-						Col:      n.Col,
-						Severity: Warning,
-					})
+					suffixResult.Message = ":inherited suffix is only available in htmx 4"
+					v.Report(suffixResult)
 				}
 			case strings.HasSuffix(attrName, ":append"):
 				baseAttrName = strings.TrimSuffix(attrName, ":append")
 				if r.htmxVersion != "4" {
-					results = append(results, Result{
-						Rule:     RuleHTMXAttributes,
-						Message:  ":append suffix is only available in htmx 4",
-						Filename: doc.Filename,
-						Line:     n.Line,
-						Col:      n.Col,
-						Severity: Warning,
-					})
+					suffixResult.Message = ":append suffix is only available in htmx 4"
+					v.Report(suffixResult)
 				}
 			}
 
 			var validationResults []Result
+			nameValidated := false
 
 			switch {
 			case baseAttrName == "hx-swap":
-				validationResults = r.validateSwap(doc.Filename, n, attr.Val)
+				validationResults = r.validateSwap(v.Doc.Filename, v.Doc.Delims(), n, attr.Val)
 			case baseAttrName == "hx-trigger":
-				validationResults = r.validateTrigger(doc.Filename, n, attr.Val)
+				validationResults = r.validateTrigger(v.Doc.Filename, v.Doc.Delims(), n, attr.Val)
 			case baseAttrName == "hx-target":
-				validationResults = r.validateTarget(doc.Filename, n, attr.Val)
+				validationResults = r.validateTarget(v.Doc.Filename, v.Doc.Delims(), n, attr.Val)
 			case strings.HasPrefix(baseAttrName, "hx-on:") || strings.HasPrefix(baseAttrName, "hx-on-"):
-				validationResults = r.validateHxOn(doc.Filename, n, attr.Key)
+				validationResults = r.validateHxOn(v.Doc.Filename, n, attr.Key)
+				nameValidated = true
 			case baseAttrName == "hx-vals" || baseAttrName == "hx-headers":
-				validationResults = r.validateJSON(doc.Filename, n, baseAttrName, attr.Val)
+				validationResults = r.validateJSON(v.Doc.Filename, v.Doc.Delims(), n, baseAttrName, attr.Val)
 			case baseAttrName == "hx-include":
-				validationResults = r.validateInclude(doc.Filename, n, attr.Val)
+				validationResults = r.validateInclude(v.Doc.Filename, v.Doc.Delims(), n, attr.Val)
 			case strings.HasPrefix(baseAttrName, "hx-status:") || strings.HasPrefix(baseAttrName, "hx-status-"):
-				validationResults = r.validateHxStatus(doc.Filename, n, attr.Key)
+				validationResults = r.validateHxStatus(v.Doc.Filename, n, attr.Key)
+				nameValidated = true
 			}
 
-			results = append(results, validationResults...)
+			// Point at the offending part of the attribute: the name for
+			// hx-on:* and hx-status:* (where the name is validated), else the value
+			span, hasSpan := n.AttrSpan(attr.Key)
+			for _, vr := range validationResults {
+				if hasSpan {
+					if nameValidated {
+						vr = vr.WithSpan(span.Key)
+					} else {
+						vr = vr.WithSpan(span.ValueOrKey())
+					}
+				}
+				v.Report(vr)
+			}
 		}
 
 		// Check for hx-post/hx-get on submit buttons inside forms
-		if submitButtonResult := r.checkSubmitButtonInForm(doc.Filename, n); submitButtonResult != nil {
-			results = append(results, *submitButtonResult)
+		if submitButtonResult := r.checkSubmitButtonInForm(v.Doc.Filename, n); submitButtonResult != nil {
+			v.Report(*submitButtonResult)
 		}
-
-		return true
 	})
-
-	return results
 }
 
 // validateSwap checks hx-swap attribute values.
-func (r *HTMXAttributes) validateSwap(filename string, n *parser.Node, value string) []Result {
+func (r *HTMXAttributes) validateSwap(filename string, delims parser.Delims, n *parser.Node, value string) []Result {
 	if value == "" {
 		return nil // Empty is valid (uses default)
 	}
 
 	// Skip template expressions (both raw and preprocessed)
-	if strings.Contains(value, "{{") || strings.Contains(value, "TMPL") {
+	if IsTemplateExprDelims(value, delims) {
 		return nil
 	}
 
@@ -456,13 +459,13 @@ func (r *HTMXAttributes) validateSwap(filename string, n *parser.Node, value str
 }
 
 // validateTrigger checks hx-trigger attribute values.
-func (r *HTMXAttributes) validateTrigger(filename string, n *parser.Node, value string) []Result {
+func (r *HTMXAttributes) validateTrigger(filename string, delims parser.Delims, n *parser.Node, value string) []Result {
 	if value == "" {
 		return nil
 	}
 
 	// Skip template expressions (both raw and preprocessed)
-	if strings.Contains(value, "{{") || strings.Contains(value, "TMPL") {
+	if IsTemplateExprDelims(value, delims) {
 		return nil
 	}
 
@@ -640,13 +643,13 @@ func (r *HTMXAttributes) validateSingleTrigger(filename string, n *parser.Node,
 }
 
 // validateTarget checks hx-target attribute values.
-func (r *HTMXAttributes) validateTarget(filename string, n *parser.Node, value string) []Result {
+func (r *HTMXAttributes) validateTarget(filename string, delims parser.Delims, n *parser.Node, value string) []Result {
 	if value == "" {
 		return nil
 	}
 
 	// Skip template expressions (both raw and preprocessed)
-	if strings.Contains(value, "{{") || strings.Contains(value, "TMPL") {
+	if IsTemplateExprDelims(value, delims) {
 		return nil
 	}
 
@@ -961,13 +964,13 @@ func (r *HTMXAttributes) isInsideForm(n *parser.Node) bool {
 }
 
 // validateJSON checks hx-vals and hx-headers attribute values for valid JSON syntax.
-func (r *HTMXAttributes) validateJSON(filename string, n *parser.Node, attrName, value string) []Result {
+func (r *HTMXAttributes) validateJSON(filename string, delims parser.Delims, n *parser.Node, attrName, value string) []Result {
 	if value == "" {
 		return nil // Empty is valid
 	}
 
 	// Skip template expressions (both raw and preprocessed)
-	if strings.Contains(value, "{{") || strings.Contains(value, "TMPL") {
+	if IsTemplateExprDelims(value, delims) {
 		return nil
 	}
 
@@ -1013,13 +1016,13 @@ func simplifyJSONError(err error) string {
 }
 
 // validateInclude checks hx-include attribute values for valid CSS selector syntax.
-func (r *HTMXAttributes) validateInclude(filename string, n *parser.Node, value string) []Result {
+func (r *HTMXAttributes) validateInclude(filename string, delims parser.Delims, n *parser.Node, value string) []Result {
 	if value == "" {
 		return nil // Empty is valid (inherits or uses default)
 	}
 
 	// Skip template expressions (both raw and preprocessed)
-	if strings.Contains(value, "{{") || strings.Contains(value, "TMPL") {
+	if IsTemplateExprDelims(value, delims) {
 		return nil
 	}
 
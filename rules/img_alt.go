@@ -2,7 +2,6 @@ package rules
 
 import (
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // ImgAlt checks that all img elements have alt attributes.
@@ -14,24 +13,21 @@ func (r *ImgAlt) Description() string {
 	return "images must have alt attribute for accessibility"
 }
 
-func (r *ImgAlt) Check(doc *parser.Document) []Result {
-	var results []Result
+func (r *ImgAlt) DefaultSeverity() Severity { return Error }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type == html.ElementNode && n.IsElement("img") {
-			if !n.HasAttr("alt") {
-				results = append(results, Result{
-					Rule:     r.Name(),
-					Message:  "img element missing alt attribute",
-					Filename: doc.Filename,
-					Line:     n.Line,
-					Col:      n.Col,
-					Severity: Error,
-				})
-			}
-		}
-		return true
-	})
+func (r *ImgAlt) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
-	return results
+func (r *ImgAlt) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
+		if !n.HasAttr("alt") {
+			v.Report(Result{
+				Rule:     r.Name(),
+				Message:  "img element missing alt attribute",
+				Filename: v.Doc.Filename,
+				Line:     n.Line,
+				Col:      n.Col,
+				Severity: Error,
+			})
+		}
+	}, "img")
 }
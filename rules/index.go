@@ -0,0 +1,103 @@
+package rules
+
+import (
+	"strings"
+
+	"github.com/toba/go-html-validate/parser"
+	"golang.org/x/net/html"
+)
+
+// Index records the elements in a document that rules look up by id or
+// collect across the whole page. Visitor rules share one Index per document
+// through Visitor.Index.
+type Index struct {
+	// IDs maps each id to the elements that have it, in document order
+	IDs map[string][]*parser.Node
+	// Labels lists the label elements in document order
+	Labels []*parser.Node
+	// LabelsFor maps each label for= value to the labels that have it
+	LabelsFor map[string][]*parser.Node
+	// Forms lists the form elements in document order
+	Forms []*parser.Node
+	// Landmarks lists the landmarks in document order
+	Landmarks []Landmark
+}
+
+// Landmark is a landmark element, or any element with an explicit role
+// other than presentation or none.
+type Landmark struct {
+	Node *parser.Node
+	// Role is the element's explicit role, or its implicit one
+	Role string
+	// Name is the accessible name from aria-label or aria-labelledby
+	Name string
+}
+
+// NewIndex builds the index for doc.
+func NewIndex(doc *parser.Document) *Index {
+	idx := &Index{
+		IDs:       make(map[string][]*parser.Node),
+		LabelsFor: make(map[string][]*parser.Node),
+	}
+	doc.Walk(func(n *parser.Node) bool {
+		if n.Type != html.ElementNode {
+			return true
+		}
+		if id := n.GetAttr("id"); id != "" {
+			idx.IDs[id] = append(idx.IDs[id], n)
+		}
+
+		tag := strings.ToLower(n.Data)
+		switch tag {
+		case "label":
+			idx.Labels = append(idx.Labels, n)
+			if forAttr := n.GetAttr("for"); forAttr != "" {
+				idx.LabelsFor[forAttr] = append(idx.LabelsFor[forAttr], n)
+			}
+		case "form":
+			idx.Forms = append(idx.Forms, n)
+		}
+
+		if l, ok := landmark(n, tag); ok {
+			idx.Landmarks = append(idx.Landmarks, l)
+		}
+		return true
+	})
+	return idx
+}
+
+// ByID returns the first element with the given id, or nil.
+func (idx *Index) ByID(id string) *parser.Node {
+	if nodes := idx.IDs[id]; len(nodes) > 0 {
+		return nodes[0]
+	}
+	return nil
+}
+
+// HasID reports whether any element has the given id.
+func (idx *Index) HasID(id string) bool {
+	return len(idx.IDs[id]) > 0
+}
+
+// landmark returns the landmark for an element, if it is one. Forms and
+// sections are only landmarks when they have an accessible name, and an
+// explicit role counts whatever it is.
+func landmark(n *parser.Node, tag string) (Landmark, bool) {
+	role := LandmarkElements[tag]
+	if explicit := n.GetAttr("role"); explicit != "" {
+		role = strings.ToLower(explicit)
+		// role="presentation" or role="none" removes landmark semantics
+		if role == "presentation" || role == "none" {
+			return Landmark{}, false
+		}
+	}
+	if role == "" {
+		return Landmark{}, false
+	}
+
+	name := GetAccessibleName(n)
+	if (tag == "form" || tag == "section") && name == "" {
+		return Landmark{}, false
+	}
+	return Landmark{Node: n, Role: role, Name: name}, true
+}
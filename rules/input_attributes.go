@@ -4,7 +4,6 @@ import (
 	"strings"
 
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // InputAttributesConfig holds htmx configuration for the InputAttributes rule.
@@ -34,19 +33,15 @@ func (r *InputAttributes) Description() string {
 	return "input attributes must be appropriate for input type"
 }
 
-// Check examines the document for invalid input attribute combinations.
-func (r *InputAttributes) Check(doc *parser.Document) []Result {
-	var results []Result
-
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
+// DefaultSeverity returns the most severe level this rule reports at.
+func (r *InputAttributes) DefaultSeverity() Severity { return Warning }
 
-		if strings.ToLower(n.Data) != "input" {
-			return true
-		}
+// Check examines the document for invalid input attribute combinations.
+func (r *InputAttributes) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+// Visit checks each <input> element.
+func (r *InputAttributes) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		// Get input type (defaults to "text")
 		inputType := strings.ToLower(n.GetAttr("type"))
 		if inputType == "" {
@@ -57,7 +52,7 @@ func (r *InputAttributes) Check(doc *parser.Document) []Result {
 		allowedAttrs, hasSpec := InputTypeAttributes[inputType]
 		if !hasSpec {
 			// Unknown input type, skip (attribute-allowed-values handles this)
-			return true
+			return
 		}
 
 		// Check each attribute
@@ -82,10 +77,10 @@ func (r *InputAttributes) Check(doc *parser.Document) []Result {
 			// Handle htmx attributes
 			if IsHTMXAttribute(attrName) {
 				if !r.config.HTMXEnabled {
-					results = append(results, Result{
+					v.Report(Result{
 						Rule:     RuleInputAttributes,
 						Message:  "htmx attribute '" + attrName + "' used but htmx not enabled in config",
-						Filename: doc.Filename,
+						Filename: v.Doc.Filename,
 						Line:     n.Line,
 						Col:      n.Col,
 						Severity: Warning,
@@ -102,29 +97,29 @@ func (r *InputAttributes) Check(doc *parser.Document) []Result {
 
 				if !valid {
 					if v4Only {
-						results = append(results, Result{
+						v.Report(Result{
 							Rule:     RuleInputAttributes,
 							Message:  "htmx attribute '" + attrName + "' is only available in htmx 4",
-							Filename: doc.Filename,
+							Filename: v.Doc.Filename,
 							Line:     n.Line,
 							Col:      n.Col,
 							Severity: Warning,
 						})
 					} else {
-						results = append(results, Result{
+						v.Report(Result{
 							Rule:     RuleInputAttributes,
 							Message:  "unknown htmx attribute '" + attrName + "'",
-							Filename: doc.Filename,
+							Filename: v.Doc.Filename,
 							Line:     n.Line,
 							Col:      n.Col,
 							Severity: Warning,
 						})
 					}
 				} else if deprecated {
-					results = append(results, Result{
+					v.Report(Result{
 						Rule:     RuleInputAttributes,
 						Message:  "htmx attribute '" + attrName + "' is deprecated in htmx 4",
-						Filename: doc.Filename,
+						Filename: v.Doc.Filename,
 						Line:     n.Line,
 						Col:      n.Col,
 						Severity: Warning,
@@ -135,21 +130,17 @@ func (r *InputAttributes) Check(doc *parser.Document) []Result {
 
 			// Check if attribute is valid for this input type
 			if !allowedAttrs[attrName] {
-				results = append(results, Result{
+				v.Report(Result{
 					Rule:     RuleInputAttributes,
 					Message:  "attribute '" + attrName + "' not valid for input type=\"" + inputType + "\"",
-					Filename: doc.Filename,
+					Filename: v.Doc.Filename,
 					Line:     n.Line,
 					Col:      n.Col,
 					Severity: Warning,
 				})
 			}
 		}
-
-		return true
-	})
-
-	return results
+	}, "input")
 }
 
 // isCommonInputAttr returns true for attributes valid on all input types.
@@ -2,7 +2,6 @@ package rules
 
 import (
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // InputLabel checks that form inputs have associated labels.
@@ -14,42 +13,23 @@ func (r *InputLabel) Description() string {
 	return "form inputs must have associated label, aria-label, or aria-labelledby"
 }
 
-func (r *InputLabel) Check(doc *parser.Document) []Result {
-	var results []Result
+func (r *InputLabel) DefaultSeverity() Severity { return Error }
 
-	// Collect all label for= values
-	labelFor := make(map[string]bool)
-	doc.Walk(func(n *parser.Node) bool {
-		if n.IsElement("label") {
-			if forAttr := n.GetAttr("for"); forAttr != "" {
-				labelFor[forAttr] = true
-			}
-		}
-		return true
-	})
-
-	// Check inputs
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
-		if !n.IsElement("input") && !n.IsElement("select") && !n.IsElement("textarea") {
-			return true
-		}
+func (r *InputLabel) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+func (r *InputLabel) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		// Hidden and submit inputs don't need labels
 		if n.IsElement("input") {
 			inputType := n.GetAttr("type")
 			if inputType == "hidden" || inputType == "submit" || inputType == "button" || inputType == "reset" || inputType == "image" {
-				return true
+				return
 			}
 		}
 
 		// Check for accessible label
 		hasLabel := n.HasAttr("aria-label") && n.GetAttr("aria-label") != ""
 
-		// Check aria-label
-
 		// Check aria-labelledby
 		if n.HasAttr("aria-labelledby") && n.GetAttr("aria-labelledby") != "" {
 			hasLabel = true
@@ -57,7 +37,7 @@ func (r *InputLabel) Check(doc *parser.Document) []Result {
 
 		// Check for associated label via id
 		if id := n.GetAttr("id"); id != "" {
-			if labelFor[id] {
+			if len(v.Index().LabelsFor[id]) > 0 {
 				hasLabel = true
 			}
 		}
@@ -76,18 +56,14 @@ func (r *InputLabel) Check(doc *parser.Document) []Result {
 		}
 
 		if !hasLabel {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     r.Name(),
 				Message:  n.Data + " element missing accessible label",
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Error,
 			})
 		}
-
-		return true
-	})
-
-	return results
+	}, "input", "select", "textarea")
 }
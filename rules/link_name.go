@@ -2,7 +2,6 @@ package rules
 
 import (
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // LinkName checks that links have accessible names.
@@ -14,32 +13,26 @@ func (r *LinkName) Description() string {
 	return "links must have text content or aria-label for accessibility"
 }
 
-func (r *LinkName) Check(doc *parser.Document) []Result {
-	var results []Result
+func (r *LinkName) DefaultSeverity() Severity { return Error }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode || !n.IsElement("a") {
-			return true
-		}
+func (r *LinkName) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+func (r *LinkName) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		// Skip anchors without href (not really links)
 		if !n.HasAttr("href") {
-			return true
+			return
 		}
 
 		if !HasAccessibleName(n) {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     r.Name(),
 				Message:  "link element missing accessible name",
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Error,
 			})
 		}
-
-		return true
-	})
-
-	return results
+	}, "a")
 }
@@ -5,47 +5,63 @@ import (
 	"strings"
 
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // MaxTitleLength is the recommended maximum title length for SEO.
 const MaxTitleLength = 70
 
 // LongTitle checks that title elements don't exceed recommended length.
-type LongTitle struct{}
+type LongTitle struct {
+	// MaxLength is the longest title allowed; MaxTitleLength when zero.
+	MaxLength int
+}
 
 func (r *LongTitle) Name() string { return RuleLongTitle }
 
 func (r *LongTitle) Description() string {
-	return "title element should not exceed 70 characters for SEO"
+	return fmt.Sprintf("title element should not exceed %d characters for SEO", r.maxLength())
 }
 
-func (r *LongTitle) Check(doc *parser.Document) []Result {
-	var results []Result
+func (r *LongTitle) DefaultSeverity() Severity { return Warning }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
+func (r *LongTitle) OptionsSchema() *OptionsSchema {
+	minimum := 1
+	return objectOptions(map[string]*OptionsSchema{
+		"maxlength": {Type: "integer", Minimum: &minimum, Description: "Maximum title length in characters (default 70)"},
+	})
+}
 
-		if strings.ToLower(n.Data) != "title" {
-			return true
-		}
+func (r *LongTitle) ConfigureOptions(options map[string]any) error {
+	if n, ok := options["maxlength"].(float64); ok {
+		r.MaxLength = int(n)
+	}
+	return nil
+}
+
+func (r *LongTitle) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+// maxLength returns the configured maximum title length, or the default.
+func (r *LongTitle) maxLength() int {
+	if r.MaxLength <= 0 {
+		return MaxTitleLength
+	}
+	return r.MaxLength
+}
+
+func (r *LongTitle) Visit(v *Visitor) {
+	maxLength := r.maxLength()
+
+	v.OnEnter(func(n *parser.Node) {
 		text := strings.TrimSpace(n.TextContent())
-		if len(text) > MaxTitleLength {
-			results = append(results, Result{
+		if len(text) > maxLength {
+			v.Report(Result{
 				Rule:     r.Name(),
-				Message:  fmt.Sprintf("title text is %d characters, should be at most %d", len(text), MaxTitleLength),
-				Filename: doc.Filename,
+				Message:  fmt.Sprintf("title text is %d characters, should be at most %d", len(text), maxLength),
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Warning,
 			})
 		}
-
-		return true
-	})
-
-	return results
+	}, "title")
 }
@@ -16,15 +16,15 @@ func (r *MapDupName) Description() string {
 	return "area elements within a map should have unique names"
 }
 
-// Check examines the document for duplicate area names within maps.
-func (r *MapDupName) Check(doc *parser.Document) []Result {
-	var results []Result
+// DefaultSeverity returns the most severe level this rule reports at.
+func (r *MapDupName) DefaultSeverity() Severity { return Warning }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode || !n.IsElement("map") {
-			return true
-		}
+// Check examines the document for duplicate area names within maps.
+func (r *MapDupName) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+// Visit checks each <map> element.
+func (r *MapDupName) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		// Collect area names
 		names := make(map[string]*parser.Node)
 		for _, child := range n.Children {
@@ -34,10 +34,10 @@ func (r *MapDupName) Check(doc *parser.Document) []Result {
 					continue
 				}
 				if first, exists := names[name]; exists {
-					results = append(results, Result{
+					v.Report(Result{
 						Rule:     RuleMapDupName,
 						Message:  "duplicate area name: " + name,
-						Filename: doc.Filename,
+						Filename: v.Doc.Filename,
 						Line:     child.Line,
 						Col:      child.Col,
 						Severity: Warning,
@@ -48,9 +48,5 @@ func (r *MapDupName) Check(doc *parser.Document) []Result {
 				}
 			}
 		}
-
-		return true
-	})
-
-	return results
+	}, "map")
 }
@@ -2,7 +2,6 @@ package rules
 
 import (
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // MapIDName checks that map elements have matching id and name attributes.
@@ -16,50 +15,46 @@ func (r *MapIDName) Description() string {
 	return "map element id and name attributes should match for compatibility"
 }
 
-// Check examines the document for map elements with mismatched id and name.
-func (r *MapIDName) Check(doc *parser.Document) []Result {
-	var results []Result
+// DefaultSeverity returns the most severe level this rule reports at.
+func (r *MapIDName) DefaultSeverity() Severity { return Error }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode || !n.IsElement("map") {
-			return true
-		}
+// Check examines the document for map elements with mismatched id and name.
+func (r *MapIDName) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+// Visit checks each <map> element.
+func (r *MapIDName) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		id := n.GetAttr("id")
 		name := n.GetAttr("name")
 
 		// Skip template values
 		if id == TemplateExprPlaceholder || name == TemplateExprPlaceholder {
-			return true
+			return
 		}
 
 		// Map must have name attribute
 		if name == "" {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     RuleMapIDName,
 				Message:  "map element must have name attribute",
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Error,
 			})
-			return true
+			return
 		}
 
 		// If both present, they should match
 		if id != "" && id != name {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     RuleMapIDName,
 				Message:  "map id=\"" + id + "\" and name=\"" + name + "\" should match",
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Warning,
 			})
 		}
-
-		return true
-	})
-
-	return results
+	}, "map")
 }
@@ -4,7 +4,6 @@ import (
 	"strings"
 
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // MetaRefresh checks for auto-refresh meta tags.
@@ -16,37 +15,31 @@ func (r *MetaRefresh) Description() string {
 	return "meta refresh should not be used for auto-redirect (WCAG)"
 }
 
-func (r *MetaRefresh) Check(doc *parser.Document) []Result {
-	var results []Result
+func (r *MetaRefresh) DefaultSeverity() Severity { return Error }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode || !n.IsElement("meta") {
-			return true
-		}
+func (r *MetaRefresh) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+func (r *MetaRefresh) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		httpEquiv := strings.ToLower(n.GetAttr("http-equiv"))
 		if httpEquiv != "refresh" {
-			return true
+			return
 		}
 
 		content := n.GetAttr("content")
 		if content == "" {
-			return true
+			return
 		}
 
 		// Any refresh is problematic for accessibility
 		// Immediate redirects (0 seconds) are less bad but still flagged
-		results = append(results, Result{
+		v.Report(Result{
 			Rule:     r.Name(),
 			Message:  "meta refresh causes automatic page change, disorienting users",
-			Filename: doc.Filename,
+			Filename: v.Doc.Filename,
 			Line:     n.Line,
 			Col:      n.Col,
 			Severity: Error,
 		})
-
-		return true
-	})
-
-	return results
+	}, "meta")
 }
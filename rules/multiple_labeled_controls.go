@@ -16,34 +16,24 @@ func (r *MultipleLabeledControls) Description() string {
 	return "label element should only be associated with one control"
 }
 
-func (r *MultipleLabeledControls) Check(doc *parser.Document) []Result {
-	var results []Result
+func (r *MultipleLabeledControls) DefaultSeverity() Severity { return Error }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
-
-		if strings.ToLower(n.Data) != "label" {
-			return true
-		}
+func (r *MultipleLabeledControls) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+func (r *MultipleLabeledControls) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		controlCount := countLabeledControls(n)
 		if controlCount > 1 {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     r.Name(),
 				Message:  "label is associated with multiple controls",
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Error,
 			})
 		}
-
-		return true
-	})
-
-	return results
+	}, "label")
 }
 
 // countLabeledControls counts controls associated with a label.
@@ -5,7 +5,6 @@ import (
 	"strings"
 
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // NoAbstractRole checks for abstract ARIA roles that shouldn't be used.
@@ -17,36 +16,30 @@ func (r *NoAbstractRole) Description() string {
 	return "abstract ARIA roles must not be used in content"
 }
 
-func (r *NoAbstractRole) Check(doc *parser.Document) []Result {
-	var results []Result
+func (r *NoAbstractRole) DefaultSeverity() Severity { return Error }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
+func (r *NoAbstractRole) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+func (r *NoAbstractRole) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		roleAttr := n.GetAttr("role")
 		if roleAttr == "" {
-			return true
+			return
 		}
 
 		// Role attribute can contain multiple space-separated roles
 		for role := range strings.FieldsSeq(roleAttr) {
 			role = strings.ToLower(role)
 			if AbstractRoles[role] {
-				results = append(results, Result{
+				v.Report(Result{
 					Rule:     r.Name(),
 					Message:  fmt.Sprintf("abstract role %q must not be used", role),
-					Filename: doc.Filename,
+					Filename: v.Doc.Filename,
 					Line:     n.Line,
 					Col:      n.Col,
 					Severity: Error,
 				})
 			}
 		}
-
-		return true
 	})
-
-	return results
 }
@@ -2,7 +2,6 @@ package rules
 
 import (
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // NoAutoplay checks that media elements don't autoplay.
@@ -14,34 +13,23 @@ func (r *NoAutoplay) Description() string {
 	return "media elements should not autoplay (disorienting for users)"
 }
 
-func (r *NoAutoplay) Check(doc *parser.Document) []Result {
-	var results []Result
+func (r *NoAutoplay) DefaultSeverity() Severity { return Warning }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
-
-		// Check video and audio elements
-		if !n.IsElement("video") && !n.IsElement("audio") {
-			return true
-		}
+func (r *NoAutoplay) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+func (r *NoAutoplay) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		if n.HasAttr("autoplay") {
 			// Muted video autoplay is more acceptable (no audio disruption)
 			// but still flag it as a warning
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     r.Name(),
 				Message:  n.Data + " element has autoplay attribute",
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Warning,
 			})
 		}
-
-		return true
-	})
-
-	return results
+	}, "video", "audio")
 }
@@ -18,6 +18,9 @@ func (r *NoConditionalComment) Description() string {
 	return "IE conditional comments should not be used"
 }
 
+// DefaultSeverity returns the most severe level this rule reports at.
+func (r *NoConditionalComment) DefaultSeverity() Severity { return Warning }
+
 // Check examines the document for IE conditional comments.
 func (r *NoConditionalComment) Check(doc *parser.Document) []Result {
 	var results []Result
@@ -4,7 +4,6 @@ import (
 	"strings"
 
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // NoDeprecatedAttr checks for deprecated HTML attributes.
@@ -18,31 +17,36 @@ func (r *NoDeprecatedAttr) Description() string {
 	return "deprecated HTML attributes should not be used"
 }
 
-// Check examines the document for deprecated attributes.
-func (r *NoDeprecatedAttr) Check(doc *parser.Document) []Result {
-	var results []Result
+// DefaultSeverity returns the most severe level this rule reports at.
+func (r *NoDeprecatedAttr) DefaultSeverity() Severity { return Warning }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
+// Check examines the document for deprecated attributes.
+func (r *NoDeprecatedAttr) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+// Visit checks each element.
+func (r *NoDeprecatedAttr) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		tag := strings.ToLower(n.Data)
 
 		for _, attr := range n.Attr {
 			attrName := strings.ToLower(attr.Key)
+			span, hasSpan := n.AttrSpan(attrName)
 
 			// Check element-specific deprecated attributes
 			if elemAttrs, ok := DeprecatedAttributes[tag]; ok {
 				if suggestion, deprecated := elemAttrs[attrName]; deprecated {
-					results = append(results, Result{
+					result := Result{
 						Rule:     RuleNoDeprecatedAttr,
 						Message:  "attribute \"" + attrName + "\" on <" + tag + "> is deprecated; " + suggestion,
-						Filename: doc.Filename,
+						Filename: v.Doc.Filename,
 						Line:     n.Line,
 						Col:      n.Col,
 						Severity: Warning,
-					})
+					}
+					if hasSpan {
+						result = result.WithSpan(span.Span)
+					}
+					v.Report(result)
 					continue
 				}
 			}
@@ -54,20 +58,20 @@ func (r *NoDeprecatedAttr) Check(doc *parser.Document) []Result {
 					if (attrName == "width" || attrName == "height") && NonDeprecatedSizeAttrs[tag] {
 						continue
 					}
-					results = append(results, Result{
+					result := Result{
 						Rule:     RuleNoDeprecatedAttr,
 						Message:  "attribute \"" + attrName + "\" is deprecated; " + suggestion,
-						Filename: doc.Filename,
+						Filename: v.Doc.Filename,
 						Line:     n.Line,
 						Col:      n.Col,
 						Severity: Warning,
-					})
+					}
+					if hasSpan {
+						result = result.WithSpan(span.Span)
+					}
+					v.Report(result)
 				}
 			}
 		}
-
-		return true
 	})
-
-	return results
 }
@@ -4,7 +4,6 @@ import (
 	"strings"
 
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // NoDupAttr checks for duplicate attributes on an element.
@@ -20,34 +19,35 @@ func (r *NoDupAttr) Description() string {
 	return "elements should not have duplicate attributes"
 }
 
-// Check examines the document for duplicate attributes.
-func (r *NoDupAttr) Check(doc *parser.Document) []Result {
-	var results []Result
+// DefaultSeverity returns the most severe level this rule reports at.
+func (r *NoDupAttr) DefaultSeverity() Severity { return Error }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
+// Check examines the document for duplicate attributes.
+func (r *NoDupAttr) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
-		// Track seen attribute names (case-insensitive)
-		seen := make(map[string]bool)
+// Visit checks each element.
+func (r *NoDupAttr) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
+		// Track how often each attribute name was seen (case-insensitive)
+		seen := make(map[string]int)
 		for _, attr := range n.Attr {
 			key := strings.ToLower(attr.Key)
-			if seen[key] {
-				results = append(results, Result{
+			if seen[key] > 0 {
+				result := Result{
 					Rule:     RuleNoDupAttr,
 					Message:  "duplicate attribute: " + attr.Key,
-					Filename: doc.Filename,
+					Filename: v.Doc.Filename,
 					Line:     n.Line,
 					Col:      n.Col,
 					Severity: Error,
-				})
+				}
+				// Point at this occurrence rather than the first one
+				if spans := n.AttrSpans(key); seen[key] < len(spans) {
+					result = result.WithSpan(spans[seen[key]].Span)
+				}
+				v.Report(result)
 			}
-			seen[key] = true
+			seen[key]++
 		}
-
-		return true
 	})
-
-	return results
 }
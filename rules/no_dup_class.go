@@ -4,7 +4,6 @@ import (
 	"strings"
 
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // NoDupClass checks for duplicate class names within a class attribute.
@@ -18,43 +17,77 @@ func (r *NoDupClass) Description() string {
 	return "elements should not have duplicate class names"
 }
 
-// Check examines the document for duplicate class names.
-func (r *NoDupClass) Check(doc *parser.Document) []Result {
-	var results []Result
+// DefaultSeverity returns the most severe level this rule reports at.
+func (r *NoDupClass) DefaultSeverity() Severity { return Warning }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
+// Check examines the document for duplicate class names.
+func (r *NoDupClass) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+// Visit checks each element.
+func (r *NoDupClass) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		classAttr := n.GetAttr("class")
 		if classAttr == "" || classAttr == TemplateExprPlaceholder {
-			return true
+			return
 		}
 
 		// Split class names and check for duplicates
 		classes := strings.Fields(classAttr)
-		seen := make(map[string]bool)
+		seen := make(map[string]int)
 		for _, class := range classes {
 			// Skip template placeholders
 			if class == TemplateExprPlaceholder {
 				continue
 			}
-			if seen[class] {
-				results = append(results, Result{
+			seen[class]++
+			if seen[class] > 1 {
+				v.Report(Result{
 					Rule:     RuleNoDupClass,
 					Message:  "duplicate class name: " + class,
-					Filename: doc.Filename,
+					Filename: v.Doc.Filename,
 					Line:     n.Line,
 					Col:      n.Col,
 					Severity: Warning,
-				})
+				}.WithEdits(v.Doc, r.removeClass(v.Doc, n, class, seen[class])...))
 			}
-			seen[class] = true
 		}
-
-		return true
 	})
+}
+
+// removeClass returns the edit deleting the nth occurrence (counting from 1)
+// of class from n's class attribute, along with the whitespace before it.
+// Attributes built with template actions aren't fixed, since which class
+// names are duplicated can depend on the data.
+func (r *NoDupClass) removeClass(doc *parser.Document, n *parser.Node, class string, nth int) []Edit {
+	a, ok := n.AttrSpan("class")
+	if !ok || doc.SourceMap() == nil || len(doc.ActionsIn(a.Value)) > 0 {
+		return nil
+	}
+	src := doc.SourceMap().Original
+	isSpace := func(c byte) bool {
+		return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+	}
 
-	return results
+	i, end := a.Value.Start.Offset, a.Value.End.Offset
+	prevEnd, count := i, 0
+	for i < end {
+		for i < end && isSpace(src[i]) {
+			i++
+		}
+		start := i
+		for i < end && !isSpace(src[i]) {
+			i++
+		}
+		if start == i {
+			break
+		}
+		if string(src[start:i]) == class {
+			count++
+			if count == nth {
+				return []Edit{{Start: prevEnd, End: i}}
+			}
+		}
+		prevEnd = i
+	}
+	return nil
 }
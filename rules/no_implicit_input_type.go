@@ -1,10 +1,7 @@
 package rules
 
 import (
-	"strings"
-
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // NoImplicitInputType suggests explicit type attribute on input elements.
@@ -18,32 +15,24 @@ func (r *NoImplicitInputType) Description() string {
 	return "input elements should have explicit type attribute"
 }
 
-// Check examines the document for inputs without type.
-func (r *NoImplicitInputType) Check(doc *parser.Document) []Result {
-	var results []Result
-
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
+// DefaultSeverity returns the most severe level this rule reports at.
+func (r *NoImplicitInputType) DefaultSeverity() Severity { return Info }
 
-		if strings.ToLower(n.Data) != "input" {
-			return true
-		}
+// Check examines the document for inputs without type.
+func (r *NoImplicitInputType) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+// Visit checks each <input> element.
+func (r *NoImplicitInputType) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		if !n.HasAttr("type") {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     RuleNoImplicitInputType,
 				Message:  "input should have explicit type attribute (defaults to \"text\")",
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Info,
-			})
+			}.WithEdits(v.Doc, insertAttr(v.Doc, n, `type="text"`)...))
 		}
-
-		return true
-	})
-
-	return results
+	}, "input")
 }
@@ -2,7 +2,6 @@ package rules
 
 import (
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // NoInlineStyle checks for inline style attributes.
@@ -14,27 +13,21 @@ func (r *NoInlineStyle) Description() string {
 	return "avoid inline styles; use classes with separate stylesheets"
 }
 
-func (r *NoInlineStyle) Check(doc *parser.Document) []Result {
-	var results []Result
+func (r *NoInlineStyle) DefaultSeverity() Severity { return Info }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
+func (r *NoInlineStyle) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+func (r *NoInlineStyle) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		if n.GetAttr("style") != "" {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     r.Name(),
 				Message:  "avoid inline style attribute; use CSS classes instead",
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Info,
 			})
 		}
-
-		return true
 	})
-
-	return results
 }
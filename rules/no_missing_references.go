@@ -1,14 +1,20 @@
 package rules
 
 import (
+	"fmt"
+	"path"
 	"strings"
 
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // NoMissingReferences checks that ID references point to existing elements.
-type NoMissingReferences struct{}
+type NoMissingReferences struct {
+	// Ignore lists ids (or path.Match patterns such as "toast-*") that may be
+	// referenced without existing in the document, such as elements added by
+	// scripts.
+	Ignore []string
+}
 
 // Name returns the rule identifier.
 func (r *NoMissingReferences) Name() string { return RuleNoMissingReferences }
@@ -18,35 +24,61 @@ func (r *NoMissingReferences) Description() string {
 	return "ID references must point to existing elements"
 }
 
-// Check examines the document for broken ID references.
-func (r *NoMissingReferences) Check(doc *parser.Document) []Result {
-	var results []Result
+// DefaultSeverity returns the most severe level this rule reports at.
+func (r *NoMissingReferences) DefaultSeverity() Severity { return Error }
 
-	// First pass: collect all IDs
-	ids := make(map[string]bool)
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
-		if id := n.GetAttr("id"); id != "" {
-			ids[id] = true
-		}
-		return true
+// IsDocumentRule marks the rule as checking the page as a whole.
+func (r *NoMissingReferences) IsDocumentRule() {}
+
+// OptionsSchema describes the rule's options.
+func (r *NoMissingReferences) OptionsSchema() *OptionsSchema {
+	return objectOptions(map[string]*OptionsSchema{
+		"ignore": {
+			Type:        "array",
+			Items:       &OptionsSchema{Type: "string"},
+			Description: "Ids, or glob patterns such as toast-*, that may be referenced without existing in the document",
+		},
 	})
+}
+
+// ConfigureOptions sets the ignored ids from options.
+func (r *NoMissingReferences) ConfigureOptions(options map[string]any) error {
+	if _, ok := options["ignore"]; ok {
+		r.Ignore = stringsOption(options, "ignore")
+	}
+	for _, pattern := range r.Ignore {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("options.ignore: invalid pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
 
-	// Second pass: check references
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
+// ignored reports whether references to id are never reported.
+func (r *NoMissingReferences) ignored(id string) bool {
+	for _, pattern := range r.Ignore {
+		if matched, _ := path.Match(pattern, id); matched {
 			return true
 		}
+	}
+	return false
+}
+
+// Check examines the document for broken ID references.
+func (r *NoMissingReferences) Check(doc *parser.Document) []Result { return visit(r, doc) }
+
+// Visit checks the id references of each element against the document's ids.
+func (r *NoMissingReferences) Visit(v *Visitor) {
+	exists := func(id string) bool { return v.Index().HasID(id) || r.ignored(id) }
 
+	v.OnEnter(func(n *parser.Node) {
 		// Check for attribute
 		if forID := n.GetAttr("for"); forID != "" {
-			if !ids[forID] && !IsTemplateExpr(forID) {
-				results = append(results, Result{
+			if !exists(forID) && !IsTemplateExprDelims(forID, v.Doc.Delims()) {
+				v.Report(Result{
 					Rule:     RuleNoMissingReferences,
 					Message:  "for=\"" + forID + "\" references non-existent id",
-					Filename: doc.Filename,
+					Filename: v.Doc.Filename,
 					Line:     n.Line,
 					Col:      n.Col,
 					Severity: Error,
@@ -57,11 +89,11 @@ func (r *NoMissingReferences) Check(doc *parser.Document) []Result {
 		// Check aria-labelledby (space-separated list)
 		if labelledby := n.GetAttr("aria-labelledby"); labelledby != "" {
 			for id := range strings.FieldsSeq(labelledby) {
-				if !ids[id] && !IsTemplateExpr(id) {
-					results = append(results, Result{
+				if !exists(id) && !IsTemplateExprDelims(id, v.Doc.Delims()) {
+					v.Report(Result{
 						Rule:     RuleNoMissingReferences,
 						Message:  "aria-labelledby references non-existent id: " + id,
-						Filename: doc.Filename,
+						Filename: v.Doc.Filename,
 						Line:     n.Line,
 						Col:      n.Col,
 						Severity: Error,
@@ -73,11 +105,11 @@ func (r *NoMissingReferences) Check(doc *parser.Document) []Result {
 		// Check aria-describedby (space-separated list)
 		if describedby := n.GetAttr("aria-describedby"); describedby != "" {
 			for id := range strings.FieldsSeq(describedby) {
-				if !ids[id] && !IsTemplateExpr(id) {
-					results = append(results, Result{
+				if !exists(id) && !IsTemplateExprDelims(id, v.Doc.Delims()) {
+					v.Report(Result{
 						Rule:     RuleNoMissingReferences,
 						Message:  "aria-describedby references non-existent id: " + id,
-						Filename: doc.Filename,
+						Filename: v.Doc.Filename,
 						Line:     n.Line,
 						Col:      n.Col,
 						Severity: Error,
@@ -89,11 +121,11 @@ func (r *NoMissingReferences) Check(doc *parser.Document) []Result {
 		// Check aria-controls (space-separated list)
 		if controls := n.GetAttr("aria-controls"); controls != "" {
 			for id := range strings.FieldsSeq(controls) {
-				if !ids[id] && !IsTemplateExpr(id) {
-					results = append(results, Result{
+				if !exists(id) && !IsTemplateExprDelims(id, v.Doc.Delims()) {
+					v.Report(Result{
 						Rule:     RuleNoMissingReferences,
 						Message:  "aria-controls references non-existent id: " + id,
-						Filename: doc.Filename,
+						Filename: v.Doc.Filename,
 						Line:     n.Line,
 						Col:      n.Col,
 						Severity: Warning,
@@ -105,11 +137,11 @@ func (r *NoMissingReferences) Check(doc *parser.Document) []Result {
 		// Check aria-owns (space-separated list)
 		if owns := n.GetAttr("aria-owns"); owns != "" {
 			for id := range strings.FieldsSeq(owns) {
-				if !ids[id] && !IsTemplateExpr(id) {
-					results = append(results, Result{
+				if !exists(id) && !IsTemplateExprDelims(id, v.Doc.Delims()) {
+					v.Report(Result{
 						Rule:     RuleNoMissingReferences,
 						Message:  "aria-owns references non-existent id: " + id,
-						Filename: doc.Filename,
+						Filename: v.Doc.Filename,
 						Line:     n.Line,
 						Col:      n.Col,
 						Severity: Warning,
@@ -120,11 +152,11 @@ func (r *NoMissingReferences) Check(doc *parser.Document) []Result {
 
 		// Check list attribute on input
 		if list := n.GetAttr("list"); list != "" {
-			if !ids[list] && !IsTemplateExpr(list) {
-				results = append(results, Result{
+			if !exists(list) && !IsTemplateExprDelims(list, v.Doc.Delims()) {
+				v.Report(Result{
 					Rule:     RuleNoMissingReferences,
 					Message:  "list=\"" + list + "\" references non-existent datalist",
-					Filename: doc.Filename,
+					Filename: v.Doc.Filename,
 					Line:     n.Line,
 					Col:      n.Col,
 					Severity: Error,
@@ -135,11 +167,11 @@ func (r *NoMissingReferences) Check(doc *parser.Document) []Result {
 		// Check headers attribute on td/th (space-separated list)
 		if headers := n.GetAttr("headers"); headers != "" {
 			for id := range strings.FieldsSeq(headers) {
-				if !ids[id] && !IsTemplateExpr(id) {
-					results = append(results, Result{
+				if !exists(id) && !IsTemplateExprDelims(id, v.Doc.Delims()) {
+					v.Report(Result{
 						Rule:     RuleNoMissingReferences,
 						Message:  "headers references non-existent id: " + id,
-						Filename: doc.Filename,
+						Filename: v.Doc.Filename,
 						Line:     n.Line,
 						Col:      n.Col,
 						Severity: Error,
@@ -151,22 +183,18 @@ func (r *NoMissingReferences) Check(doc *parser.Document) []Result {
 		// Check usemap attribute (starts with #)
 		if usemap := n.GetAttr("usemap"); usemap != "" && strings.HasPrefix(usemap, "#") {
 			mapName := usemap[1:] // Remove #
-			if !ids[mapName] && !IsTemplateExpr(mapName) {
+			if !exists(mapName) && !IsTemplateExprDelims(mapName, v.Doc.Delims()) {
 				// usemap references name attribute, not id, but often they match
 				// This is a simplified check
-				results = append(results, Result{
+				v.Report(Result{
 					Rule:     RuleNoMissingReferences,
 					Message:  "usemap=\"" + usemap + "\" may reference non-existent map",
-					Filename: doc.Filename,
+					Filename: v.Doc.Filename,
 					Line:     n.Line,
 					Col:      n.Col,
 					Severity: Warning,
 				})
 			}
 		}
-
-		return true
 	})
-
-	return results
 }
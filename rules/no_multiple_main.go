@@ -2,7 +2,6 @@ package rules
 
 import (
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // NoMultipleMain ensures only one visible <main> element per document.
@@ -14,40 +13,35 @@ func (r *NoMultipleMain) Description() string {
 	return "only one visible <main> element allowed per document"
 }
 
-func (r *NoMultipleMain) Check(doc *parser.Document) []Result {
-	var visibleMains []*parser.Node
+func (r *NoMultipleMain) DefaultSeverity() Severity { return Error }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode || !n.IsElement("main") {
-			return true
-		}
+// IsDocumentRule marks the rule as checking the page as a whole.
+func (r *NoMultipleMain) IsDocumentRule() {}
+
+func (r *NoMultipleMain) Check(doc *parser.Document) []Result { return visit(r, doc) }
+
+func (r *NoMultipleMain) Visit(v *Visitor) {
+	// Report error on all but the first visible main element
+	seen := false
 
+	v.OnEnter(func(n *parser.Node) {
 		// Check if element is hidden
 		if n.HasAttr("hidden") {
-			return true
+			return
 		}
 
-		visibleMains = append(visibleMains, n)
-		return true
-	})
-
-	if len(visibleMains) <= 1 {
-		return nil
-	}
+		if !seen {
+			seen = true
+			return
+		}
 
-	// Report error on all but the first main element
-	var results []Result
-	for i := 1; i < len(visibleMains); i++ {
-		n := visibleMains[i]
-		results = append(results, Result{
+		v.Report(Result{
 			Rule:     r.Name(),
 			Message:  "document has multiple visible <main> elements; remove this <main> or add hidden attribute",
-			Filename: doc.Filename,
+			Filename: v.Doc.Filename,
 			Line:     n.Line,
 			Col:      n.Col,
 			Severity: Error,
 		})
-	}
-
-	return results
+	}, "main")
 }
@@ -16,18 +16,18 @@ func (r *NoRedundantFor) Description() string {
 	return "label for attribute is redundant when label wraps the control"
 }
 
-// Check examines the document for redundant for attributes on labels.
-func (r *NoRedundantFor) Check(doc *parser.Document) []Result {
-	var results []Result
+// DefaultSeverity returns the most severe level this rule reports at.
+func (r *NoRedundantFor) DefaultSeverity() Severity { return Info }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode || !n.IsElement("label") {
-			return true
-		}
+// Check examines the document for redundant for attributes on labels.
+func (r *NoRedundantFor) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+// Visit checks each <label> element.
+func (r *NoRedundantFor) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		forAttr := n.GetAttr("for")
 		if forAttr == "" || forAttr == TemplateExprPlaceholder {
-			return true
+			return
 		}
 
 		// Check if label contains a labelable element with matching id
@@ -52,18 +52,14 @@ func (r *NoRedundantFor) Check(doc *parser.Document) []Result {
 		checkChildren(n)
 
 		if hasMatchingChild {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     RuleNoRedundantFor,
 				Message:  "label for=\"" + forAttr + "\" is redundant when label wraps the control",
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Info,
 			})
 		}
-
-		return true
-	})
-
-	return results
+	}, "label")
 }
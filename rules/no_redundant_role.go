@@ -5,7 +5,6 @@ import (
 	"strings"
 
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // NoRedundantRole checks for explicit roles that match implicit roles.
@@ -17,35 +16,29 @@ func (r *NoRedundantRole) Description() string {
 	return "element should not have role matching its implicit role"
 }
 
-func (r *NoRedundantRole) Check(doc *parser.Document) []Result {
-	var results []Result
+func (r *NoRedundantRole) DefaultSeverity() Severity { return Warning }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
+func (r *NoRedundantRole) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+func (r *NoRedundantRole) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		role := strings.ToLower(n.GetAttr("role"))
 		if role == "" {
-			return true
+			return
 		}
 
 		tagName := strings.ToLower(n.Data)
 		implicitRole := GetImplicitRole(tagName, n)
 
 		if role == implicitRole {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     r.Name(),
 				Message:  fmt.Sprintf("<%s> has implicit role %q, explicit role is redundant", tagName, role),
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Warning,
 			})
 		}
-
-		return true
 	})
-
-	return results
 }
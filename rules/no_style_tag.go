@@ -1,10 +1,7 @@
 package rules
 
 import (
-	"strings"
-
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // NoStyleTag discourages use of inline <style> tags.
@@ -18,28 +15,22 @@ func (r *NoStyleTag) Description() string {
 	return "inline <style> tags should be avoided; use external stylesheets"
 }
 
-// Check examines the document for <style> tags.
-func (r *NoStyleTag) Check(doc *parser.Document) []Result {
-	var results []Result
-
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
+// DefaultSeverity returns the most severe level this rule reports at.
+func (r *NoStyleTag) DefaultSeverity() Severity { return Info }
 
-		if strings.ToLower(n.Data) == "style" {
-			results = append(results, Result{
-				Rule:     RuleNoStyleTag,
-				Message:  "inline <style> tags are discouraged; use external stylesheets",
-				Filename: doc.Filename,
-				Line:     n.Line,
-				Col:      n.Col,
-				Severity: Info,
-			})
-		}
-
-		return true
-	})
-
-	return results
+// Check examines the document for <style> tags.
+func (r *NoStyleTag) Check(doc *parser.Document) []Result { return visit(r, doc) }
+
+// Visit checks each <style> element.
+func (r *NoStyleTag) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
+		v.Report(Result{
+			Rule:     RuleNoStyleTag,
+			Message:  "inline <style> tags are discouraged; use external stylesheets",
+			Filename: v.Doc.Filename,
+			Line:     n.Line,
+			Col:      n.Col,
+			Severity: Info,
+		})
+	}, "style")
 }
@@ -15,6 +15,9 @@ func (r *NoUTF8BOM) Description() string {
 	return "files should not have UTF-8 BOM"
 }
 
+// DefaultSeverity returns the most severe level this rule reports at.
+func (r *NoUTF8BOM) DefaultSeverity() Severity { return Warning }
+
 // Check examines the document for UTF-8 BOM.
 // Note: This check needs raw file content, which the parser may strip.
 // The linter should check for BOM before parsing if needed.
@@ -0,0 +1,139 @@
+package rules
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+)
+
+// OptionsSchema describes the options a rule accepts. It is the subset of
+// JSON Schema needed for rule options: it marshals to a schema fragment that
+// can be published for editors, and Validate checks decoded JSON against it.
+type OptionsSchema struct {
+	// Type is "object", "string", "integer", "boolean" or "array".
+	Type        string                    `json:"type"`
+	Description string                    `json:"description,omitempty"`
+	Properties  map[string]*OptionsSchema `json:"properties,omitempty"`
+	// AdditionalProperties is false for objects, which reject unknown keys.
+	AdditionalProperties *bool          `json:"additionalProperties,omitempty"`
+	Items                *OptionsSchema `json:"items,omitempty"`
+	Enum                 []string       `json:"enum,omitempty"`
+	Minimum              *int           `json:"minimum,omitempty"`
+	// Format "regex" requires strings to compile as regular expressions.
+	Format string `json:"format,omitempty"`
+}
+
+// objectOptions returns a schema for an options object with the given
+// properties and no others.
+func objectOptions(properties map[string]*OptionsSchema) *OptionsSchema {
+	closed := false
+	return &OptionsSchema{Type: "object", Properties: properties, AdditionalProperties: &closed}
+}
+
+// Validate checks a value decoded from JSON against the schema.
+func (s *OptionsSchema) Validate(v any) error {
+	return s.validate(v, "options")
+}
+
+func (s *OptionsSchema) validate(v any, path string) error {
+	switch s.Type {
+	case "object":
+		m, ok := v.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: must be an object", path)
+		}
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			prop, ok := s.Properties[k]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					return fmt.Errorf("%s: unknown option %q", path, k)
+				}
+				continue
+			}
+			if err := prop.validate(m[k], path+"."+k); err != nil {
+				return err
+			}
+		}
+
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("%s: must be a string", path)
+		}
+		if len(s.Enum) > 0 && !slices.Contains(s.Enum, str) {
+			return fmt.Errorf("%s: must be one of %v", path, s.Enum)
+		}
+		if s.Format == "regex" {
+			if _, err := regexp.Compile(str); err != nil {
+				return fmt.Errorf("%s: invalid regular expression: %w", path, err)
+			}
+		}
+
+	case "integer":
+		n, ok := v.(float64)
+		if !ok || n != math.Trunc(n) {
+			return fmt.Errorf("%s: must be an integer", path)
+		}
+		if s.Minimum != nil && n < float64(*s.Minimum) {
+			return fmt.Errorf("%s: must be at least %d", path, *s.Minimum)
+		}
+
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return fmt.Errorf("%s: must be true or false", path)
+		}
+
+	case "array":
+		items, ok := v.([]any)
+		if !ok {
+			return fmt.Errorf("%s: must be an array", path)
+		}
+		if s.Items != nil {
+			for i, item := range items {
+				if err := s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateOptions checks options for a rule by applying them, so r should be
+// a throwaway instance such as one from NewRegistry. Rules that don't
+// implement Configurable accept any options, so configurations written for
+// html-validate keep working.
+func ValidateOptions(r Rule, options map[string]any) error {
+	c, ok := r.(Configurable)
+	if !ok {
+		return nil
+	}
+	return ConfigureOptions(c, options)
+}
+
+// ConfigureOptions validates options for a rule and applies them.
+func ConfigureOptions(r Configurable, options map[string]any) error {
+	if err := r.OptionsSchema().Validate(options); err != nil {
+		return err
+	}
+	return r.ConfigureOptions(options)
+}
+
+// stringsOption returns a validated array-of-strings option.
+func stringsOption(options map[string]any, key string) []string {
+	items, _ := options[key].([]any)
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
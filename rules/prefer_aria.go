@@ -4,7 +4,6 @@ import (
 	"strings"
 
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // PreferAria checks for custom data attributes that should use ARIA equivalents.
@@ -16,6 +15,8 @@ func (r *PreferAria) Description() string {
 	return "prefer ARIA attributes over custom data-* attributes for accessibility semantics"
 }
 
+func (r *PreferAria) DefaultSeverity() Severity { return Warning }
+
 // ariaEquivalents maps data-* patterns to their ARIA equivalents.
 var ariaEquivalents = map[string]string{
 	"data-label":        "aria-label",
@@ -49,14 +50,10 @@ var ariaEquivalents = map[string]string{
 	"data-placeholder":  "aria-placeholder",
 }
 
-func (r *PreferAria) Check(doc *parser.Document) []Result {
-	var results []Result
-
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode || n.Node == nil {
-			return true
-		}
+func (r *PreferAria) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+func (r *PreferAria) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		for _, attr := range n.Attr {
 			// Check if this is a data-* attribute with an ARIA equivalent
 			if !strings.HasPrefix(attr.Key, "data-") {
@@ -65,10 +62,10 @@ func (r *PreferAria) Check(doc *parser.Document) []Result {
 
 			// Check exact matches
 			if ariaAttr, ok := ariaEquivalents[attr.Key]; ok {
-				results = append(results, Result{
+				v.Report(Result{
 					Rule:     r.Name(),
 					Message:  attr.Key + " should use " + ariaAttr + " instead",
-					Filename: doc.Filename,
+					Filename: v.Doc.Filename,
 					Line:     n.Line,
 					Col:      n.Col,
 					Severity: Warning,
@@ -82,10 +79,10 @@ func (r *PreferAria) Check(doc *parser.Document) []Result {
 				// Check variations: data-is-expanded, data-isexpanded
 				baseName := strings.TrimPrefix(dataAttr, "data-")
 				if strings.Contains(lowerKey, baseName) {
-					results = append(results, Result{
+					v.Report(Result{
 						Rule:     r.Name(),
 						Message:  attr.Key + " appears to indicate state; consider using " + ariaAttr,
-						Filename: doc.Filename,
+						Filename: v.Doc.Filename,
 						Line:     n.Line,
 						Col:      n.Col,
 						Severity: Info,
@@ -94,9 +91,5 @@ func (r *PreferAria) Check(doc *parser.Document) []Result {
 				}
 			}
 		}
-
-		return true
 	})
-
-	return results
 }
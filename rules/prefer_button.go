@@ -5,7 +5,6 @@ import (
 	"strings"
 
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // PreferButton checks for input elements that should be buttons.
@@ -17,6 +16,8 @@ func (r *PreferButton) Description() string {
 	return "prefer <button> over <input type=\"button|submit|reset\">"
 }
 
+func (r *PreferButton) DefaultSeverity() Severity { return Info }
+
 // buttonInputTypes are input types that should use <button> instead.
 var buttonInputTypes = map[string]bool{
 	"button": true,
@@ -25,32 +26,20 @@ var buttonInputTypes = map[string]bool{
 	"image":  true,
 }
 
-func (r *PreferButton) Check(doc *parser.Document) []Result {
-	var results []Result
-
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
-
-		if strings.ToLower(n.Data) != "input" {
-			return true
-		}
+func (r *PreferButton) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+func (r *PreferButton) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		inputType := strings.ToLower(n.GetAttr("type"))
 		if buttonInputTypes[inputType] {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     r.Name(),
 				Message:  fmt.Sprintf("prefer <button> over <input type=%q>", inputType),
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Info,
 			})
 		}
-
-		return true
-	})
-
-	return results
+	}, "input")
 }
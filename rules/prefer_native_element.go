@@ -2,7 +2,6 @@ package rules
 
 import (
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // roleToNative maps ARIA roles to their native HTML element equivalents.
@@ -27,35 +26,29 @@ func (r *PreferNativeElement) Description() string {
 	return "prefer native HTML elements over ARIA roles"
 }
 
-func (r *PreferNativeElement) Check(doc *parser.Document) []Result {
-	var results []Result
+func (r *PreferNativeElement) DefaultSeverity() Severity { return Warning }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
+func (r *PreferNativeElement) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+func (r *PreferNativeElement) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		role := n.GetAttr("role")
 		if role == "" {
-			return true
+			return
 		}
 
 		native, ok := roleToNative[role]
 		if !ok {
-			return true
+			return
 		}
 
-		results = append(results, Result{
+		v.Report(Result{
 			Rule:     r.Name(),
 			Message:  "use " + native + " element instead of <" + n.Data + " role=\"" + role + "\">; native elements have better accessibility support",
-			Filename: doc.Filename,
+			Filename: v.Doc.Filename,
 			Line:     n.Line,
 			Col:      n.Col,
 			Severity: Warning,
 		})
-
-		return true
 	})
-
-	return results
 }
@@ -2,7 +2,6 @@ package rules
 
 import (
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // PreferSemantic checks for non-semantic elements used as interactive controls.
@@ -14,19 +13,12 @@ func (r *PreferSemantic) Description() string {
 	return "prefer semantic elements (button, a) over div/span with click handlers"
 }
 
-func (r *PreferSemantic) Check(doc *parser.Document) []Result {
-	var results []Result
+func (r *PreferSemantic) DefaultSeverity() Severity { return Warning }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
-
-		// Check div and span elements
-		if !n.IsElement("div") && !n.IsElement("span") {
-			return true
-		}
+func (r *PreferSemantic) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+func (r *PreferSemantic) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		// Check for interactive attributes that suggest it should be a button
 		hasClickHandler := n.HasAttr("onclick") ||
 			n.HasAttr("onkeydown") ||
@@ -47,27 +39,23 @@ func (r *PreferSemantic) Check(doc *parser.Document) []Result {
 		hasTabindex := n.HasAttr("tabindex")
 
 		if hasClickHandler || (hasHTMXClick && hasButtonRole) {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     r.Name(),
 				Message:  n.Data + " with click handler should be a <button> element",
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Warning,
 			})
 		} else if hasButtonRole && hasTabindex {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     r.Name(),
 				Message:  n.Data + " with role=\"button\" should be a <button> element",
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Warning,
 			})
 		}
-
-		return true
-	})
-
-	return results
+	}, "div", "span")
 }
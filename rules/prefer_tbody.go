@@ -18,19 +18,15 @@ func (r *PreferTbody) Description() string {
 	return "tables should use explicit <tbody> element"
 }
 
-// Check examines the document for tables without explicit tbody.
-func (r *PreferTbody) Check(doc *parser.Document) []Result {
-	var results []Result
-
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
+// DefaultSeverity returns the most severe level this rule reports at.
+func (r *PreferTbody) DefaultSeverity() Severity { return Info }
 
-		if strings.ToLower(n.Data) != "table" {
-			return true
-		}
+// Check examines the document for tables without explicit tbody.
+func (r *PreferTbody) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+// Visit checks each <table> element.
+func (r *PreferTbody) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		// Check if table has tbody
 		hasTbody := false
 		hasTr := false
@@ -50,18 +46,14 @@ func (r *PreferTbody) Check(doc *parser.Document) []Result {
 
 		// Only report if table has direct tr children without tbody
 		if hasTr && !hasTbody {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     RulePreferTbody,
 				Message:  "table should use explicit <tbody> element",
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Info,
 			})
 		}
-
-		return true
-	})
-
-	return results
+	}, "table")
 }
@@ -2,7 +2,6 @@ package rules
 
 import (
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // RedundantAriaLabel checks for aria-label that duplicates visible text.
@@ -14,17 +13,15 @@ func (r *RedundantAriaLabel) Description() string {
 	return "aria-label should not duplicate visible text content"
 }
 
-func (r *RedundantAriaLabel) Check(doc *parser.Document) []Result {
-	var results []Result
+func (r *RedundantAriaLabel) DefaultSeverity() Severity { return Warning }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
+func (r *RedundantAriaLabel) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+func (r *RedundantAriaLabel) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		ariaLabel := n.GetAttr("aria-label")
 		if ariaLabel == "" {
-			return true
+			return
 		}
 
 		// Get visible text content
@@ -37,18 +34,14 @@ func (r *RedundantAriaLabel) Check(doc *parser.Document) []Result {
 		// Check if they're the same (ignoring case and whitespace)
 		if normalizedAriaLabel != "" && normalizedText != "" &&
 			normalizedAriaLabel == normalizedText {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     r.Name(),
 				Message:  "aria-label duplicates visible text content",
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Warning,
 			})
 		}
-
-		return true
 	})
-
-	return results
 }
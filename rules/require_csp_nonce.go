@@ -18,34 +18,34 @@ func (r *RequireCSPNonce) Description() string {
 	return "inline scripts and styles should have CSP nonce attribute"
 }
 
-// Check examines the document for inline scripts/styles without nonce.
-func (r *RequireCSPNonce) Check(doc *parser.Document) []Result {
-	var results []Result
+// DefaultSeverity returns the most severe level this rule reports at.
+func (r *RequireCSPNonce) DefaultSeverity() Severity { return Info }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
+// Check examines the document for inline scripts/styles without nonce.
+func (r *RequireCSPNonce) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+// Visit checks each element.
+func (r *RequireCSPNonce) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		tag := strings.ToLower(n.Data)
 
 		switch tag {
 		case "script":
 			// External scripts with src don't need nonce (unless they also have inline content)
 			if n.HasAttr("src") && !hasInlineContent(n) {
-				return true
+				return
 			}
 			// Scripts with type that's not JavaScript don't need nonce
 			scriptType := strings.ToLower(n.GetAttr("type"))
 			if scriptType != "" && !isJavaScriptType(scriptType) {
-				return true
+				return
 			}
 			// Check for nonce attribute
 			if !n.HasAttr("nonce") {
-				results = append(results, Result{
+				v.Report(Result{
 					Rule:     RuleRequireCSPNonce,
 					Message:  "inline script should have nonce attribute for CSP",
-					Filename: doc.Filename,
+					Filename: v.Doc.Filename,
 					Line:     n.Line,
 					Col:      n.Col,
 					Severity: Info, // Info level since not all sites use CSP
@@ -55,21 +55,17 @@ func (r *RequireCSPNonce) Check(doc *parser.Document) []Result {
 		case "style":
 			// Check for nonce attribute
 			if !n.HasAttr("nonce") {
-				results = append(results, Result{
+				v.Report(Result{
 					Rule:     RuleRequireCSPNonce,
 					Message:  "inline style should have nonce attribute for CSP",
-					Filename: doc.Filename,
+					Filename: v.Doc.Filename,
 					Line:     n.Line,
 					Col:      n.Col,
 					Severity: Info, // Info level since not all sites use CSP
 				})
 			}
 		}
-
-		return true
 	})
-
-	return results
 }
 
 // hasInlineContent checks if element has non-whitespace text content.
@@ -2,7 +2,6 @@ package rules
 
 import (
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // RequireLang ensures <html> elements have a lang attribute.
@@ -14,36 +13,30 @@ func (r *RequireLang) Description() string {
 	return "<html> element must have a lang attribute"
 }
 
-func (r *RequireLang) Check(doc *parser.Document) []Result {
-	var results []Result
+func (r *RequireLang) DefaultSeverity() Severity { return Error }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode || !n.IsElement("html") {
-			return true
-		}
+func (r *RequireLang) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+func (r *RequireLang) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		if !n.HasAttr("lang") {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     r.Name(),
 				Message:  "<html> element must have a lang attribute; add lang=\"en\" for English content",
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Error,
 			})
 		} else if n.GetAttr("lang") == "" {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     r.Name(),
 				Message:  "lang attribute must not be empty; use BCP 47 code like \"en\" or \"en-US\"",
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Error,
 			})
 		}
-
-		return true
-	})
-
-	return results
+	}, "html")
 }
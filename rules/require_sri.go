@@ -4,7 +4,6 @@ import (
 	"strings"
 
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // RequireSRI checks that external scripts and stylesheets have integrity attributes.
@@ -16,30 +15,28 @@ func (r *RequireSRI) Description() string {
 	return "external resources should have subresource integrity (integrity attribute)"
 }
 
-func (r *RequireSRI) Check(doc *parser.Document) []Result {
-	var results []Result
+func (r *RequireSRI) DefaultSeverity() Severity { return Warning }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
+func (r *RequireSRI) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+func (r *RequireSRI) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		tagName := strings.ToLower(n.Data)
 
 		switch tagName {
 		case "script":
 			src := n.GetAttr("src")
 			if src == "" {
-				return true // inline script
+				return // inline script
 			}
 			if !isExternalURL(src) {
-				return true // local resource
+				return // local resource
 			}
 			if n.GetAttr("integrity") == "" {
-				results = append(results, Result{
+				v.Report(Result{
 					Rule:     r.Name(),
 					Message:  "external script missing integrity attribute for SRI",
-					Filename: doc.Filename,
+					Filename: v.Doc.Filename,
 					Line:     n.Line,
 					Col:      n.Col,
 					Severity: Warning,
@@ -50,31 +47,27 @@ func (r *RequireSRI) Check(doc *parser.Document) []Result {
 			rel := strings.ToLower(n.GetAttr("rel"))
 			// Only check stylesheets and preloads
 			if rel != "stylesheet" && rel != "preload" && rel != "modulepreload" {
-				return true
+				return
 			}
 			href := n.GetAttr("href")
 			if href == "" {
-				return true
+				return
 			}
 			if !isExternalURL(href) {
-				return true // local resource
+				return // local resource
 			}
 			if n.GetAttr("integrity") == "" {
-				results = append(results, Result{
+				v.Report(Result{
 					Rule:     r.Name(),
 					Message:  "external stylesheet missing integrity attribute for SRI",
-					Filename: doc.Filename,
+					Filename: v.Doc.Filename,
 					Line:     n.Line,
 					Col:      n.Col,
 					Severity: Warning,
 				})
 			}
 		}
-
-		return true
 	})
-
-	return results
 }
 
 // isExternalURL checks if a URL points to an external resource.
@@ -109,6 +109,11 @@ const (
 	RuleHTMXAttributes              = "htmx-attributes"
 	RuleTemplateWhitespaceTrim      = "template-whitespace-trim"
 	RuleTemplateSyntaxValid         = "template-syntax-valid"
+	RuleTemplateUndefined           = "template-undefined"
+	RuleTemplateUnused              = "template-unused"
+	RuleTemplateTypeCheck           = "template-type-check"
+	RuleTemplateEscapeHazard        = "template-escape-hazard"
+	RuleDisableDirective            = "disable-directive"
 )
 
 // Result represents a single lint finding.
@@ -118,10 +123,26 @@ type Result struct {
 	Filename string   // Source file path
 	Line     int      // 1-indexed line number
 	Col      int      // 1-indexed column number
+	EndLine  int      // 1-indexed end line of the range, 0 if unknown
+	EndCol   int      // 1-indexed end column (exclusive), 0 if unknown
 	Severity Severity // Error, Warning, or Info
+	Edits    []Edit   // Edits to the original source that fix the problem, if any
+}
+
+// WithSpan returns a copy of the result covering the given source range.
+func (r Result) WithSpan(s parser.Span) Result {
+	r.Line, r.Col = s.Start.Line, s.Start.Col
+	r.EndLine, r.EndCol = s.End.Line, s.End.Col
+	return r
 }
 
 // Rule defines the interface for accessibility rules.
+//
+// A linter shares one instance of each rule between the goroutines linting
+// files, so Check may run concurrently and must not modify the rule. Rules
+// are configured (through the *Configurable interfaces) once, before the
+// first Check; state built up while checking, such as a cache, needs its
+// own locking.
 type Rule interface {
 	// Name returns the rule identifier (e.g., "img-alt")
 	Name() string
@@ -141,6 +162,53 @@ type HTMXCustomEventsConfigurable interface {
 	ConfigureCustomEvents(events []string)
 }
 
+// TemplateEntryPointsConfigurable is implemented by rules that need the names
+// of templates executed directly from Go code.
+type TemplateEntryPointsConfigurable interface {
+	ConfigureEntryPoints(names []string)
+}
+
+// Configurable is implemented by rules that take options from the
+// ["error", {...}] form of their configuration.
+type Configurable interface {
+	Rule
+	// OptionsSchema describes the options the rule accepts.
+	OptionsSchema() *OptionsSchema
+	// ConfigureOptions applies options already validated against
+	// OptionsSchema. Options that are absent keep their defaults.
+	ConfigureOptions(options map[string]any) error
+}
+
+// SeverityDefaulter is implemented by rules that declare the most severe
+// level they report problems at, before any configured override, for tools
+// that list rules before they report anything.
+type SeverityDefaulter interface {
+	Rule
+	DefaultSeverity() Severity
+}
+
+// DefaultSeverity returns the most severe level r reports problems at.
+// Rules that don't implement SeverityDefaulter are warnings.
+func DefaultSeverity(r Rule) Severity {
+	if d, ok := r.(SeverityDefaulter); ok {
+		return d.DefaultSeverity()
+	}
+	return Warning
+}
+
+// TemplateDataConfigurable is implemented by rules that need the Go type a
+// template file is executed with, as an "import/path.Type" spec.
+type TemplateDataConfigurable interface {
+	ConfigureTemplateData(dataFor func(filename string) string)
+}
+
+// Resettable is implemented by rules that keep state between documents,
+// such as loaded Go packages. The linter calls Reset at the start of each
+// run, so nothing is carried over to the next one.
+type Resettable interface {
+	Reset()
+}
+
 // RawRule is implemented by rules that need access to the raw file content
 // before template preprocessing. This allows linting template syntax itself.
 type RawRule interface {
@@ -148,6 +216,24 @@ type RawRule interface {
 	CheckRaw(filename string, content []byte) []Result
 }
 
+// DocumentRule is implemented by rules whose findings depend on the whole
+// rendered page, such as counting landmarks or resolving ID references.
+// When a template set is configured, the linter checks these rules against
+// entry-point pages with their {{template}} calls inlined, rather than
+// against each file on its own.
+type DocumentRule interface {
+	Rule
+	IsDocumentRule()
+}
+
+// TemplateRawRule is implemented by raw rules that look for template actions
+// and so need the delimiters in effect for the file. The linter calls
+// CheckRawDelims instead of CheckRaw for these rules.
+type TemplateRawRule interface {
+	RawRule
+	CheckRawDelims(filename string, content []byte, delims parser.Delims) []Result
+}
+
 // Registry holds all available rules.
 type Registry struct {
 	rules []Rule
@@ -255,6 +341,12 @@ func NewRegistry() *Registry {
 			// Template rules
 			&TemplateWhitespaceTrim{},
 			&TemplateSyntaxValid{},
+			&TemplateUndefined{},
+			&TemplateUnused{},
+			&TemplateTypeCheck{},
+			&TemplateEscapeHazard{},
+			// Inline directive rules
+			&DisableDirective{},
 		},
 	}
 }
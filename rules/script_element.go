@@ -18,19 +18,15 @@ func (r *ScriptElement) Description() string {
 	return "script elements must follow HTML5 constraints"
 }
 
-// Check examines the document for script element issues.
-func (r *ScriptElement) Check(doc *parser.Document) []Result {
-	var results []Result
-
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
+// DefaultSeverity returns the most severe level this rule reports at.
+func (r *ScriptElement) DefaultSeverity() Severity { return Error }
 
-		if strings.ToLower(n.Data) != "script" {
-			return true
-		}
+// Check examines the document for script element issues.
+func (r *ScriptElement) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+// Visit checks each <script> element.
+func (r *ScriptElement) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		hasSrc := n.HasAttr("src")
 		hasAsync := n.HasAttr("async")
 		hasDefer := n.HasAttr("defer")
@@ -40,10 +36,10 @@ func (r *ScriptElement) Check(doc *parser.Document) []Result {
 		// async and defer are mutually exclusive for classic scripts
 		// For module scripts, async is allowed but defer is ignored
 		if hasAsync && hasDefer && scriptType != "module" {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     RuleScriptElement,
 				Message:  "script should not have both async and defer",
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Warning,
@@ -53,20 +49,20 @@ func (r *ScriptElement) Check(doc *parser.Document) []Result {
 		// async and defer only make sense with src
 		if !hasSrc {
 			if hasAsync {
-				results = append(results, Result{
+				v.Report(Result{
 					Rule:     RuleScriptElement,
 					Message:  "async attribute requires src attribute",
-					Filename: doc.Filename,
+					Filename: v.Doc.Filename,
 					Line:     n.Line,
 					Col:      n.Col,
 					Severity: Error,
 				})
 			}
 			if hasDefer {
-				results = append(results, Result{
+				v.Report(Result{
 					Rule:     RuleScriptElement,
 					Message:  "defer attribute requires src attribute",
-					Filename: doc.Filename,
+					Filename: v.Doc.Filename,
 					Line:     n.Line,
 					Col:      n.Col,
 					Severity: Error,
@@ -76,10 +72,10 @@ func (r *ScriptElement) Check(doc *parser.Document) []Result {
 
 		// nomodule only makes sense for classic scripts
 		if hasNomodule && scriptType == "module" {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     RuleScriptElement,
 				Message:  "nomodule attribute should not be on module scripts",
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Warning,
@@ -91,10 +87,10 @@ func (r *ScriptElement) Check(doc *parser.Document) []Result {
 			// Check for non-whitespace content
 			for _, child := range n.Children {
 				if child.Type == html.TextNode && strings.TrimSpace(child.Data) != "" {
-					results = append(results, Result{
+					v.Report(Result{
 						Rule:     RuleScriptElement,
 						Message:  "script with src should not have inline content",
-						Filename: doc.Filename,
+						Filename: v.Doc.Filename,
 						Line:     n.Line,
 						Col:      n.Col,
 						Severity: Warning,
@@ -109,19 +105,15 @@ func (r *ScriptElement) Check(doc *parser.Document) []Result {
 			// Unknown type acts as data block (valid but unusual)
 			// Only warn for non-MIME type values that might be mistakes
 			if !strings.Contains(scriptType, "/") {
-				results = append(results, Result{
+				v.Report(Result{
 					Rule:     RuleScriptElement,
 					Message:  "unknown script type: " + scriptType,
-					Filename: doc.Filename,
+					Filename: v.Doc.Filename,
 					Line:     n.Line,
 					Col:      n.Col,
 					Severity: Info,
 				})
 			}
 		}
-
-		return true
-	})
-
-	return results
+	}, "script")
 }
@@ -4,7 +4,6 @@ import (
 	"strings"
 
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // ScriptType checks that script elements have valid type attributes.
@@ -18,41 +17,37 @@ func (r *ScriptType) Description() string {
 	return "script type attribute must have a valid value"
 }
 
-// Check examines the document for script elements with invalid type.
-func (r *ScriptType) Check(doc *parser.Document) []Result {
-	var results []Result
+// DefaultSeverity returns the most severe level this rule reports at.
+func (r *ScriptType) DefaultSeverity() Severity { return Warning }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode || !n.IsElement("script") {
-			return true
-		}
+// Check examines the document for script elements with invalid type.
+func (r *ScriptType) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+// Visit checks each <script> element.
+func (r *ScriptType) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		// Type is optional (defaults to JavaScript)
 		if !n.HasAttr("type") {
-			return true
+			return
 		}
 
 		scriptType := strings.ToLower(strings.TrimSpace(n.GetAttr("type")))
 
 		// Skip template values
 		if scriptType == "tmpl" {
-			return true
+			return
 		}
 
 		// Check if it's a valid type
 		if !ValidScriptTypes[scriptType] {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     RuleScriptType,
 				Message:  "invalid script type: " + n.GetAttr("type"),
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Warning,
 			})
 		}
-
-		return true
-	})
-
-	return results
+	}, "script")
 }
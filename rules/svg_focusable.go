@@ -14,28 +14,24 @@ func (r *SVGFocusable) Description() string {
 	return "SVGs inside interactive elements should have focusable=\"false\""
 }
 
-func (r *SVGFocusable) Check(doc *parser.Document) []Result {
-	var results []Result
+func (r *SVGFocusable) DefaultSeverity() Severity { return Warning }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
+func (r *SVGFocusable) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+func (r *SVGFocusable) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		// Check if this is an interactive element
 		if !r.isInteractive(n) {
-			return true
+			return
 		}
 
 		// Look for SVG children
+		var results []Result
 		for _, child := range n.Children {
-			r.checkSVG(child, doc.Filename, &results)
+			r.checkSVG(child, v.Doc, &results)
 		}
-
-		return true
-	})
-
-	return results
+		v.Report(results...)
+	}, "a", "button")
 }
 
 func (r *SVGFocusable) isInteractive(n *parser.Node) bool {
@@ -49,7 +45,7 @@ func (r *SVGFocusable) isInteractive(n *parser.Node) bool {
 	}
 }
 
-func (r *SVGFocusable) checkSVG(n *parser.Node, filename string, results *[]Result) {
+func (r *SVGFocusable) checkSVG(n *parser.Node, doc *parser.Document, results *[]Result) {
 	if n.Type != html.ElementNode {
 		return
 	}
@@ -61,17 +57,17 @@ func (r *SVGFocusable) checkSVG(n *parser.Node, filename string, results *[]Resu
 			*results = append(*results, Result{
 				Rule:     r.Name(),
 				Message:  "SVG inside interactive element should have focusable=\"false\"",
-				Filename: filename,
+				Filename: doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Warning,
-			})
+			}.WithEdits(doc, setAttr(doc, n, "focusable", "false")...))
 		}
 		return
 	}
 
 	// Recurse into children
 	for _, child := range n.Children {
-		r.checkSVG(child, filename, results)
+		r.checkSVG(child, doc, results)
 	}
 }
@@ -4,7 +4,6 @@ import (
 	"strconv"
 
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // TabindexNoPositive checks that tabindex values are not positive.
@@ -16,39 +15,33 @@ func (r *TabindexNoPositive) Description() string {
 	return "tabindex should be 0 or -1, not positive (breaks natural tab order)"
 }
 
-func (r *TabindexNoPositive) Check(doc *parser.Document) []Result {
-	var results []Result
+func (r *TabindexNoPositive) DefaultSeverity() Severity { return Error }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
+func (r *TabindexNoPositive) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+func (r *TabindexNoPositive) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		tabindex := n.GetAttr("tabindex")
 		if tabindex == "" {
-			return true
+			return
 		}
 
 		// Parse tabindex value
 		val, err := strconv.Atoi(tabindex)
 		if err != nil {
 			// Non-numeric tabindex, skip (could be template variable)
-			return true
+			return
 		}
 
 		if val > 0 {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     r.Name(),
 				Message:  "positive tabindex disrupts natural tab order; use 0 or -1",
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Error,
 			})
 		}
-
-		return true
 	})
-
-	return results
 }
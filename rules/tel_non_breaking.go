@@ -4,7 +4,6 @@ import (
 	"strings"
 
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // TelNonBreaking checks that tel: links use non-breaking formatting.
@@ -19,42 +18,38 @@ func (r *TelNonBreaking) Description() string {
 	return "tel: links should use non-breaking spaces to prevent awkward line breaks"
 }
 
-// Check examines the document for tel: links with breaking spaces.
-func (r *TelNonBreaking) Check(doc *parser.Document) []Result {
-	var results []Result
+// DefaultSeverity returns the most severe level this rule reports at.
+func (r *TelNonBreaking) DefaultSeverity() Severity { return Info }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode || !n.IsElement("a") {
-			return true
-		}
+// Check examines the document for tel: links with breaking spaces.
+func (r *TelNonBreaking) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+// Visit checks each <a> element.
+func (r *TelNonBreaking) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		// Check if this is a tel: link
 		href := n.GetAttr("href")
 		if !strings.HasPrefix(strings.ToLower(href), "tel:") {
-			return true
+			return
 		}
 
 		// Check text content for regular spaces
 		text := n.TextContent()
 		if text == "" || text == TemplateExprPlaceholder {
-			return true
+			return
 		}
 
 		// Look for regular spaces in the phone number text
 		// Non-breaking space is \u00A0, regular space is \u0020
 		if strings.Contains(text, " ") {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     RuleTelNonBreaking,
 				Message:  "tel: link text contains regular spaces; use &nbsp; or CSS white-space: nowrap",
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Info,
 			})
 		}
-
-		return true
-	})
-
-	return results
+	}, "a")
 }
@@ -0,0 +1,102 @@
+package rules
+
+import (
+	"bytes"
+
+	"github.com/toba/go-html-validate/parser"
+)
+
+// TemplateEscapeHazard checks where template actions sit in the HTML, since
+// html/template escapes each one for its context. In some contexts it
+// refuses to render the value (printing ZgotmplZ instead), and in others the
+// escaped output is still easy to misuse.
+type TemplateEscapeHazard struct{}
+
+func (r *TemplateEscapeHazard) Name() string { return RuleTemplateEscapeHazard }
+
+func (r *TemplateEscapeHazard) Description() string {
+	return "template actions should not appear where html/template can't escape them safely"
+}
+
+func (r *TemplateEscapeHazard) DefaultSeverity() Severity { return Warning }
+
+// Check reports output actions in hazardous escape contexts.
+func (r *TemplateEscapeHazard) Check(doc *parser.Document) []Result {
+	sm := doc.SourceMap()
+	if sm == nil {
+		return nil
+	}
+
+	var results []Result
+	for _, a := range doc.TemplateActions() {
+		if a.Kind != parser.ActionOutput {
+			continue
+		}
+		message, severity := escapeHazard(a, sm.Original)
+		if message == "" {
+			continue
+		}
+		results = append(results, Result{
+			Rule:     r.Name(),
+			Message:  message,
+			Filename: doc.Filename,
+			Severity: severity,
+		}.WithSpan(a.Span))
+	}
+	return results
+}
+
+// navigationAttrs hold URLs followed when the user acts, where a javascript:
+// URL set by a whole-URL action would run. Loaded URLs such as src are
+// filtered the same way but don't need the warning.
+var navigationAttrs = map[string]bool{
+	"href":       true,
+	"action":     true,
+	"formaction": true,
+}
+
+// escapeHazard describes the problem with an output action, if any.
+func escapeHazard(a parser.TemplateAction, content []byte) (string, Severity) {
+	ctx := a.Context
+	switch {
+	case ctx.State == parser.StateTag || ctx.State == parser.StateAttrName:
+		return "action in an attribute name renders ZgotmplZ unless it is a template.HTMLAttr", Warning
+	case ctx.State == parser.StateComment:
+		return "action inside an HTML comment is never rendered; html/template strips comments", Info
+	case ctx.State == parser.StateURL && ctx.URLPart == parser.URLPartNone && navigationAttrs[ctx.Attr]:
+		return "action sets the whole " + ctx.Attr + " URL; unsafe schemes such as javascript: render as #ZgotmplZ", Warning
+	case ctx.State == parser.StateJS && ctx.Attr != "":
+		return "action in " + ctx.Attr + " event handler; pass the value through a data- attribute instead", Warning
+	case ctx.State == parser.StateJS && ctx.JSQuote == 0 && concatenated(content, a.Span):
+		return "action concatenated with a JavaScript string is escaped as a JS value, not for the markup or URL the string builds", Warning
+	case ctx.InAttrValue() && ctx.Quote == 0:
+		return "action in unquoted " + ctx.Attr + " attribute value; quote the value", Warning
+	case ctx.State == parser.StateCSS:
+		return "action in CSS renders ZgotmplZ unless the value is safe CSS or a template.CSS", Info
+	}
+	return "", Info
+}
+
+// concatenated reports whether the action at span is an operand of "+"
+// whose other operand is a string literal.
+func concatenated(content []byte, span parser.Span) bool {
+	before := bytes.TrimRight(content[:span.Start.Offset], " \t\r\n")
+	if rest, ok := bytes.CutSuffix(before, []byte("+")); ok {
+		rest = bytes.TrimRight(rest, " \t\r\n")
+		if len(rest) > 0 && isJSQuote(rest[len(rest)-1]) {
+			return true
+		}
+	}
+	after := bytes.TrimLeft(content[span.End.Offset:], " \t\r\n")
+	if rest, ok := bytes.CutPrefix(after, []byte("+")); ok {
+		rest = bytes.TrimLeft(rest, " \t\r\n")
+		if len(rest) > 0 && isJSQuote(rest[0]) {
+			return true
+		}
+	}
+	return false
+}
+
+func isJSQuote(c byte) bool {
+	return c == '"' || c == '\'' || c == '`'
+}
@@ -16,30 +16,33 @@ func (r *TemplateSyntaxValid) Description() string {
 	return "validate Go template syntax for common errors"
 }
 
+func (r *TemplateSyntaxValid) DefaultSeverity() Severity { return Error }
+
 // Check implements Rule but returns nil - this rule uses CheckRaw instead.
 func (r *TemplateSyntaxValid) Check(_ *parser.Document) []Result {
 	return nil
 }
 
-// controlStructures that require matching end.
-var controlStructures = map[string]bool{
-	"if":     true,
-	"range":  true,
-	"with":   true,
-	"block":  true,
-	"define": true,
-}
-
 // CheckRaw examines the raw template content for syntax errors.
 func (r *TemplateSyntaxValid) CheckRaw(filename string, content []byte) []Result {
+	return r.CheckRawDelims(filename, content, parser.DefaultDelims)
+}
+
+// CheckRawDelims examines raw template content using the given delimiters.
+func (r *TemplateSyntaxValid) CheckRawDelims(filename string, content []byte, delims parser.Delims) []Result {
+	d := delims.OrDefault()
+	// Actions are found the way text/template lexes them, so delimiters
+	// inside string literals and comments don't count
+	actions := parser.ScanActions(content, d)
+
 	// Check for unbalanced braces
-	braceResults := r.checkBalancedBraces(filename, content)
+	braceResults := r.checkBalancedBraces(filename, content, actions, d)
 
 	// Check for unbalanced control structures
-	controlResults := r.checkBalancedControlStructures(filename, content)
+	controlResults := r.checkBalancedControlStructures(filename, actions, d)
 
 	// Check for invalid trim marker syntax
-	trimResults := r.checkTrimMarkerSyntax(filename, content)
+	trimResults := r.checkTrimMarkerSyntax(filename, content, d)
 
 	// Combine all results
 	results := make([]Result, 0, len(braceResults)+len(controlResults)+len(trimResults))
@@ -50,33 +53,44 @@ func (r *TemplateSyntaxValid) CheckRaw(filename string, content []byte) []Result
 	return results
 }
 
-// checkBalancedBraces verifies that {{ and }} are balanced.
-func (r *TemplateSyntaxValid) checkBalancedBraces(filename string, content []byte) []Result {
+// checkBalancedBraces reports an action left open at the end of content and
+// the first closing delimiter outside any action.
+func (r *TemplateSyntaxValid) checkBalancedBraces(filename string, content []byte, actions []parser.TemplateAction, d parser.Delims) []Result {
 	var results []Result
 
-	openCount := bytes.Count(content, []byte("{{"))
-	closeCount := bytes.Count(content, []byte("}}"))
+	// Text between actions must not contain a closing delimiter
+	text := 0
+	for i := 0; i <= len(actions); i++ {
+		end := len(content)
+		if i < len(actions) {
+			end = actions[i].Span.Start.Offset
+		}
+		if at := bytes.Index(content[text:end], []byte(d.Right)); at >= 0 {
+			line, col := position(content, text+at)
+			results = append(results, Result{
+				Rule:     r.Name(),
+				Message:  "unmatched '" + d.Right + "' - missing opening '" + d.Left + "'",
+				Filename: filename,
+				Line:     line,
+				Col:      col,
+				Severity: Error,
+			})
+			break
+		}
+		if i < len(actions) {
+			text = actions[i].Span.End.Offset
+		}
+	}
 
-	if openCount > closeCount {
-		// Find the first unmatched {{
-		line, col := r.findUnmatchedOpen(content)
+	// An unclosed action runs to the end of content, so it can only be the last
+	if len(actions) > 0 && !actions[len(actions)-1].Closed {
+		start := actions[len(actions)-1].Span.Start
 		results = append(results, Result{
 			Rule:     r.Name(),
-			Message:  "unmatched '{{' - missing closing '}}'",
+			Message:  "unmatched '" + d.Left + "' - missing closing '" + d.Right + "'",
 			Filename: filename,
-			Line:     line,
-			Col:      col,
-			Severity: Error,
-		})
-	} else if closeCount > openCount {
-		// Find the first unmatched }}
-		line, col := r.findUnmatchedClose(content)
-		results = append(results, Result{
-			Rule:     r.Name(),
-			Message:  "unmatched '}}' - missing opening '{{'",
-			Filename: filename,
-			Line:     line,
-			Col:      col,
+			Line:     start.Line,
+			Col:      start.Col,
 			Severity: Error,
 		})
 	}
@@ -84,134 +98,49 @@ func (r *TemplateSyntaxValid) checkBalancedBraces(filename string, content []byt
 	return results
 }
 
-// findUnmatchedOpen finds the position of an unmatched {{.
-func (r *TemplateSyntaxValid) findUnmatchedOpen(content []byte) (line, col int) {
-	depth := 0
-	lastOpenLine, lastOpenCol := 1, 1
-	currentLine, currentCol := 1, 1
-
-	for i := 0; i < len(content); i++ {
-		if content[i] == '\n' {
-			currentLine++
-			currentCol = 1
-			continue
-		}
-
-		if i+1 < len(content) && content[i] == '{' && content[i+1] == '{' {
-			if depth == 0 {
-				lastOpenLine, lastOpenCol = currentLine, currentCol
-			}
-			depth++
-			i++ // skip second {
-			currentCol += 2
-			continue
-		}
-
-		if i+1 < len(content) && content[i] == '}' && content[i+1] == '}' {
-			depth--
-			i++ // skip second }
-			currentCol += 2
-			continue
-		}
-
-		currentCol++
-	}
-
-	return lastOpenLine, lastOpenCol
+// position returns the 1-based line and byte column of offset in content.
+func position(content []byte, offset int) (line, col int) {
+	before := content[:offset]
+	line = bytes.Count(before, []byte("\n")) + 1
+	return line, offset - (bytes.LastIndexByte(before, '\n') + 1) + 1
 }
 
-// findUnmatchedClose finds the position of an unmatched }}.
-func (r *TemplateSyntaxValid) findUnmatchedClose(content []byte) (line, col int) {
-	depth := 0
-	currentLine, currentCol := 1, 1
-
-	for i := 0; i < len(content); i++ {
-		if content[i] == '\n' {
-			currentLine++
-			currentCol = 1
-			continue
-		}
-
-		if i+1 < len(content) && content[i] == '{' && content[i+1] == '{' {
-			depth++
-			i++ // skip second {
-			currentCol += 2
-			continue
-		}
-
-		if i+1 < len(content) && content[i] == '}' && content[i+1] == '}' {
-			depth--
-			if depth < 0 {
-				return currentLine, currentCol
-			}
-			i++ // skip second }
-			currentCol += 2
-			continue
-		}
-
-		currentCol++
-	}
-
-	return 1, 1
-}
-
-// checkBalancedControlStructures verifies that if/range/with/block have matching end.
-func (r *TemplateSyntaxValid) checkBalancedControlStructures(filename string, content []byte) []Result {
+// checkBalancedControlStructures verifies that if/range/with/block/define
+// have a matching end.
+func (r *TemplateSyntaxValid) checkBalancedControlStructures(filename string, actions []parser.TemplateAction, d parser.Delims) []Result {
 	var results []Result
 
-	// Stack to track open control structures
-	type openStruct struct {
-		keyword string
-		line    int
-		col     int
-	}
-	var stack []openStruct
-
-	lines := bytes.Split(content, []byte("\n"))
-
-	// Pattern to extract template actions
-	actionRegex := regexp.MustCompile(`\{\{-?\s*(\w+)`)
-
-	for lineNum, line := range lines {
-		matches := actionRegex.FindAllSubmatchIndex(line, -1)
-		for _, match := range matches {
-			if match[2] < 0 || match[3] < 0 {
-				continue
+	// Stack of open control structures
+	var stack []parser.TemplateAction
+
+	for _, a := range actions {
+		switch a.Kind {
+		case parser.ActionIf, parser.ActionRange, parser.ActionWith, parser.ActionBlock, parser.ActionDefine:
+			stack = append(stack, a)
+		case parser.ActionEnd:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			} else {
+				results = append(results, Result{
+					Rule:     r.Name(),
+					Message:  "unexpected '" + d.Left + " end " + d.Right + "' - no matching control structure",
+					Filename: filename,
+					Line:     a.Span.Start.Line,
+					Col:      a.Span.Start.Col,
+					Severity: Error,
+				})
 			}
-			keyword := string(line[match[2]:match[3]])
-
-			switch {
-			case controlStructures[keyword]:
-				stack = append(stack, openStruct{
-					keyword: keyword,
-					line:    lineNum + 1,
-					col:     match[0] + 1,
+		case parser.ActionElse, parser.ActionElseIf, parser.ActionElseWith:
+			// else doesn't pop the stack, it's part of an if/with
+			if len(stack) == 0 {
+				results = append(results, Result{
+					Rule:     r.Name(),
+					Message:  "unexpected '" + d.Left + " else " + d.Right + "' - no matching 'if' or 'with'",
+					Filename: filename,
+					Line:     a.Span.Start.Line,
+					Col:      a.Span.Start.Col,
+					Severity: Error,
 				})
-			case keyword == "end":
-				if len(stack) > 0 {
-					stack = stack[:len(stack)-1]
-				} else {
-					results = append(results, Result{
-						Rule:     r.Name(),
-						Message:  "unexpected '{{ end }}' - no matching control structure",
-						Filename: filename,
-						Line:     lineNum + 1,
-						Col:      match[0] + 1,
-						Severity: Error,
-					})
-				}
-			case keyword == "else":
-				// else doesn't pop the stack, it's part of an if/with
-				if len(stack) == 0 {
-					results = append(results, Result{
-						Rule:     r.Name(),
-						Message:  "unexpected '{{ else }}' - no matching 'if' or 'with'",
-						Filename: filename,
-						Line:     lineNum + 1,
-						Col:      match[0] + 1,
-						Severity: Error,
-					})
-				}
 			}
 		}
 	}
@@ -220,10 +149,10 @@ func (r *TemplateSyntaxValid) checkBalancedControlStructures(filename string, co
 	for _, open := range stack {
 		results = append(results, Result{
 			Rule:     r.Name(),
-			Message:  "unclosed '{{ " + open.keyword + " }}' - missing '{{ end }}'",
+			Message:  "unclosed '" + d.Left + " " + open.Kind.String() + " " + d.Right + "' - missing '" + d.Left + " end " + d.Right + "'",
 			Filename: filename,
-			Line:     open.line,
-			Col:      open.col,
+			Line:     open.Span.Start.Line,
+			Col:      open.Span.Start.Col,
 			Severity: Error,
 		})
 	}
@@ -232,7 +161,7 @@ func (r *TemplateSyntaxValid) checkBalancedControlStructures(filename string, co
 }
 
 // checkTrimMarkerSyntax verifies that trim markers have proper spacing.
-func (r *TemplateSyntaxValid) checkTrimMarkerSyntax(filename string, content []byte) []Result {
+func (r *TemplateSyntaxValid) checkTrimMarkerSyntax(filename string, content []byte, d parser.Delims) []Result {
 	var results []Result
 
 	lines := bytes.Split(content, []byte("\n"))
@@ -240,17 +169,17 @@ func (r *TemplateSyntaxValid) checkTrimMarkerSyntax(filename string, content []b
 	// Pattern to find {{- without space after or -}} without space before
 	// Valid: {{- foo }}, {{ foo -}}
 	// Invalid: {{-foo }}, {{ foo-}}
-	leadingTrimNoSpace := regexp.MustCompile(`\{\{-[^\s]`)
-	trailingTrimNoSpace := regexp.MustCompile(`[^\s]-\}\}`)
+	leadingTrimNoSpace := regexp.MustCompile(regexp.QuoteMeta(d.Left) + `-[^\s]`)
+	trailingTrimNoSpace := regexp.MustCompile(`[^\s]-` + regexp.QuoteMeta(d.Right))
 
 	for lineNum, line := range lines {
 		// Check leading trim marker
 		if match := leadingTrimNoSpace.FindIndex(line); match != nil {
 			// Make sure it's not {{--}} (double dash edge case)
-			if match[0]+3 < len(line) && line[match[0]+3] != '-' {
+			if after := match[0] + len(d.Left) + 1; after < len(line) && line[after] != '-' {
 				results = append(results, Result{
 					Rule:     r.Name(),
-					Message:  "trim marker '{{-' must be followed by whitespace",
+					Message:  "trim marker '" + d.Left + "-' must be followed by whitespace",
 					Filename: filename,
 					Line:     lineNum + 1,
 					Col:      match[0] + 1,
@@ -267,7 +196,7 @@ func (r *TemplateSyntaxValid) checkTrimMarkerSyntax(filename string, content []b
 			if charBefore != '-' {
 				results = append(results, Result{
 					Rule:     r.Name(),
-					Message:  "trim marker '-}}' must be preceded by whitespace",
+					Message:  "trim marker '-" + d.Right + "' must be preceded by whitespace",
 					Filename: filename,
 					Line:     lineNum + 1,
 					Col:      match[0] + 1,
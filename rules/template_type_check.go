@@ -0,0 +1,187 @@
+package rules
+
+import (
+	"errors"
+	"go/importer"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sort"
+	"sync"
+	"text/template/parse"
+
+	"github.com/toba/go-html-validate/parser"
+)
+
+// TemplateTypeCheck checks field and method references in a template against
+// the Go type it is executed with, catching "can't evaluate field" errors
+// before run time. The type is declared per file in configuration, or in the
+// template itself with a {{/* htmlint:data example.com/app/views.Page */}}
+// comment; templates without a declared type aren't checked.
+type TemplateTypeCheck struct {
+	dataFor func(filename string) string
+
+	mu       sync.Mutex
+	importer types.ImporterFrom
+	loaded   map[typeKey]loadedType
+}
+
+// typeKey identifies a type spec as resolved from a directory, as the same
+// import path can name different packages in different modules.
+type typeKey struct {
+	spec, srcDir string
+}
+
+// loadedType caches the outcome of resolving a type spec.
+type loadedType struct {
+	typ types.Type
+	err error
+}
+
+func (r *TemplateTypeCheck) Name() string { return RuleTemplateTypeCheck }
+
+func (r *TemplateTypeCheck) Description() string {
+	return "template field references must exist on the template's data type"
+}
+
+func (r *TemplateTypeCheck) DefaultSeverity() Severity { return Error }
+
+// ConfigureTemplateData sets how to find the data type of a file, as an
+// "import/path.Type" spec. An empty spec means no type is configured.
+func (r *TemplateTypeCheck) ConfigureTemplateData(dataFor func(filename string) string) {
+	r.dataFor = dataFor
+}
+
+// Reset forgets the packages loaded so far, so Go source edited since is
+// type-checked again.
+func (r *TemplateTypeCheck) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.importer, r.loaded = nil, nil
+}
+
+// Check type-checks every template in the document that has a data type.
+func (r *TemplateTypeCheck) Check(doc *parser.Document) []Result {
+	sm := doc.SourceMap()
+	if sm == nil {
+		return nil
+	}
+	delims := doc.Delims()
+
+	t := parse.New("")
+	t.Mode = parse.ParseComments | parse.SkipFuncCheck
+	trees := make(map[string]*parse.Tree)
+	if _, err := t.Parse(string(sm.Original), delims.Left, delims.Right, trees); err != nil {
+		// Syntax errors are template-syntax-valid's business
+		return nil
+	}
+	if trees[""] != t {
+		trees[""] = t
+	}
+
+	fileSpec := ""
+	if r.dataFor != nil {
+		fileSpec = r.dataFor(doc.Filename)
+	}
+	fileSpecOffset := -1
+	if spec, offset := dataDirectiveIn(t); spec != "" {
+		fileSpec, fileSpecOffset = spec, offset
+	}
+
+	var results []Result
+	report := func(offset, length int, message string, severity Severity) {
+		start := sm.Position(offset)
+		results = append(results, Result{
+			Rule:     r.Name(),
+			Message:  message,
+			Filename: doc.Filename,
+			Severity: severity,
+		}.WithSpan(parser.Span{Start: start, End: sm.Position(offset + length)}))
+	}
+
+	// resolve loads the type for a tree, reporting load failures at the
+	// directive that named it (or the top of the file for configured types)
+	srcDir := filepath.Dir(doc.Filename)
+	failed := make(map[string]bool)
+	resolve := func(spec string, offset int) types.Type {
+		typ, err := r.load(spec, srcDir)
+		if err != nil {
+			if !failed[spec] {
+				failed[spec] = true
+				report(max(offset, 0), 0, "can't load template data type "+spec+": "+err.Error(), Warning)
+			}
+			return nil
+		}
+		return typ
+	}
+
+	w := &typeWalker{
+		content: string(sm.Original),
+		trees:   trees,
+		checked: make(map[*parse.Tree]bool),
+	}
+
+	// The main template first, so templates it calls get the type they're
+	// called with; then any remaining definitions on their own
+	if fileSpec != "" {
+		if typ := resolve(fileSpec, fileSpecOffset); typ != nil {
+			w.checkTree(t, typ)
+		}
+	}
+	rest := make([]*parse.Tree, 0, len(trees))
+	for _, tree := range trees {
+		rest = append(rest, tree)
+	}
+	sort.Slice(rest, func(i, j int) bool { return rest[i].Root.Pos < rest[j].Root.Pos })
+	for _, tree := range rest {
+		if w.checked[tree] {
+			continue
+		}
+		spec, offset := dataDirectiveIn(tree)
+		if spec == "" && tree != t {
+			spec, offset = fileSpec, fileSpecOffset
+		}
+		if spec == "" {
+			continue
+		}
+		if typ := resolve(spec, offset); typ != nil {
+			w.checkTree(tree, typ)
+		}
+	}
+
+	for _, p := range w.problems {
+		report(p.offset, p.length, p.message, Error)
+	}
+	return results
+}
+
+// load resolves an "import/path.Type" spec by type-checking the package from
+// source. Packages are resolved relative to srcDir, so module-local import
+// paths work. Results are cached until Reset, as loading a package is slow.
+func (r *TemplateTypeCheck) load(spec, srcDir string) (types.Type, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := typeKey{spec, srcDir}
+	if l, ok := r.loaded[key]; ok {
+		return l.typ, l.err
+	}
+	if r.importer == nil {
+		r.importer = importer.ForCompiler(token.NewFileSet(), "source", nil).(types.ImporterFrom)
+		r.loaded = make(map[typeKey]loadedType)
+	}
+
+	var l loadedType
+	pkgPath, name, ok := splitTypeSpec(spec)
+	if !ok {
+		l.err = errors.New("expected import/path.TypeName")
+	} else if pkg, err := r.importer.ImportFrom(pkgPath, srcDir, 0); err != nil {
+		l.err = err
+	} else if obj, ok := pkg.Scope().Lookup(name).(*types.TypeName); !ok {
+		l.err = errors.New("no type " + name + " in package " + pkgPath)
+	} else {
+		l.typ = obj.Type()
+	}
+	r.loaded[key] = l
+	return l.typ, l.err
+}
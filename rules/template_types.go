@@ -0,0 +1,286 @@
+package rules
+
+import (
+	"go/token"
+	"go/types"
+	"slices"
+	"strings"
+	"text/template/parse"
+)
+
+// DataDirective marks a template comment declaring the Go type a template is
+// executed with: {{/* htmlint:data example.com/app/views.Page */}}.
+const DataDirective = "htmlint:data"
+
+// typeProblem is a field reference that can't be evaluated on its type.
+type typeProblem struct {
+	offset, length int
+	message        string
+}
+
+// typeVar is a template variable and its type, nil when unknown.
+type typeVar struct {
+	name string
+	typ  types.Type
+}
+
+// typeWalker follows the type of dot and of each variable through a template,
+// the way text/template does at execution time, and records references to
+// fields and methods the types don't have. Unknown types (function results,
+// interfaces) are not checked.
+type typeWalker struct {
+	content  string
+	trees    map[string]*parse.Tree
+	checked  map[*parse.Tree]bool
+	problems []typeProblem
+}
+
+// checkTree checks a template executed with dot of type dot.
+func (w *typeWalker) checkTree(tree *parse.Tree, dot types.Type) {
+	w.checked[tree] = true
+	vars := []typeVar{{name: "$", typ: dot}}
+	w.walkList(tree.Root, dot, vars)
+}
+
+func (w *typeWalker) walkList(list *parse.ListNode, dot types.Type, vars []typeVar) {
+	if list == nil {
+		return
+	}
+	for _, n := range list.Nodes {
+		switch n := n.(type) {
+		case *parse.ActionNode:
+			t := w.pipeType(n.Pipe, dot, vars)
+			vars = declare(vars, n.Pipe, t)
+		case *parse.IfNode:
+			t := w.pipeType(n.Pipe, dot, vars)
+			inner := declare(vars, n.Pipe, t)
+			w.walkList(n.List, dot, inner)
+			w.walkList(n.ElseList, dot, inner)
+		case *parse.WithNode:
+			t := w.pipeType(n.Pipe, dot, vars)
+			inner := declare(vars, n.Pipe, t)
+			w.walkList(n.List, t, inner)
+			w.walkList(n.ElseList, dot, inner)
+		case *parse.RangeNode:
+			t := w.pipeType(n.Pipe, dot, vars)
+			key, elem := rangeTypes(t)
+			inner := slices.Clip(vars)
+			switch len(n.Pipe.Decl) {
+			case 1:
+				inner = append(inner, typeVar{n.Pipe.Decl[0].Ident[0], elem})
+			case 2:
+				inner = append(inner,
+					typeVar{n.Pipe.Decl[0].Ident[0], key},
+					typeVar{n.Pipe.Decl[1].Ident[0], elem})
+			}
+			w.walkList(n.List, elem, inner)
+			w.walkList(n.ElseList, dot, inner)
+		case *parse.TemplateNode:
+			t := w.pipeType(n.Pipe, dot, vars)
+			// Templates called from this file are checked with the
+			// type they're called with
+			if tree := w.trees[n.Name]; tree != nil && t != nil && !w.checked[tree] {
+				w.checkTree(tree, t)
+			}
+		}
+	}
+}
+
+// declare adds the variables declared by pipe, all of type t. Assignments to
+// existing variables don't change their recorded type.
+func declare(vars []typeVar, pipe *parse.PipeNode, t types.Type) []typeVar {
+	if pipe == nil || pipe.IsAssign || len(pipe.Decl) == 0 {
+		return vars
+	}
+	vars = slices.Clip(vars)
+	for _, v := range pipe.Decl {
+		vars = append(vars, typeVar{v.Ident[0], t})
+	}
+	return vars
+}
+
+// pipeType checks a pipeline and returns the type of its result.
+func (w *typeWalker) pipeType(pipe *parse.PipeNode, dot types.Type, vars []typeVar) types.Type {
+	if pipe == nil {
+		return nil
+	}
+	var t types.Type
+	for _, cmd := range pipe.Cmds {
+		t = w.cmdType(cmd, dot, vars)
+	}
+	return t
+}
+
+// cmdType checks every argument of a command and returns the type of its
+// result, if it's a field, method or variable reference.
+func (w *typeWalker) cmdType(cmd *parse.CommandNode, dot types.Type, vars []typeVar) types.Type {
+	if len(cmd.Args) == 0 {
+		return nil
+	}
+	for _, arg := range cmd.Args[1:] {
+		w.argType(arg, dot, vars)
+	}
+	return w.argType(cmd.Args[0], dot, vars)
+}
+
+func (w *typeWalker) argType(arg parse.Node, dot types.Type, vars []typeVar) types.Type {
+	switch arg := arg.(type) {
+	case *parse.DotNode:
+		return dot
+	case *parse.FieldNode:
+		return w.fieldChain(dot, arg.Ident, w.nodeStart(arg))
+	case *parse.VariableNode:
+		t := lookupVar(vars, arg.Ident[0])
+		return w.fieldChain(t, arg.Ident[1:], w.nodeStart(arg)+len(arg.Ident[0]))
+	case *parse.ChainNode:
+		t := w.argType(arg.Node, dot, vars)
+		return w.fieldChain(t, arg.Field, int(arg.Pos))
+	case *parse.PipeNode:
+		return w.pipeType(arg, dot, vars)
+	}
+	// Function calls and literals
+	return nil
+}
+
+// nodeStart returns the offset of the first character of a field or variable
+// node. The parser merges .A.B into a single node positioned at its last
+// field, so the start is found by searching back for the node's text.
+func (w *typeWalker) nodeStart(n parse.Node) int {
+	pos, text := int(n.Position()), n.String()
+	end := min(pos+len(text), len(w.content))
+	if i := strings.LastIndex(w.content[:end], text); i >= 0 {
+		return i
+	}
+	return pos
+}
+
+func lookupVar(vars []typeVar, name string) types.Type {
+	for i := len(vars) - 1; i >= 0; i-- {
+		if vars[i].name == name {
+			return vars[i].typ
+		}
+	}
+	return nil
+}
+
+// fieldChain resolves .A.B.C on t, where pos is the offset of the first dot.
+func (w *typeWalker) fieldChain(t types.Type, idents []string, pos int) types.Type {
+	for _, name := range idents {
+		if t == nil {
+			return nil
+		}
+		next, problem := fieldType(t, name)
+		if problem != "" {
+			w.problems = append(w.problems, typeProblem{offset: pos, length: len(name) + 1, message: problem})
+			return nil
+		}
+		t = next
+		pos += len(name) + 1
+	}
+	return t
+}
+
+// fieldType returns the type of field or method name on t, or a message
+// matching the text/template execution error if there's no such thing.
+// Returns a nil type and no message when t can't be checked statically.
+func fieldType(t types.Type, name string) (types.Type, string) {
+	// Methods come first, as they do at run time, even on map types
+	if token.IsExported(name) {
+		obj, _, _ := types.LookupFieldOrMethod(t, true, nil, name)
+		switch obj := obj.(type) {
+		case *types.Var:
+			return obj.Type(), ""
+		case *types.Func:
+			sig, _ := obj.Type().(*types.Signature)
+			if sig == nil || sig.Results().Len() == 0 {
+				return nil, ""
+			}
+			return sig.Results().At(0).Type(), ""
+		}
+	}
+
+	base := t
+	if p, ok := base.Underlying().(*types.Pointer); ok {
+		base = p.Elem()
+	}
+	switch u := base.Underlying().(type) {
+	case *types.Map:
+		// Map keys are looked up at run time
+		return u.Elem(), ""
+	case *types.Interface:
+		// The dynamic type may have it
+		return nil, ""
+	case *types.Struct:
+		if !token.IsExported(name) {
+			return nil, name + " is an unexported field of struct type " + typeString(t)
+		}
+	}
+	return nil, "can't evaluate field " + name + " in type " + typeString(t)
+}
+
+// rangeTypes returns the key and element types produced by ranging over t.
+func rangeTypes(t types.Type) (key, elem types.Type) {
+	if t == nil {
+		return nil, nil
+	}
+	intType := types.Typ[types.Int]
+	under := t.Underlying()
+	if p, ok := under.(*types.Pointer); ok {
+		if a, ok := p.Elem().Underlying().(*types.Array); ok {
+			return intType, a.Elem()
+		}
+	}
+	switch u := under.(type) {
+	case *types.Slice:
+		return intType, u.Elem()
+	case *types.Array:
+		return intType, u.Elem()
+	case *types.Map:
+		return u.Key(), u.Elem()
+	case *types.Chan:
+		return nil, u.Elem()
+	case *types.Basic:
+		if u.Info()&types.IsInteger != 0 {
+			return t, t
+		}
+	}
+	return nil, nil
+}
+
+// typeString formats t with package names rather than full import paths.
+func typeString(t types.Type) string {
+	return types.TypeString(t, func(p *types.Package) string { return p.Name() })
+}
+
+// dataDirectiveIn returns the type named by a data directive at the top
+// level of tree, if any.
+func dataDirectiveIn(tree *parse.Tree) (spec string, offset int) {
+	if tree == nil || tree.Root == nil {
+		return "", 0
+	}
+	for _, n := range tree.Root.Nodes {
+		c, ok := n.(*parse.CommentNode)
+		if !ok {
+			continue
+		}
+		text := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(c.Text, "/*"), "*/"))
+		if rest, ok := strings.CutPrefix(text, DataDirective); ok {
+			if fields := strings.Fields(rest); len(fields) == 1 {
+				return fields[0], int(c.Pos)
+			}
+		}
+	}
+	return "", 0
+}
+
+// splitTypeSpec splits "example.com/app/views.Page" into its import path and
+// type name. A leading "*" is ignored; methods with pointer receivers are
+// found either way.
+func splitTypeSpec(spec string) (pkgPath, name string, ok bool) {
+	spec = strings.TrimPrefix(spec, "*")
+	dot := strings.LastIndex(spec, ".")
+	if dot <= strings.LastIndex(spec, "/") || dot == len(spec)-1 {
+		return "", "", false
+	}
+	return spec[:dot], spec[dot+1:], true
+}
@@ -0,0 +1,53 @@
+package rules
+
+import (
+	"github.com/toba/go-html-validate/parser"
+)
+
+// TemplateUndefined checks that every {{template "name"}} call refers to a
+// template defined somewhere in the template set. Calling an undefined
+// template is an error at execution time, not at parse time.
+type TemplateUndefined struct{}
+
+func (r *TemplateUndefined) Name() string { return RuleTemplateUndefined }
+
+func (r *TemplateUndefined) Description() string {
+	return "{{template}} calls must refer to a defined template"
+}
+
+func (r *TemplateUndefined) DefaultSeverity() Severity { return Error }
+
+// Check reports {{template}} calls to names defined neither in the document
+// nor in its template set. Does nothing without a template set, since
+// templates defined in other files can't be seen.
+func (r *TemplateUndefined) Check(doc *parser.Document) []Result {
+	set := doc.Templates()
+	if set == nil {
+		return nil
+	}
+
+	actions := doc.TemplateActions()
+	defined := make(map[string]bool)
+	for _, a := range actions {
+		if a.Kind == parser.ActionDefine || a.Kind == parser.ActionBlock {
+			defined[a.Name] = true
+		}
+	}
+
+	var results []Result
+	for _, a := range actions {
+		if a.Kind != parser.ActionTemplate || a.Name == "" || defined[a.Name] {
+			continue
+		}
+		if _, ok := set.Lookup(a.Name); ok {
+			continue
+		}
+		results = append(results, Result{
+			Rule:     r.Name(),
+			Message:  "template \"" + a.Name + "\" is not defined",
+			Filename: doc.Filename,
+			Severity: Error,
+		}.WithSpan(a.Span))
+	}
+	return results
+}
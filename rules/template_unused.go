@@ -0,0 +1,63 @@
+package rules
+
+import (
+	"slices"
+
+	"github.com/toba/go-html-validate/parser"
+)
+
+// TemplateUnused checks for {{define}} templates that are never called.
+// Templates executed directly from Go code (ExecuteTemplate) are listed as
+// entry points so they aren't reported.
+type TemplateUnused struct {
+	entryPoints []string
+}
+
+func (r *TemplateUnused) Name() string { return RuleTemplateUnused }
+
+func (r *TemplateUnused) Description() string {
+	return "{{define}} templates should be referenced by a {{template}} call"
+}
+
+func (r *TemplateUnused) DefaultSeverity() Severity { return Warning }
+
+// ConfigureEntryPoints sets the names of templates executed from Go code.
+func (r *TemplateUnused) ConfigureEntryPoints(names []string) {
+	r.entryPoints = names
+}
+
+// Check reports {{define}} templates in the document that no file of its
+// template set calls. Does nothing without a template set, since calls from
+// other files can't be seen.
+func (r *TemplateUnused) Check(doc *parser.Document) []Result {
+	set := doc.Templates()
+	if set == nil {
+		return nil
+	}
+
+	actions := doc.TemplateActions()
+	called := make(map[string]bool)
+	for _, a := range actions {
+		if a.Kind == parser.ActionTemplate {
+			called[a.Name] = true
+		}
+	}
+
+	var results []Result
+	for _, a := range actions {
+		// {{block}} executes in place, so it is always used
+		if a.Kind != parser.ActionDefine || a.Name == "" {
+			continue
+		}
+		if called[a.Name] || set.IsReferenced(a.Name) || slices.Contains(r.entryPoints, a.Name) {
+			continue
+		}
+		results = append(results, Result{
+			Rule:     r.Name(),
+			Message:  "template \"" + a.Name + "\" is defined but never used",
+			Filename: doc.Filename,
+			Severity: Warning,
+		}.WithSpan(a.Span))
+	}
+	return results
+}
@@ -18,6 +18,8 @@ func (r *TemplateWhitespaceTrim) Description() string {
 	return "suggest trim markers to prevent unwanted whitespace in template output"
 }
 
+func (r *TemplateWhitespaceTrim) DefaultSeverity() Severity { return Warning }
+
 // Check implements Rule but returns nil - this rule uses CheckRaw instead.
 func (r *TemplateWhitespaceTrim) Check(_ *parser.Document) []Result {
 	return nil
@@ -27,7 +29,14 @@ func (r *TemplateWhitespaceTrim) Check(_ *parser.Document) []Result {
 // - group 1: opening ({{ or {{-)
 // - group 2: action content
 // - group 3: closing (-}} or }})
-var templateActionPattern = regexp.MustCompile(`(\{\{-?)\s*(.*?)\s*(-?\}\})`)
+var templateActionPattern = actionPattern(parser.DefaultDelims)
+
+// actionPattern builds the equivalent of templateActionPattern for the given
+// delimiters.
+func actionPattern(d parser.Delims) *regexp.Regexp {
+	left, right := regexp.QuoteMeta(d.Left), regexp.QuoteMeta(d.Right)
+	return regexp.MustCompile(`(` + left + `-?)\s*(.*?)\s*(-?` + right + `)`)
+}
 
 // controlFlowKeywords are template actions that don't produce output
 // and commonly appear alone on lines.
@@ -44,13 +53,28 @@ var controlFlowKeywords = map[string]bool{
 
 // CheckRaw examines the raw template content for whitespace trim issues.
 func (r *TemplateWhitespaceTrim) CheckRaw(filename string, content []byte) []Result {
+	return r.CheckRawDelims(filename, content, parser.DefaultDelims)
+}
+
+// CheckRawDelims examines raw template content using the given delimiters.
+func (r *TemplateWhitespaceTrim) CheckRawDelims(filename string, content []byte, delims parser.Delims) []Result {
 	var results []Result
 
+	d := delims.OrDefault()
+	pattern := templateActionPattern
+	if d != parser.DefaultDelims {
+		pattern = actionPattern(d)
+	}
+
 	lines := bytes.Split(content, []byte("\n"))
 
+	lineStart := 0
 	for lineNum, line := range lines {
+		offset := lineStart
+		lineStart += len(line) + 1
+
 		// Find all template actions on this line
-		matches := templateActionPattern.FindAllSubmatchIndex(line, -1)
+		matches := pattern.FindAllSubmatchIndex(line, -1)
 		if len(matches) == 0 {
 			continue
 		}
@@ -69,18 +93,26 @@ func (r *TemplateWhitespaceTrim) CheckRaw(filename string, content []byte) []Res
 
 			// Check if the closing already has a trim marker
 			closing := string(line[match[6]:match[7]])
-			if closing == "-}}" {
+			if closing == "-"+d.Right {
 				continue
 			}
 
+			// The marker must be separated from the action by a space
+			marker := "-"
+			if c := line[match[6]-1]; c != ' ' && c != '\t' {
+				marker = " -"
+			}
+			at := offset + match[6]
+
 			// Report a warning
 			results = append(results, Result{
 				Rule:     r.Name(),
-				Message:  "control flow action alone on line should use trailing trim marker (-}}) to prevent blank lines",
+				Message:  "control flow action alone on line should use trailing trim marker (-" + d.Right + ") to prevent blank lines",
 				Filename: filename,
 				Line:     lineNum + 1,
 				Col:      match[0] + 1,
 				Severity: Warning,
+				Edits:    []Edit{{Start: at, End: at, Text: marker}},
 			})
 		}
 	}
@@ -19,30 +19,30 @@ func (r *TextContent) Description() string {
 	return "interactive elements must have accessible text content"
 }
 
-// Check examines the document for interactive elements without text content.
-func (r *TextContent) Check(doc *parser.Document) []Result {
-	var results []Result
+// DefaultSeverity returns the most severe level this rule reports at.
+func (r *TextContent) DefaultSeverity() Severity { return Error }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
+// Check examines the document for interactive elements without text content.
+func (r *TextContent) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+// Visit checks each element.
+func (r *TextContent) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		tag := strings.ToLower(n.Data)
 
 		// Check summary elements
 		if tag == "summary" {
 			if !HasAccessibleName(n) {
-				results = append(results, Result{
+				v.Report(Result{
 					Rule:     RuleTextContent,
 					Message:  "summary element must have accessible text content",
-					Filename: doc.Filename,
+					Filename: v.Doc.Filename,
 					Line:     n.Line,
 					Col:      n.Col,
 					Severity: Error,
 				})
 			}
-			return true
+			return
 		}
 
 		// Check details without summary (the default summary needs text)
@@ -57,11 +57,7 @@ func (r *TextContent) Check(doc *parser.Document) []Result {
 			// Details without explicit summary uses browser default ("Details")
 			// which is accessible, so no error needed
 			_ = hasSummary
-			return true
+			return
 		}
-
-		return true
 	})
-
-	return results
 }
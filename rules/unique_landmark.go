@@ -5,7 +5,6 @@ import (
 	"strings"
 
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // UniqueLandmark checks that duplicate landmark types have unique names.
@@ -17,90 +16,60 @@ func (r *UniqueLandmark) Description() string {
 	return "multiple landmarks of same type must have unique accessible names"
 }
 
-// landmarkInfo holds information about a landmark element.
-type landmarkInfo struct {
-	node *parser.Node
-	name string // accessible name from aria-label/aria-labelledby
-}
-
-func (r *UniqueLandmark) Check(doc *parser.Document) []Result {
-	var results []Result
+func (r *UniqueLandmark) DefaultSeverity() Severity { return Warning }
 
-	// Collect landmarks by role
-	landmarks := make(map[string][]landmarkInfo)
-
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
+// IsDocumentRule marks the rule as checking the page as a whole.
+func (r *UniqueLandmark) IsDocumentRule() {}
 
-		tagName := strings.ToLower(n.Data)
-		role := LandmarkElements[tagName]
+func (r *UniqueLandmark) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
-		// Check for explicit role attribute
-		if explicitRole := n.GetAttr("role"); explicitRole != "" {
-			explicitRole = strings.ToLower(explicitRole)
-			// role="presentation" or role="none" removes landmark semantics
-			if explicitRole == "presentation" || explicitRole == "none" {
-				return true
+func (r *UniqueLandmark) Visit(v *Visitor) {
+	v.OnDone(func() {
+		// Group landmarks by role, in order of first appearance
+		var roles []string
+		byRole := make(map[string][]Landmark)
+		for _, l := range v.Index().Landmarks {
+			if byRole[l.Role] == nil {
+				roles = append(roles, l.Role)
 			}
-			role = explicitRole
-		}
-
-		if role == "" {
-			return true
-		}
-
-		// Skip form/section without accessible name (they're not landmarks)
-		name := GetAccessibleName(n)
-		if (tagName == "form" || tagName == "section") && name == "" {
-			return true
+			byRole[l.Role] = append(byRole[l.Role], l)
 		}
 
-		landmarks[role] = append(landmarks[role], landmarkInfo{
-			node: n,
-			name: name,
-		})
-
-		return true
-	})
-
-	// Check for duplicate landmarks without unique names
-	for role, infos := range landmarks {
-		if len(infos) <= 1 {
-			continue
-		}
-
-		// Check for duplicates or missing names
-		seenNames := make(map[string]*parser.Node)
-		for _, info := range infos {
-			if info.name == "" {
-				results = append(results, Result{
-					Rule:     r.Name(),
-					Message:  fmt.Sprintf("multiple %q landmarks, this one needs aria-label to distinguish it", role),
-					Filename: doc.Filename,
-					Line:     info.node.Line,
-					Col:      info.node.Col,
-					Severity: Warning,
-				})
+		for _, role := range roles {
+			landmarks := byRole[role]
+			if len(landmarks) <= 1 {
 				continue
 			}
 
-			normalizedName := strings.ToLower(strings.TrimSpace(info.name))
-			if prev, exists := seenNames[normalizedName]; exists {
-				results = append(results, Result{
-					Rule:     r.Name(),
-					Message:  fmt.Sprintf("duplicate %q landmark name %q (first at line %d)", role, info.name, prev.Line),
-					Filename: doc.Filename,
-					Line:     info.node.Line,
-					Col:      info.node.Col,
-					Severity: Warning,
-				})
-			} else {
-				seenNames[normalizedName] = info.node
+			// Check for duplicates or missing names
+			seenNames := make(map[string]*parser.Node)
+			for _, l := range landmarks {
+				if l.Name == "" {
+					v.Report(Result{
+						Rule:     r.Name(),
+						Message:  fmt.Sprintf("multiple %q landmarks, this one needs aria-label to distinguish it", role),
+						Filename: v.Doc.Filename,
+						Line:     l.Node.Line,
+						Col:      l.Node.Col,
+						Severity: Warning,
+					})
+					continue
+				}
+
+				normalizedName := strings.ToLower(strings.TrimSpace(l.Name))
+				if prev, exists := seenNames[normalizedName]; exists {
+					v.Report(Result{
+						Rule:     r.Name(),
+						Message:  fmt.Sprintf("duplicate %q landmark name %q (first at line %d)", role, l.Name, prev.Line),
+						Filename: v.Doc.Filename,
+						Line:     l.Node.Line,
+						Col:      l.Node.Col,
+						Severity: Warning,
+					})
+				} else {
+					seenNames[normalizedName] = l.Node
+				}
 			}
 		}
-	}
-
-	return results
+	})
 }
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"html"
 	"regexp"
+	"strings"
 
 	"github.com/toba/go-html-validate/parser"
 )
@@ -21,6 +22,8 @@ func (r *UnrecognizedCharRef) Description() string {
 	return "character references must be valid HTML5 entities"
 }
 
+func (r *UnrecognizedCharRef) DefaultSeverity() Severity { return Warning }
+
 // Check implements Rule but returns nil - this rule uses CheckRaw instead.
 func (r *UnrecognizedCharRef) Check(_ *parser.Document) []Result {
 	return nil
@@ -28,7 +31,14 @@ func (r *UnrecognizedCharRef) Check(_ *parser.Document) []Result {
 
 // CheckRaw examines the raw content for unrecognized named character references.
 func (r *UnrecognizedCharRef) CheckRaw(filename string, content []byte) []Result {
+	return r.CheckRawDelims(filename, content, parser.DefaultDelims)
+}
+
+// CheckRawDelims examines raw content, skipping template actions delimited
+// by delims.
+func (r *UnrecognizedCharRef) CheckRawDelims(filename string, content []byte, delims parser.Delims) []Result {
 	var results []Result
+	d := delims.OrDefault()
 
 	lines := bytes.Split(content, []byte("\n"))
 	for lineNum, line := range lines {
@@ -43,7 +53,7 @@ func (r *UnrecognizedCharRef) CheckRaw(filename string, content []byte) []Result
 			name := lineStr[match[2]:match[3]]
 
 			// Skip if inside a Go template expression
-			if isInsideTemplateExpr(lineStr, match[0]) {
+			if isInsideTemplateExpr(lineStr, match[0], d) {
 				continue
 			}
 
@@ -67,16 +77,16 @@ func (r *UnrecognizedCharRef) CheckRaw(filename string, content []byte) []Result
 }
 
 // isInsideTemplateExpr checks if a position is within a {{ ... }} template expression.
-func isInsideTemplateExpr(line string, pos int) bool {
+func isInsideTemplateExpr(line string, pos int, d parser.Delims) bool {
 	// Find all {{ and }} positions and check if pos falls inside one
 	depth := 0
 	for i := 0; i < len(line)-1 && i < pos; i++ {
-		if line[i] == '{' && line[i+1] == '{' {
+		if strings.HasPrefix(line[i:], d.Left) {
 			depth++
-			i++ // skip second brace
-		} else if line[i] == '}' && line[i+1] == '}' {
+			i += len(d.Left) - 1 // skip rest of delimiter
+		} else if strings.HasPrefix(line[i:], d.Right) {
 			depth--
-			i++
+			i += len(d.Right) - 1
 		}
 	}
 	return depth > 0
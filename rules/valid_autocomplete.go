@@ -4,7 +4,6 @@ import (
 	"strings"
 
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // ValidAutocomplete checks that autocomplete attributes have valid values.
@@ -18,27 +17,18 @@ func (r *ValidAutocomplete) Description() string {
 	return "autocomplete attribute must have valid token values"
 }
 
-// Check examines the document for invalid autocomplete values.
-func (r *ValidAutocomplete) Check(doc *parser.Document) []Result {
-	var results []Result
-
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
+// DefaultSeverity returns the most severe level this rule reports at.
+func (r *ValidAutocomplete) DefaultSeverity() Severity { return Warning }
 
-		// Check elements that support autocomplete
-		tag := strings.ToLower(n.Data)
-		switch tag {
-		case "input", "select", "textarea", "form":
-			// These support autocomplete
-		default:
-			return true
-		}
+// Check examines the document for invalid autocomplete values.
+func (r *ValidAutocomplete) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+// Visit checks each <input>, <select>, <textarea>, <form> element.
+func (r *ValidAutocomplete) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		autocomplete := n.GetAttr("autocomplete")
 		if autocomplete == "" || autocomplete == TemplateExprPlaceholder {
-			return true
+			return
 		}
 
 		// Parse autocomplete tokens
@@ -65,19 +55,15 @@ func (r *ValidAutocomplete) Check(doc *parser.Document) []Result {
 
 			// Check if it's a valid autocomplete token
 			if !AutocompleteTokens[token] {
-				results = append(results, Result{
+				v.Report(Result{
 					Rule:     RuleValidAutocomplete,
 					Message:  "invalid autocomplete token: " + token,
-					Filename: doc.Filename,
+					Filename: v.Doc.Filename,
 					Line:     n.Line,
 					Col:      n.Col,
 					Severity: Warning,
 				})
 			}
 		}
-
-		return true
-	})
-
-	return results
+	}, "input", "select", "textarea", "form")
 }
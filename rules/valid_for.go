@@ -4,7 +4,6 @@ import (
 	"strings"
 
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // labelableElements are elements that can be associated with a <label>.
@@ -27,72 +26,54 @@ func (r *ValidFor) Description() string {
 	return "label for attribute must reference a labelable element"
 }
 
-func (r *ValidFor) Check(doc *parser.Document) []Result {
-	// Build a map of id -> node for lookup
-	idMap := make(map[string]*parser.Node)
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type == html.ElementNode && n.HasAttr("id") {
-			id := n.GetAttr("id")
-			if id != "" {
-				idMap[id] = n
-			}
-		}
-		return true
-	})
-
-	var results []Result
+func (r *ValidFor) DefaultSeverity() Severity { return Error }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode || !n.IsElement("label") {
-			return true
-		}
+func (r *ValidFor) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+func (r *ValidFor) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		forAttr := n.GetAttr("for")
 		if forAttr == "" {
-			return true
+			return
 		}
 
 		// Skip template expressions
-		if IsTemplateExpr(forAttr) {
-			return true
+		if IsTemplateExprDelims(forAttr, v.Doc.Delims()) {
+			return
 		}
 
-		target, exists := idMap[forAttr]
-		if !exists {
+		target := v.Index().ByID(forAttr)
+		if target == nil {
 			// Target not in this document; could be in another template fragment
-			return true
+			return
 		}
 
 		tag := strings.ToLower(target.Data)
 		if !labelableElements[tag] {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     r.Name(),
 				Message:  "label for attribute references non-labelable element <" + tag + ">",
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Error,
 			})
-			return true
+			return
 		}
 
 		// input type="hidden" is not labelable
 		if tag == "input" {
 			inputType := strings.ToLower(target.GetAttr("type"))
 			if inputType == "hidden" {
-				results = append(results, Result{
+				v.Report(Result{
 					Rule:     r.Name(),
 					Message:  "label for attribute references hidden input",
-					Filename: doc.Filename,
+					Filename: v.Doc.Filename,
 					Line:     n.Line,
 					Col:      n.Col,
 					Severity: Error,
 				})
 			}
 		}
-
-		return true
-	})
-
-	return results
+	}, "label")
 }
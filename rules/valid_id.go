@@ -5,7 +5,6 @@ import (
 	"unicode"
 
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // ValidID ensures ID attributes are well-formed.
@@ -17,60 +16,54 @@ func (r *ValidID) Description() string {
 	return "ID attributes must be non-empty and not contain whitespace"
 }
 
-func (r *ValidID) Check(doc *parser.Document) []Result {
-	var results []Result
+func (r *ValidID) DefaultSeverity() Severity { return Error }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
+func (r *ValidID) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+func (r *ValidID) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		if !n.HasAttr("id") {
-			return true
+			return
 		}
 
 		id := n.GetAttr("id")
 
 		// Check for empty ID
 		if id == "" {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     r.Name(),
 				Message:  "id attribute must not be empty; provide a unique identifier or remove the attribute",
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Error,
 			})
-			return true
+			return
 		}
 
 		// Check for whitespace
 		if strings.ContainsAny(id, " \t\n\r") {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     r.Name(),
 				Message:  "id \"" + id + "\" contains whitespace; use hyphens or underscores instead of spaces",
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Error,
 			})
-			return true
+			return
 		}
 
 		// Warn if starts with a digit (CSS selector issues)
 		if id != "" && unicode.IsDigit(rune(id[0])) {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     r.Name(),
 				Message:  "id \"" + id + "\" starts with digit; prefix with letter to avoid CSS selector issues",
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Warning,
 			})
 		}
-
-		return true
 	})
-
-	return results
 }
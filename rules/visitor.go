@@ -0,0 +1,162 @@
+package rules
+
+import (
+	"strings"
+
+	"github.com/toba/go-html-validate/parser"
+	"golang.org/x/net/html"
+)
+
+// VisitorRule is implemented by rules that check elements as the linter walks
+// the document, rather than walking it themselves. The linter walks each
+// document once and dispatches every element to all visitor rules, so the
+// cost of a walk is shared however many rules are enabled.
+type VisitorRule interface {
+	Rule
+	// Visit subscribes the rule to element events for one document. The
+	// rule is shared between goroutines, so anything it tracks while
+	// checking the document belongs in the handlers' closures.
+	Visit(v *Visitor)
+}
+
+// ElementFunc handles an element event.
+type ElementFunc func(n *parser.Node)
+
+// Visitor receives one rule's subscriptions for a document and collects the
+// rule's results.
+type Visitor struct {
+	// Doc is the document being walked
+	Doc *parser.Document
+
+	shared  *visitShared
+	enter   []elementHandler
+	leave   []elementHandler
+	done    []func()
+	results []Result
+}
+
+// visitShared is the state every visitor of a document shares.
+type visitShared struct {
+	doc   *parser.Document
+	index *Index
+}
+
+// elementHandler is a subscription to elements with the given tags, or to
+// every element when tags is empty.
+type elementHandler struct {
+	tags []string
+	fn   ElementFunc
+}
+
+// OnEnter calls fn for each element with one of tags, or every element when
+// no tags are given, before its children are visited. Tags are lowercase.
+func (v *Visitor) OnEnter(fn ElementFunc, tags ...string) {
+	v.enter = append(v.enter, elementHandler{tags: tags, fn: fn})
+}
+
+// OnLeave calls fn for each element with one of tags, or every element when
+// no tags are given, after its children are visited.
+func (v *Visitor) OnLeave(fn ElementFunc, tags ...string) {
+	v.leave = append(v.leave, elementHandler{tags: tags, fn: fn})
+}
+
+// OnDone calls fn once the whole document has been visited.
+func (v *Visitor) OnDone(fn func()) {
+	v.done = append(v.done, fn)
+}
+
+// Report adds results for the rule.
+func (v *Visitor) Report(results ...Result) {
+	v.results = append(v.results, results...)
+}
+
+// Index returns the document's shared index, building it on first use.
+func (v *Visitor) Index() *Index {
+	if v.shared.index == nil {
+		v.shared.index = NewIndex(v.shared.doc)
+	}
+	return v.shared.index
+}
+
+// Dispatch walks doc once, sending each element to the handlers rules
+// subscribe with, and returns each rule's results in the order of rules.
+func Dispatch(doc *parser.Document, rules []VisitorRule) [][]Result {
+	shared := &visitShared{doc: doc}
+	visitors := make([]*Visitor, len(rules))
+	d := &dispatcher{
+		enter: make(map[string][]ElementFunc),
+		leave: make(map[string][]ElementFunc),
+	}
+	for i, r := range rules {
+		v := &Visitor{Doc: doc, shared: shared}
+		r.Visit(v)
+		visitors[i] = v
+		d.enterAll = d.add(d.enter, d.enterAll, v.enter)
+		d.leaveAll = d.add(d.leave, d.leaveAll, v.leave)
+	}
+
+	if doc.Root != nil {
+		d.walk(doc.Root)
+	}
+
+	results := make([][]Result, len(visitors))
+	for i, v := range visitors {
+		for _, fn := range v.done {
+			fn()
+		}
+		results[i] = v.results
+	}
+	return results
+}
+
+// visit runs a single visitor rule over doc, for its Check method.
+func visit(r VisitorRule, doc *parser.Document) []Result {
+	return Dispatch(doc, []VisitorRule{r})[0]
+}
+
+// dispatcher holds the handlers for a walk, indexed by tag name.
+type dispatcher struct {
+	enter, leave       map[string][]ElementFunc
+	enterAll, leaveAll []ElementFunc
+}
+
+// add files handlers under their tags, returning all with the handlers that
+// take every element appended.
+func (d *dispatcher) add(byTag map[string][]ElementFunc, all []ElementFunc, handlers []elementHandler) []ElementFunc {
+	for _, h := range handlers {
+		if len(h.tags) == 0 {
+			all = append(all, h.fn)
+			continue
+		}
+		for _, tag := range h.tags {
+			byTag[tag] = append(byTag[tag], h.fn)
+		}
+	}
+	return all
+}
+
+func (d *dispatcher) walk(n *parser.Node) {
+	if n.Type != html.ElementNode {
+		for _, child := range n.Children {
+			d.walk(child)
+		}
+		return
+	}
+
+	tag := strings.ToLower(n.Data)
+	for _, fn := range d.enterAll {
+		fn(n)
+	}
+	for _, fn := range d.enter[tag] {
+		fn(n)
+	}
+	for _, child := range n.Children {
+		d.walk(child)
+	}
+	for _, fn := range d.leave[tag] {
+		fn(n)
+	}
+	for _, fn := range d.leaveAll {
+		fn(n)
+	}
+}
@@ -18,29 +18,29 @@ func (r *VoidContent) Description() string {
 	return "void elements must not have content"
 }
 
-// Check examines the document for void elements with children.
-func (r *VoidContent) Check(doc *parser.Document) []Result {
-	var results []Result
+// DefaultSeverity returns the most severe level this rule reports at.
+func (r *VoidContent) DefaultSeverity() Severity { return Error }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode {
-			return true
-		}
+// Check examines the document for void elements with children.
+func (r *VoidContent) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+// Visit checks each element.
+func (r *VoidContent) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		tag := strings.ToLower(n.Data)
 
 		// Check if element is void
 		if !VoidElements[tag] {
-			return true
+			return
 		}
 
 		// Check for child elements (text nodes are also invalid)
 		for _, child := range n.Children {
 			if child.Type == html.ElementNode {
-				results = append(results, Result{
+				v.Report(Result{
 					Rule:     RuleVoidContent,
 					Message:  "void element <" + tag + "> must not have child elements",
-					Filename: doc.Filename,
+					Filename: v.Doc.Filename,
 					Line:     n.Line,
 					Col:      n.Col,
 					Severity: Error,
@@ -49,10 +49,10 @@ func (r *VoidContent) Check(doc *parser.Document) []Result {
 			}
 			// Check for non-whitespace text content
 			if child.Type == html.TextNode && strings.TrimSpace(child.Data) != "" {
-				results = append(results, Result{
+				v.Report(Result{
 					Rule:     RuleVoidContent,
 					Message:  "void element <" + tag + "> must not have text content",
-					Filename: doc.Filename,
+					Filename: v.Doc.Filename,
 					Line:     n.Line,
 					Col:      n.Col,
 					Severity: Error,
@@ -60,9 +60,5 @@ func (r *VoidContent) Check(doc *parser.Document) []Result {
 				break
 			}
 		}
-
-		return true
 	})
-
-	return results
 }
@@ -4,7 +4,6 @@ import (
 	"strings"
 
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // WcagH36 checks that input type="image" has alt text.
@@ -19,49 +18,45 @@ func (r *WcagH36) Description() string {
 	return "input type=\"image\" must have alt attribute describing the action"
 }
 
-// Check examines the document for image inputs missing alt text.
-func (r *WcagH36) Check(doc *parser.Document) []Result {
-	var results []Result
+// DefaultSeverity returns the most severe level this rule reports at.
+func (r *WcagH36) DefaultSeverity() Severity { return Error }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode || !n.IsElement("input") {
-			return true
-		}
+// Check examines the document for image inputs missing alt text.
+func (r *WcagH36) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+// Visit checks each <input> element.
+func (r *WcagH36) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		// Check if this is an image input
 		inputType := strings.ToLower(n.GetAttr("type"))
 		if inputType != "image" {
-			return true
+			return
 		}
 
 		// Check for alt attribute
 		if !n.HasAttr("alt") {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     RuleWcagH36,
 				Message:  "input type=\"image\" must have alt attribute",
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Error,
 			})
-			return true
+			return
 		}
 
 		// Check for empty alt
 		alt := n.GetAttr("alt")
 		if alt == "" {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     RuleWcagH36,
 				Message:  "input type=\"image\" has empty alt attribute",
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Error,
 			})
 		}
-
-		return true
-	})
-
-	return results
+	}, "input")
 }
@@ -2,7 +2,6 @@ package rules
 
 import (
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // WcagH63 checks that th elements have scope attribute.
@@ -17,43 +16,39 @@ func (r *WcagH63) Description() string {
 	return "th elements should have scope attribute for accessibility"
 }
 
-// Check examines the document for th elements without scope.
-func (r *WcagH63) Check(doc *parser.Document) []Result {
-	var results []Result
+// DefaultSeverity returns the most severe level this rule reports at.
+func (r *WcagH63) DefaultSeverity() Severity { return Error }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode || !n.IsElement("th") {
-			return true
-		}
+// Check examines the document for th elements without scope.
+func (r *WcagH63) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+// Visit checks each <th> element.
+func (r *WcagH63) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		// Check for scope attribute
 		if !n.HasAttr("scope") {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     RuleWcagH63,
 				Message:  "th element should have scope attribute (col, row, colgroup, or rowgroup)",
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Warning,
 			})
-			return true
+			return
 		}
 
 		// Validate scope value
 		scope := n.GetAttr("scope")
 		if !ValidScopeValues[scope] && scope != TemplateExprPlaceholder {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     RuleWcagH63,
 				Message:  "th scope attribute has invalid value: " + scope,
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Error,
 			})
 		}
-
-		return true
-	})
-
-	return results
+	}, "th")
 }
@@ -4,7 +4,6 @@ import (
 	"strings"
 
 	"github.com/toba/go-html-validate/parser"
-	"golang.org/x/net/html"
 )
 
 // WcagH67 checks that decorative images have empty alt and no title.
@@ -19,30 +18,30 @@ func (r *WcagH67) Description() string {
 	return "decorative images (alt=\"\") should not have title attribute"
 }
 
-// Check examines the document for images with empty alt that also have title.
-func (r *WcagH67) Check(doc *parser.Document) []Result {
-	var results []Result
+// DefaultSeverity returns the most severe level this rule reports at.
+func (r *WcagH67) DefaultSeverity() Severity { return Warning }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode || !n.IsElement("img") {
-			return true
-		}
+// Check examines the document for images with empty alt that also have title.
+func (r *WcagH67) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+// Visit checks each <img> element.
+func (r *WcagH67) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		// Check for decorative image pattern (empty alt)
 		alt := n.GetAttr("alt")
 		if alt != "" {
 			// Not a decorative image
-			return true
+			return
 		}
 
 		// Decorative images should not have title
 		if n.HasAttr("title") {
 			title := n.GetAttr("title")
 			if title != "" && title != TemplateExprPlaceholder {
-				results = append(results, Result{
+				v.Report(Result{
 					Rule:     RuleWcagH67,
 					Message:  "decorative image (alt=\"\") should not have title attribute",
-					Filename: doc.Filename,
+					Filename: v.Doc.Filename,
 					Line:     n.Line,
 					Col:      n.Col,
 					Severity: Warning,
@@ -53,18 +52,14 @@ func (r *WcagH67) Check(doc *parser.Document) []Result {
 		// Decorative images should not have role="img"
 		role := strings.ToLower(n.GetAttr("role"))
 		if role == "img" {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     RuleWcagH67,
 				Message:  "decorative image (alt=\"\") should have role=\"presentation\" or role=\"none\"",
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Warning,
 			})
 		}
-
-		return true
-	})
-
-	return results
+	}, "img")
 }
@@ -17,15 +17,15 @@ func (r *WcagH71) Description() string {
 	return "fieldset elements must contain a legend element"
 }
 
-// Check examines the document for fieldsets without legend.
-func (r *WcagH71) Check(doc *parser.Document) []Result {
-	var results []Result
+// DefaultSeverity returns the most severe level this rule reports at.
+func (r *WcagH71) DefaultSeverity() Severity { return Error }
 
-	doc.Walk(func(n *parser.Node) bool {
-		if n.Type != html.ElementNode || !n.IsElement("fieldset") {
-			return true
-		}
+// Check examines the document for fieldsets without legend.
+func (r *WcagH71) Check(doc *parser.Document) []Result { return visit(r, doc) }
 
+// Visit checks each <fieldset> element.
+func (r *WcagH71) Visit(v *Visitor) {
+	v.OnEnter(func(n *parser.Node) {
 		// Check if fieldset has a legend child
 		hasLegend := false
 		for _, child := range n.Children {
@@ -36,18 +36,14 @@ func (r *WcagH71) Check(doc *parser.Document) []Result {
 		}
 
 		if !hasLegend {
-			results = append(results, Result{
+			v.Report(Result{
 				Rule:     RuleWcagH71,
 				Message:  "fieldset element must contain a legend element",
-				Filename: doc.Filename,
+				Filename: v.Doc.Filename,
 				Line:     n.Line,
 				Col:      n.Col,
 				Severity: Error,
 			})
 		}
-
-		return true
-	})
-
-	return results
+	}, "fieldset")
 }